@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	LogsSourceStore  = "store"
+	LogsSourceAPI    = "api"
+	defaultLogsLimit = 20
+	maxLogsLimit     = 500
+)
+
+var errNoPendingModifications = errors.New("pending modifications are only available when the telegram bot is enabled")
+
+// logEntry is a log with the name of the reservation whose keypad code was used
+type logEntry struct {
+	model.NukiSmartlockLogResponse
+	ReservationName string `json:"reservationName,omitempty"`
+}
+
+type codeResponse struct {
+	Reference    string    `json:"reference"`
+	Code         int       `json:"code"`
+	Enabled      bool      `json:"enabled"`
+	AllowedFrom  time.Time `json:"allowedFrom"`
+	AllowedUntil time.Time `json:"allowedUntil"`
+}
+
+type modificationRequest struct {
+	ReservationRef string `json:"reservation_ref"`
+	// CheckIn and CheckOut are HH:MM times, default ones being used when empty
+	CheckIn   string `json:"check_in"`
+	CheckOut  string `json:"check_out"`
+	GuestName string `json:"guest_name"`
+}
+
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := defaultLogsLimit
+	if v := q.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %s", v))
+			return
+		}
+		limit = min(limit, maxLogsLimit)
+	}
+	var from, to time.Time
+	for name, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		var err error
+		if *t, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s date %s, RFC3339 expected", name, v))
+			return
+		}
+	}
+
+	source := q.Get("source")
+	if source == "" {
+		source = LogsSourceStore
+		if len(s.getStoredLogs()) == 0 {
+			source = LogsSourceAPI
+		}
+	}
+
+	var logs []model.NukiSmartlockLogResponse
+	switch source {
+	case LogsSourceStore:
+		for _, l := range s.getStoredLogs() {
+			if (!from.IsZero() && l.Date.Before(from)) || (!to.IsZero() && l.Date.After(to)) {
+				continue
+			}
+			logs = append(logs, l)
+		}
+	case LogsSourceAPI:
+		lr := s.logsReader
+		lr.FromDate = from
+		lr.ToDate = to
+		var err error
+		if logs, err = lr.ExecuteAll((limit + 49) / 50); err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid source %s, expected %s or %s", source, LogsSourceStore, LogsSourceAPI))
+		return
+	}
+	logs = logs[:min(limit, len(logs))]
+
+	names := make(map[string]string)
+	if resas, err := s.reservationsReader.Execute(); err != nil {
+		log.Error().Err(err).Msg("Unable to get reservations to resolve names, keeping refs")
+	} else {
+		for _, resa := range resas {
+			names[resa.Reference] = resa.Name
+		}
+	}
+	res := make([]logEntry, 0, len(logs))
+	for _, l := range logs {
+		e := logEntry{NukiSmartlockLogResponse: l}
+		if l.Trigger == model.NukiTriggerKeypad && l.Source == model.NukiSourceKeypadCode && l.State != model.NukiStateWrongKeypadCode {
+			e.ReservationName = names[l.Name]
+		}
+		res = append(res, e)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleSmartlock(w http.ResponseWriter, _ *http.Request) {
+	res, err := s.smartlockReader.Execute()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleReservations(w http.ResponseWriter, _ *http.Request) {
+	res, err := s.reservationsReader.Execute()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if res == nil {
+		res = []model.NukiReservationResponse{}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleReservationCode(w http.ResponseWriter, r *http.Request) {
+	ref := r.PathValue("ref")
+	auths, err := s.smartlockAuthReader.Execute()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	// Keypad codes of reservations are named after the reservation's reference
+	for _, auth := range auths {
+		if auth.Name == ref {
+			writeJSON(w, http.StatusOK, codeResponse{
+				Reference:    ref,
+				Code:         auth.Code,
+				Enabled:      auth.Enabled,
+				AllowedFrom:  auth.AllowedFromDate,
+				AllowedUntil: auth.AllowedUntilDate,
+			})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("no code found for %s", ref))
+}
+
+func (s *Server) handleListModifications(w http.ResponseWriter, _ *http.Request) {
+	if s.pendingModifications == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoPendingModifications)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.pendingModifications.GetAllPendingModifications())
+}
+
+func (s *Server) handleCreateModification(w http.ResponseWriter, r *http.Request) {
+	if s.pendingModifications == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoPendingModifications)
+		return
+	}
+
+	var req modificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if req.ReservationRef == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("reservation_ref is mandatory"))
+		return
+	}
+
+	checkIn, checkOut := s.getDefaultCheckTimes()
+	rpm := model.ReservationPendingModification{
+		ReservationRef: req.ReservationRef,
+		CheckInTime:    checkIn,
+		CheckOutTime:   checkOut,
+		GuestName:      req.GuestName,
+	}
+	for _, v := range []struct {
+		str string
+		t   *time.Time
+	}{{req.CheckIn, &rpm.CheckInTime}, {req.CheckOut, &rpm.CheckOutTime}} {
+		if v.str == "" {
+			continue
+		}
+		var err error
+		if *v.t, err = time.Parse(model.FormatTimeHoursMinutes, v.str); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid time %s, HH:MM expected", v.str))
+			return
+		}
+	}
+
+	s.pendingModifications.AddPendingModification(rpm)
+	writeJSON(w, http.StatusCreated, rpm)
+}
+
+func (s *Server) handleDeleteModification(w http.ResponseWriter, r *http.Request) {
+	if s.pendingModifications == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoPendingModifications)
+		return
+	}
+	s.pendingModifications.DeletePendingModification(r.PathValue("ref"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleApplyModifications(w http.ResponseWriter, _ *http.Request) {
+	if s.pendingModifications == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoPendingModifications)
+		return
+	}
+	s.pendingModifications.ApplyModificationNow()
+	w.WriteHeader(http.StatusAccepted)
+}
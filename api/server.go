@@ -0,0 +1,163 @@
+package api
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	tgbroutine "github.com/nmaupu/nuki-logger/telegrambot/routine"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultPrefix = "/api/v1"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Config configures the REST API served by the http server
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Token must be sent as a bearer token with every request
+	Token string `mapstructure:"token"`
+	// Prefix of all the API's paths
+	Prefix string `mapstructure:"prefix"`
+}
+
+func (c Config) GetPrefix() string {
+	if c.Prefix == "" {
+		return DefaultPrefix
+	}
+	return strings.TrimSuffix(c.Prefix, "/")
+}
+
+// Server exposes nuki-logger's data and actions as a JSON REST API
+type Server struct {
+	config              Config
+	logsReader          nukiapi.LogsReader
+	smartlockReader     nukiapi.SmartlockReader
+	reservationsReader  nukiapi.ReservationsReader
+	smartlockAuthReader nukiapi.SmartlockAuthReader
+	mutexDefaults       sync.RWMutex
+	defaultCheckIn      time.Time
+	defaultCheckOut     time.Time
+	// pendingModifications is nil when the telegram bot is disabled
+	pendingModifications tgbroutine.ReservationPendingModificationRoutine
+
+	mutexLogs  sync.RWMutex
+	storedLogs []model.NukiSmartlockLogResponse
+}
+
+func NewServer(config Config,
+	logsReader nukiapi.LogsReader,
+	smartlockReader nukiapi.SmartlockReader,
+	reservationsReader nukiapi.ReservationsReader,
+	smartlockAuthReader nukiapi.SmartlockAuthReader,
+	defaultCheckIn time.Time,
+	defaultCheckOut time.Time) (*Server, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("api token is mandatory")
+	}
+	return &Server{
+		config:              config,
+		logsReader:          logsReader,
+		smartlockReader:     smartlockReader,
+		reservationsReader:  reservationsReader,
+		smartlockAuthReader: smartlockAuthReader,
+		defaultCheckIn:      defaultCheckIn,
+		defaultCheckOut:     defaultCheckOut,
+	}, nil
+}
+
+// SetPendingModificationRoutine enables the pending modifications endpoints
+func (s *Server) SetPendingModificationRoutine(r tgbroutine.ReservationPendingModificationRoutine) {
+	s.pendingModifications = r
+}
+
+// SetDefaultCheckTimes changes the access times of the modifications created without them
+func (s *Server) SetDefaultCheckTimes(checkIn, checkOut time.Time) {
+	s.mutexDefaults.Lock()
+	defer s.mutexDefaults.Unlock()
+	s.defaultCheckIn = checkIn
+	s.defaultCheckOut = checkOut
+}
+
+func (s *Server) getDefaultCheckTimes() (time.Time, time.Time) {
+	s.mutexDefaults.RLock()
+	defer s.mutexDefaults.RUnlock()
+	return s.defaultCheckIn, s.defaultCheckOut
+}
+
+// SetStoredLogs updates the logs served from the store, newest first
+func (s *Server) SetStoredLogs(logs []model.NukiSmartlockLogResponse) {
+	s.mutexLogs.Lock()
+	defer s.mutexLogs.Unlock()
+	s.storedLogs = logs
+}
+
+func (s *Server) getStoredLogs() []model.NukiSmartlockLogResponse {
+	s.mutexLogs.RLock()
+	defer s.mutexLogs.RUnlock()
+	return s.storedLogs
+}
+
+// Register registers all the API's handlers on mux
+func (s *Server) Register(mux *http.ServeMux) {
+	prefix := s.config.GetPrefix()
+	mux.HandleFunc("GET "+prefix+"/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(openAPISpec)
+	})
+
+	handle := func(pattern string, handler http.HandlerFunc) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.Handle(method+" "+prefix+path, s.authenticated(handler))
+	}
+	handle("GET /logs", s.handleLogs)
+	handle("GET /smartlock", s.handleSmartlock)
+	handle("GET /reservations", s.handleReservations)
+	handle("GET /reservations/{ref}/code", s.handleReservationCode)
+	handle("GET /modifications", s.handleListModifications)
+	handle("POST /modifications", s.handleCreateModification)
+	handle("DELETE /modifications/{ref}", s.handleDeleteModification)
+	handle("POST /modifications/apply", s.handleApplyModifications)
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+			log.Warn().
+				Str("remote_addr", r.RemoteAddr).
+				Str("path", r.URL.Path).
+				Msg("Unauthorized api request")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing token"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Unable to write api response")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,195 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/calendar"
+	"github.com/nmaupu/nuki-logger/model"
+)
+
+const (
+	SourceAirbnb  = "airbnb"
+	SourceBooking = "booking"
+	SourceICal    = "ical"
+
+	DefaultInterval = time.Hour
+	dateFormat      = "2006-01-02"
+)
+
+var (
+	// defaultReferencePatterns extract the reservation reference from an event's description
+	defaultReferencePatterns = map[string]string{
+		SourceAirbnb: `reservations/details/([A-Z0-9]+)`,
+	}
+	// placeholderSummaries are summaries used by OTAs instead of a guest name
+	placeholderSummaries = []string{"reserved", "not available", "closed", "blocked", "unavailable"}
+	httpClient           = http.Client{Timeout: time.Second * 30}
+)
+
+// Config configures the import of bookings from the iCal feeds of the OTAs of the address
+type Config struct {
+	// Interval between two imports
+	Interval time.Duration `mapstructure:"interval"`
+	Feeds    []FeedConfig  `mapstructure:"feeds"`
+}
+
+func (c Config) IsEnabled() bool {
+	return len(c.Feeds) > 0
+}
+
+func (c Config) GetInterval() time.Duration {
+	if c.Interval <= 0 {
+		return DefaultInterval
+	}
+	return c.Interval
+}
+
+// FeedConfig is an iCal feed exported by an OTA (Airbnb, Booking.com, etc.)
+type FeedConfig struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+	// Source is airbnb, booking or ical
+	Source string `mapstructure:"source"`
+	// ReferencePattern is a regexp whose first group extracts the reservation reference from the event's description or summary
+	ReferencePattern string `mapstructure:"reference_pattern"`
+	// CheckIn and CheckOut (HH:MM) override the access times of the bookings of this feed
+	CheckIn  string `mapstructure:"check_in"`
+	CheckOut string `mapstructure:"check_out"`
+}
+
+// Validate checks the feed's configuration
+func (f FeedConfig) Validate() error {
+	if f.URL == "" {
+		return fmt.Errorf("url is mandatory")
+	}
+	switch f.Source {
+	case SourceAirbnb, SourceBooking, SourceICal:
+	default:
+		return fmt.Errorf("invalid source %q, expected %s, %s or %s", f.Source, SourceAirbnb, SourceBooking, SourceICal)
+	}
+	if _, err := f.referenceRegexp(); err != nil {
+		return fmt.Errorf("invalid reference pattern: %w", err)
+	}
+	if _, _, _, err := f.AccessTimes(time.Time{}, time.Time{}); err != nil {
+		return fmt.Errorf("invalid check in or check out time, HH:MM expected")
+	}
+	return nil
+}
+
+func (f FeedConfig) referenceRegexp() (*regexp.Regexp, error) {
+	pattern := f.ReferencePattern
+	if pattern == "" {
+		pattern = defaultReferencePatterns[f.Source]
+	}
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// AccessTimes returns the check-in and check-out overrides of the feed, ok is false when none is configured
+func (f FeedConfig) AccessTimes(defaultCheckIn, defaultCheckOut time.Time) (checkIn time.Time, checkOut time.Time, ok bool, err error) {
+	checkIn, checkOut = defaultCheckIn, defaultCheckOut
+	if f.CheckIn != "" {
+		if checkIn, err = time.Parse(model.FormatTimeHoursMinutes, f.CheckIn); err != nil {
+			return
+		}
+		ok = true
+	}
+	if f.CheckOut != "" {
+		if checkOut, err = time.Parse(model.FormatTimeHoursMinutes, f.CheckOut); err != nil {
+			return
+		}
+		ok = true
+	}
+	return
+}
+
+// Booking is a reservation read from an OTA's iCal feed
+type Booking struct {
+	Feed      FeedConfig
+	UID       string
+	Reference string
+	GuestName string
+	// Start and End are the check-in and check-out dates
+	Start time.Time
+	End   time.Time
+}
+
+func (b Booking) FormatDates() string {
+	return fmt.Sprintf("%s -> %s", b.Start.Format(dateFormat), b.End.Format(dateFormat))
+}
+
+// Fetch downloads a feed and returns its bookings, blocked dates being ignored
+func Fetch(feed FeedConfig, loc *time.Location) ([]Booking, error) {
+	resp, err := httpClient.Get(feed.URL)
+	if err != nil {
+		// The url carries the OTA's token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("unable to get %s feed: %w", feed.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("error while getting %s feed (status: %s)", feed.Name, resp.Status)
+	}
+
+	events, err := calendar.ParseICal(resp.Body, loc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s feed: %w", feed.Name, err)
+	}
+	return FromEvents(feed, events)
+}
+
+// FromEvents converts the events of a feed to bookings
+func FromEvents(feed FeedConfig, events []calendar.Event) ([]Booking, error) {
+	re, err := feed.referenceRegexp()
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference pattern for %s feed: %w", feed.Name, err)
+	}
+
+	var res []Booking
+	for _, e := range events {
+		b := Booking{
+			Feed:  feed,
+			UID:   e.UID,
+			Start: e.Start,
+			End:   e.End,
+		}
+		if re != nil {
+			if m := re.FindStringSubmatch(e.Description + "\n" + e.Summary); len(m) > 1 {
+				b.Reference = m[1]
+			}
+		}
+		if !isPlaceholder(e.Summary) {
+			b.GuestName = strings.TrimSpace(e.Summary)
+		}
+		// Airbnb exports blocked dates as "Airbnb (Not available)" events without a reservation
+		if feed.Source == SourceAirbnb && b.Reference == "" {
+			continue
+		}
+		res = append(res, b)
+	}
+	return res, nil
+}
+
+func isPlaceholder(summary string) bool {
+	s := strings.ToLower(summary)
+	if s == "" {
+		return true
+	}
+	for _, p := range placeholderSummaries {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package booking
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/model"
+)
+
+type ConflictKind string
+
+const (
+	// ConflictDates means the booking and the Nuki reservation with the same reference have different dates
+	ConflictDates ConflictKind = "dates"
+	// ConflictAmbiguous means several Nuki reservations could match a booking without reference
+	ConflictAmbiguous ConflictKind = "ambiguous"
+	// ConflictOverlap means a booking overlaps a different Nuki reservation
+	ConflictOverlap ConflictKind = "overlap"
+	// ConflictDoubleBooking means bookings from two feeds overlap
+	ConflictDoubleBooking ConflictKind = "double_booking"
+)
+
+// Match is a booking associated to a Nuki reservation reference
+type Match struct {
+	Booking   Booking
+	Reference string
+	// Reservation is nil when the reservation is not synced to Nuki yet
+	Reservation *model.NukiReservationResponse
+}
+
+// Conflict is an inconsistency between the feeds and Nuki data
+type Conflict struct {
+	Kind    ConflictKind
+	Booking Booking
+	// Other describes what the booking conflicts with
+	Other string
+}
+
+// Key identifies a conflict so that it is only reported once
+func (c Conflict) Key() string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", c.Kind, c.Booking.Feed.Name, c.Booking.UID, c.Booking.FormatDates(), c.Other)
+}
+
+// MatchReservations associates bookings to Nuki reservations, by reference when the feed provides one and by dates otherwise.
+// Bookings already over are ignored.
+func MatchReservations(bookings []Booking, resas []model.NukiReservationResponse, loc *time.Location, now time.Time) ([]Match, []Conflict) {
+	var matches []Match
+	var conflicts []Conflict
+
+	day := func(t time.Time) string {
+		return t.In(loc).Format(dateFormat)
+	}
+	sameDates := func(b Booking, r model.NukiReservationResponse) bool {
+		return day(b.Start) == day(r.StartDate) && day(b.End) == day(r.EndDate)
+	}
+	overlaps := func(start1, end1, start2, end2 time.Time) bool {
+		return day(start1) < day(end2) && day(start2) < day(end1)
+	}
+
+	for _, b := range bookings {
+		if b.End.Before(now) {
+			continue
+		}
+
+		m := Match{Booking: b, Reference: b.Reference}
+		if b.Reference != "" {
+			for i, r := range resas {
+				if r.Reference == b.Reference {
+					m.Reservation = &resas[i]
+					break
+				}
+			}
+			if m.Reservation != nil && !sameDates(b, *m.Reservation) {
+				conflicts = append(conflicts, Conflict{
+					Kind:    ConflictDates,
+					Booking: b,
+					Other:   fmt.Sprintf("%s (%s -> %s)", m.Reservation.Reference, day(m.Reservation.StartDate), day(m.Reservation.EndDate)),
+				})
+			}
+		} else {
+			var candidates []int
+			for i, r := range resas {
+				if sameDates(b, r) {
+					candidates = append(candidates, i)
+				}
+			}
+			switch len(candidates) {
+			case 0:
+			case 1:
+				m.Reservation = &resas[candidates[0]]
+				m.Reference = m.Reservation.Reference
+			default:
+				conflicts = append(conflicts, Conflict{Kind: ConflictAmbiguous, Booking: b, Other: fmt.Sprintf("%d reservations", len(candidates))})
+			}
+		}
+
+		for i, r := range resas {
+			if m.Reservation == &resas[i] || (m.Reference != "" && r.Reference == m.Reference) {
+				continue
+			}
+			if overlaps(b.Start, b.End, r.StartDate, r.EndDate) && !(b.Reference == "" && sameDates(b, r)) {
+				conflicts = append(conflicts, Conflict{
+					Kind:    ConflictOverlap,
+					Booking: b,
+					Other:   fmt.Sprintf("%s %s (%s -> %s)", r.Name, r.Reference, day(r.StartDate), day(r.EndDate)),
+				})
+			}
+		}
+
+		matches = append(matches, m)
+	}
+
+	for i := range matches {
+		for j := i + 1; j < len(matches); j++ {
+			a, b := matches[i], matches[j]
+			if a.Booking.Feed.Name == b.Booking.Feed.Name ||
+				(a.Reference != "" && a.Reference == b.Reference) ||
+				!overlaps(a.Booking.Start, a.Booking.End, b.Booking.Start, b.Booking.End) {
+				continue
+			}
+			conflicts = append(conflicts, Conflict{
+				Kind:    ConflictDoubleBooking,
+				Booking: a.Booking,
+				Other:   fmt.Sprintf("%s (%s)", b.Booking.Feed.Name, b.Booking.FormatDates()),
+			})
+		}
+	}
+
+	return matches, conflicts
+}
@@ -0,0 +1,313 @@
+package calendar
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultPath            = "/calendar.ics"
+	DefaultPastDays        = 30
+	DefaultRefreshInterval = time.Minute * 5
+
+	// logsMaxRequests limits the number of calls to the logs API when building the feed
+	logsMaxRequests = 20
+	// lastExitGracePeriod is the time after the end of an access window during which the door being locked is still considered as the guest leaving
+	lastExitGracePeriod = time.Hour * 2
+)
+
+// Config configures the iCalendar feed published on the http server
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path of the feed on the http server
+	Path string `mapstructure:"path"`
+	// Token must be given as a token query parameter or as a bearer token to get the feed
+	Token    string `mapstructure:"token"`
+	Timezone string `mapstructure:"timezone"`
+	Language string `mapstructure:"language"`
+	// PastDays is the number of days finished reservations are kept in the feed
+	PastDays int `mapstructure:"past_days"`
+	// RefreshInterval is the time the feed is cached for, calendar apps polling it regularly
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+func (c Config) GetPath() string {
+	if c.Path == "" {
+		return DefaultPath
+	}
+	return c.Path
+}
+
+// Feed publishes reservations, their access windows and the actual entry and exit times as an iCalendar feed
+type Feed struct {
+	config             Config
+	loc                *time.Location
+	reservationsReader nukiapi.ReservationsReader
+	logsReader         nukiapi.LogsReader
+	// pendingModifications returns the access times modifications not applied yet, can be nil
+	pendingModifications func() []model.ReservationPendingModification
+
+	mutex     sync.Mutex
+	content   []byte
+	updatedAt time.Time
+}
+
+func NewFeed(config Config,
+	reservationsReader nukiapi.ReservationsReader,
+	logsReader nukiapi.LogsReader,
+	pendingModifications func() []model.ReservationPendingModification) (*Feed, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("calendar token is mandatory")
+	}
+	loc, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load calendar timezone %s: %w", config.Timezone, err)
+	}
+	if config.PastDays <= 0 {
+		config.PastDays = DefaultPastDays
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultRefreshInterval
+	}
+	config.Language = i18n.Normalize(config.Language)
+
+	return &Feed{
+		config:               config,
+		loc:                  loc,
+		reservationsReader:   reservationsReader,
+		logsReader:           logsReader,
+		pendingModifications: pendingModifications,
+	}, nil
+}
+
+func (f *Feed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.isAuthorized(r) {
+		log.Warn().
+			Str("remote_addr", r.RemoteAddr).
+			Msg("Unauthorized calendar request")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	content, err := f.get()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to build calendar feed")
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="nuki.ics"`)
+	_, _ = w.Write(content)
+}
+
+func (f *Feed) isAuthorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(f.config.Token)) == 1
+}
+
+// get returns the feed, built again when older than the refresh interval
+func (f *Feed) get() ([]byte, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := time.Now()
+	if f.content != nil && now.Sub(f.updatedAt) < f.config.RefreshInterval {
+		return f.content, nil
+	}
+
+	events, err := f.Events(now)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, i18n.T(f.config.Language, "calendar.name"), events, now); err != nil {
+		return nil, err
+	}
+	f.content = buf.Bytes()
+	f.updatedAt = now
+	return f.content, nil
+}
+
+// accessWindow is the time during which a reservation's keypad code is valid
+type accessWindow struct {
+	resa    model.NukiReservationResponse
+	start   time.Time
+	end     time.Time
+	pending *model.ReservationPendingModification
+}
+
+// Events returns the events of the feed: reservations, effective access windows and actual first entry and last exit
+func (f *Feed) Events(now time.Time) ([]Event, error) {
+	resas, err := f.reservationsReader.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	pendings := make(map[string]model.ReservationPendingModification)
+	if f.pendingModifications != nil {
+		for _, p := range f.pendingModifications() {
+			// Once done, the modification is reflected by the reservation itself
+			if !p.ModificationDone && !p.NameOnly {
+				pendings[p.ReservationRef] = p
+			}
+		}
+	}
+
+	oldest := now.AddDate(0, 0, -f.config.PastDays)
+	var windows []accessWindow
+	for _, r := range resas {
+		if r.EndDate.Before(oldest) {
+			continue
+		}
+		w := accessWindow{resa: r, start: r.StartDate, end: r.EndDate}
+		if p, ok := pendings[r.Reference]; ok {
+			w.pending = &p
+			w.start = f.atTime(r.StartDate, p.CheckInTime)
+			w.end = f.atTime(r.EndDate, p.CheckOutTime)
+		}
+		windows = append(windows, w)
+	}
+	slices.SortFunc(windows, func(a, b accessWindow) int {
+		return a.start.Compare(b.start)
+	})
+
+	logs, err := f.getLogs(windows, now)
+	if err != nil {
+		// Reservations are still worth publishing without entries and exits
+		log.Error().Err(err).Msg("Unable to get logs for calendar feed")
+	}
+
+	lang := f.config.Language
+	var events []Event
+	for _, w := range windows {
+		r := w.resa
+		description := i18n.T(lang, "calendar.description", r.Reference, r.Guests)
+		if w.pending != nil {
+			description += "\n" + i18n.T(lang, "calendar.pending", w.pending.FormatCheckIn(), w.pending.FormatCheckOut())
+		}
+
+		startDay := r.StartDate.In(f.loc)
+		endDay := r.EndDate.In(f.loc)
+		if !endDay.After(startDay) {
+			endDay = startDay.AddDate(0, 0, 1)
+		}
+		events = append(events,
+			Event{
+				UID:         uid("resa", r.ID),
+				Summary:     r.Name,
+				Description: description,
+				Start:       startDay,
+				End:         endDay,
+				AllDay:      true,
+				Categories:  []string{i18n.T(lang, "calendar.category_stay")},
+			},
+			Event{
+				UID:         uid("access", r.ID),
+				Summary:     i18n.T(lang, "calendar.access", r.Name),
+				Description: description,
+				Start:       w.start,
+				End:         w.end,
+				Categories:  []string{i18n.T(lang, "calendar.category_access")},
+			},
+		)
+
+		firstEntry, lastExit := w.entryAndExit(logs)
+		if firstEntry != nil {
+			events = append(events, Event{
+				UID:         uid("entry", r.ID),
+				Summary:     i18n.T(lang, "calendar.first_entry", r.Name),
+				Description: description,
+				Start:       firstEntry.Date,
+				Categories:  []string{i18n.T(lang, "calendar.category_activity")},
+			})
+		}
+		if lastExit != nil && now.After(w.end) {
+			events = append(events, Event{
+				UID:         uid("exit", r.ID),
+				Summary:     i18n.T(lang, "calendar.last_exit", r.Name),
+				Description: description,
+				Start:       lastExit.Date,
+				Categories:  []string{i18n.T(lang, "calendar.category_activity")},
+			})
+		}
+	}
+	return events, nil
+}
+
+// atTime returns the date d at the time of day of t in the feed's timezone
+func (f *Feed) atTime(d, t time.Time) time.Time {
+	y, m, day := d.In(f.loc).Date()
+	return time.Date(y, m, day, t.Hour(), t.Minute(), 0, 0, f.loc)
+}
+
+// getLogs returns the logs covering all access windows which already started
+func (f *Feed) getLogs(windows []accessWindow, now time.Time) ([]model.NukiSmartlockLogResponse, error) {
+	var from time.Time
+	for _, w := range windows {
+		if w.start.Before(now) && (from.IsZero() || w.start.Before(from)) {
+			from = w.start
+		}
+	}
+	if from.IsZero() {
+		return nil, nil
+	}
+
+	lr := f.logsReader
+	lr.FromDate = from.AddDate(0, 0, -1)
+	lr.ToDate = time.Time{}
+	return lr.ExecuteAll(logsMaxRequests)
+}
+
+// entryAndExit returns the first use of the reservation's keypad code and the last exit.
+// The last exit is the first time the door is locked after the guest's last use of the keypad,
+// or this last use when the door is never locked until the end of the access window.
+// logs are sorted newest first.
+func (w accessWindow) entryAndExit(logs []model.NukiSmartlockLogResponse) (*model.NukiSmartlockLogResponse, *model.NukiSmartlockLogResponse) {
+	var firstEntry, lastUse *model.NukiSmartlockLogResponse
+	for i := range logs {
+		l := &logs[i]
+		if l.Trigger != model.NukiTriggerKeypad ||
+			l.Source != model.NukiSourceKeypadCode ||
+			l.State != model.NukiStateSuccess ||
+			l.Name != w.resa.Reference ||
+			l.Date.Before(w.start.AddDate(0, 0, -1)) ||
+			l.Date.After(w.end.Add(lastExitGracePeriod)) {
+			continue
+		}
+		if lastUse == nil {
+			lastUse = l
+		}
+		firstEntry = l
+	}
+	if lastUse == nil {
+		return nil, nil
+	}
+
+	lastExit := lastUse
+	for i := len(logs) - 1; i >= 0; i-- {
+		l := &logs[i]
+		if !l.Date.After(lastUse.Date) || l.Date.After(w.end.Add(lastExitGracePeriod)) {
+			continue
+		}
+		if (l.Action == model.NukiActionLock || l.Action == model.NukiActionLockNGo) && l.State == model.NukiStateSuccess {
+			lastExit = l
+			break
+		}
+	}
+	return firstEntry, lastExit
+}
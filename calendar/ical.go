@@ -0,0 +1,221 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateFormat     = "20060102"
+	icalDateTimeFormat = "20060102T150405Z"
+	// icalLineLength is the maximum length of a content line in octets, CRLF excluded (RFC 5545, section 3.1)
+	icalLineLength = 75
+)
+
+// Event is a VEVENT of an iCalendar feed
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	// End is optional for timed events, an event without end being a point in time
+	End time.Time
+	// AllDay events only use the dates of Start and End, End being exclusive
+	AllDay     bool
+	Categories []string
+}
+
+// WriteICal writes an iCalendar (RFC 5545) document containing events to w
+func WriteICal(w io.Writer, name string, events []Event, now time.Time) error {
+	iw := &icalWriter{w: w}
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//nmaupu//nuki-logger//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	iw.line("METHOD", "PUBLISH")
+	iw.line("X-WR-CALNAME", escapeText(name))
+	for _, e := range events {
+		iw.line("BEGIN", "VEVENT")
+		iw.line("UID", e.UID)
+		iw.line("DTSTAMP", now.UTC().Format(icalDateTimeFormat))
+		if e.AllDay {
+			iw.line("DTSTART;VALUE=DATE", e.Start.Format(icalDateFormat))
+			iw.line("DTEND;VALUE=DATE", e.End.Format(icalDateFormat))
+		} else {
+			iw.line("DTSTART", e.Start.UTC().Format(icalDateTimeFormat))
+			if !e.End.IsZero() {
+				iw.line("DTEND", e.End.UTC().Format(icalDateTimeFormat))
+			}
+		}
+		iw.line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			iw.line("DESCRIPTION", escapeText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, 0, len(e.Categories))
+			for _, c := range e.Categories {
+				categories = append(categories, escapeText(c))
+			}
+			iw.line("CATEGORIES", strings.Join(categories, ","))
+		}
+		iw.line("TRANSP", "TRANSPARENT")
+		iw.line("END", "VEVENT")
+	}
+	iw.line("END", "VCALENDAR")
+	return iw.err
+}
+
+// icalWriter writes folded content lines and keeps the first error encountered
+type icalWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icalWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	_, iw.err = io.WriteString(iw.w, fold(name+":"+value))
+}
+
+// fold splits a content line into lines of at most icalLineLength octets without breaking UTF-8 characters
+func fold(line string) string {
+	var sb strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > icalLineLength {
+			sb.WriteString("\r\n ")
+			length = 1
+		}
+		sb.WriteRune(r)
+		length += size
+	}
+	sb.WriteString("\r\n")
+	return sb.String()
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+func uid(kind, id string) string {
+	return fmt.Sprintf("%s-%s@nuki-logger", kind, id)
+}
+
+// ParseICal reads the VEVENTs of an iCalendar document.
+// Dates and floating date-times are read in loc.
+func ParseICal(r io.Reader, loc *time.Location) ([]Event, error) {
+	var events []Event
+	var current *Event
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				current = &Event{}
+			}
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && current != nil {
+				events = append(events, *current)
+				current = nil
+			}
+		}
+		if current == nil {
+			continue
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "UID":
+			current.UID = value
+		case "SUMMARY":
+			current.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(value)
+		case "CATEGORIES":
+			for _, c := range strings.Split(value, ",") {
+				current.Categories = append(current.Categories, unescapeText(c))
+			}
+		case "DTSTART":
+			current.Start, current.AllDay, err = parseICalDate(params, value, loc)
+		case "DTEND":
+			current.End, _, err = parseICalDate(params, value, loc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse event %s: %w", current.UID, err)
+		}
+	}
+	return events, nil
+}
+
+// unfold returns the content lines of an iCalendar document, joining folded lines
+func unfold(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, l := range strings.Split(string(data), "\n") {
+		l = strings.TrimRight(l, "\r")
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, nil
+}
+
+func parseICalDate(params, value string, loc *time.Location) (time.Time, bool, error) {
+	for _, p := range strings.Split(params, ";") {
+		k, v, _ := strings.Cut(p, "=")
+		switch {
+		case strings.EqualFold(k, "VALUE") && strings.EqualFold(v, "DATE"):
+			t, err := time.ParseInLocation(icalDateFormat, value, loc)
+			return t, true, err
+		case strings.EqualFold(k, "TZID"):
+			if l, err := time.LoadLocation(strings.Trim(v, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+	if len(value) == len(icalDateFormat) {
+		t, err := time.ParseInLocation(icalDateFormat, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTimeFormat, value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation(strings.TrimSuffix(icalDateTimeFormat, "Z"), value, loc)
+	return t, false, err
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(s)
+}
@@ -6,8 +6,19 @@ import (
 	"time"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/nmaupu/nuki-logger/api"
+	"github.com/nmaupu/nuki-logger/booking"
+	"github.com/nmaupu/nuki-logger/calendar"
+	"github.com/nmaupu/nuki-logger/dashboard"
+	"github.com/nmaupu/nuki-logger/drift"
+	"github.com/nmaupu/nuki-logger/hygiene"
+	"github.com/nmaupu/nuki-logger/lifecycle"
 	"github.com/nmaupu/nuki-logger/messaging"
 	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/nmaupu/nuki-logger/outbox"
+	"github.com/nmaupu/nuki-logger/polling"
+	"github.com/nmaupu/nuki-logger/stream"
+	"github.com/nmaupu/nuki-logger/telegrambot"
 	"github.com/spf13/viper"
 )
 
@@ -20,13 +31,32 @@ type Config struct {
 	AddressID    int64          `mapstructure:"address_id"`
 	Senders      []SenderConfig `mapstructure:"senders"`
 	TelegramBot  struct {
-		Enabled           bool           `mapstructure:"enabled"`
-		SenderName        string         `mapstructure:"sender_name"`
-		DefaultCheckIn    TimeHourMinute `mapstructure:"default_check_in"`
-		DefaultCheckOut   TimeHourMinute `mapstructure:"default_check_out"`
-		RestrictToChatIDs []int64        `mapstructure:"restrict_private_chat_ids"`
+		Enabled           bool                      `mapstructure:"enabled"`
+		SenderName        string                    `mapstructure:"sender_name"`
+		DefaultCheckIn    TimeHourMinute            `mapstructure:"default_check_in"`
+		DefaultCheckOut   TimeHourMinute            `mapstructure:"default_check_out"`
+		RestrictToChatIDs []int64                   `mapstructure:"restrict_private_chat_ids"`
+		Roles             telegrambot.Roles         `mapstructure:"roles"`
+		SessionTimeout    time.Duration             `mapstructure:"session_idle_timeout"`
+		PersistSessions   bool                      `mapstructure:"persist_sessions"`
+		Webhook           telegrambot.WebhookConfig `mapstructure:"webhook"`
+		Guests            telegrambot.GuestsConfig  `mapstructure:"guests"`
 	} `mapstructure:"telegram_bot"`
-	HealthCheckPort     int                         `mapstructure:"health_check_port"`
+	HealthCheckPort int `mapstructure:"health_check_port"`
+	HTTPServer      struct {
+		TLSCertFile string `mapstructure:"tls_cert_file"`
+		TLSKeyFile  string `mapstructure:"tls_key_file"`
+	} `mapstructure:"http_server"`
+	API                 api.Config                  `mapstructure:"api"`
+	Calendar            calendar.Config             `mapstructure:"calendar"`
+	Bookings            booking.Config              `mapstructure:"bookings"`
+	Dashboard           dashboard.Config            `mapstructure:"dashboard"`
+	Stream              stream.Config               `mapstructure:"stream"`
+	Outbox              outbox.Config               `mapstructure:"outbox"`
+	Polling             polling.Config              `mapstructure:"polling"`
+	Drift               drift.Config                `mapstructure:"drift"`
+	Lifecycle           lifecycle.Config            `mapstructure:"lifecycle"`
+	Hygiene             hygiene.Config              `mapstructure:"hygiene"`
 	MemcachedServers    []string                    `mapstructure:"memcached_servers"`
 	LogsReader          nukiapi.LogsReader          `mapstructure:"-"`
 	SmartlockReader     nukiapi.SmartlockReader     `mapstructure:"-"`
@@ -35,21 +65,23 @@ type Config struct {
 }
 
 func (c *Config) initReaders() {
+	// Readers share the token so that it can be rotated by a reload
+	token := nukiapi.NewToken(c.NukiAPIToken)
 	c.LogsReader = nukiapi.LogsReader{
-		APICaller:   nukiapi.APICaller{Token: c.NukiAPIToken},
+		APICaller:   nukiapi.APICaller{Token: token},
 		SmartlockID: c.SmartlockID,
 		Limit:       20,
 	}
 	c.SmartlockReader = nukiapi.SmartlockReader{
-		APICaller:   nukiapi.APICaller{Token: c.NukiAPIToken},
+		APICaller:   nukiapi.APICaller{Token: token},
 		SmartlockID: c.SmartlockID,
 	}
 	c.ReservationsReader = nukiapi.ReservationsReader{
-		APICaller: nukiapi.APICaller{Token: c.NukiAPIToken},
+		APICaller: nukiapi.APICaller{Token: token},
 		AddressID: c.AddressID,
 	}
 	c.SmartlockAuthReader = nukiapi.SmartlockAuthReader{
-		APICaller:   nukiapi.APICaller{Token: c.NukiAPIToken},
+		APICaller:   nukiapi.APICaller{Token: token},
 		SmartlockID: c.SmartlockID,
 	}
 }
@@ -90,9 +122,23 @@ func (c *Config) LoadConfig(vi *viper.Viper) error {
 			StringToTimeHourMinuteHookFunc(),
 		),
 	)
+
+	// Rejecting unknown keys using the config file only, flags and env being bound to the global viper
+	strict := viper.New()
+	strict.SetConfigFile(vi.ConfigFileUsed())
+	if err := strict.ReadInConfig(); err != nil {
+		return err
+	}
+	if err := strict.UnmarshalExact(&Config{}, decoderConfigOpt); err != nil {
+		return fmt.Errorf("invalid configuration file %s: %w", vi.ConfigFileUsed(), err)
+	}
+
 	if err := vi.Unmarshal(c, decoderConfigOpt); err != nil {
 		return err
 	}
+	if err := c.resolveSecrets(); err != nil {
+		return fmt.Errorf("unable to resolve secrets: %w", err)
+	}
 	c.initReaders()
 
 	return nil
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagConfigCheckAPI = "check-api"
+)
+
+var (
+	ConfigCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Validate the configuration or export its schema",
+		// Overriding the root's checks, senders are not needed here
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	ConfigValidateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration file, reporting all the problems found",
+		RunE:  ConfigValidateRun,
+	}
+	ConfigSchemaCmd = &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration file, for editors' autocompletion",
+		RunE:  ConfigSchemaRun,
+	}
+)
+
+func init() {
+	ConfigValidateCmd.Flags().Bool(FlagConfigCheckAPI, false, "Also check the Nuki API token against the API")
+	_ = viper.BindPFlags(ConfigValidateCmd.Flags())
+
+	ConfigCmd.AddCommand(ConfigValidateCmd)
+	ConfigCmd.AddCommand(ConfigSchemaCmd)
+}
+
+func ConfigValidateRun(_ *cobra.Command, _ []string) error {
+	if viper.GetString(PersistentFlagConfig) == "" {
+		return fmt.Errorf("the following flag(s) are required: %s", PersistentFlagConfig)
+	}
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if viper.GetBool(FlagConfigCheckAPI) {
+		if err := config.CheckAPI(); err != nil {
+			return err
+		}
+	}
+	log.Info().
+		Str("file", viper.ConfigFileUsed()).
+		Msg("Configuration is valid")
+	return nil
+}
+
+func ConfigSchemaRun(_ *cobra.Command, _ []string) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ConfigSchema())
+}
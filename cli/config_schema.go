@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	durationPattern       = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	timeHourMinutePattern = `^[0-2][0-9]:[0-5][0-9]$`
+)
+
+// ConfigSchema returns a JSON Schema of the configuration file, generated from the Config struct
+func ConfigSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "nuki-logger configuration"
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	case reflect.TypeOf(TimeHourMinute{}):
+		return map[string]any{"type": "string", "pattern": timeHourMinutePattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		addStructProperties(t, properties)
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	default:
+		return map[string]any{}
+	}
+}
+
+func addStructProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if opts == "squash" {
+			addStructProperties(f.Type, properties)
+			continue
+		}
+		if !f.IsExported() || name == "" {
+			continue
+		}
+		properties[name] = typeSchema(f.Type)
+	}
+}
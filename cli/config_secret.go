@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/nmaupu/nuki-logger/secret"
+	"golang.org/x/exp/maps"
+)
+
+// secrets returns the secret settings of the configuration by key, senders' tokens excepted as senders are pointers
+func (c *Config) secrets() map[string]*string {
+	res := map[string]*string{
+		"nuki_api_token":                    &c.NukiAPIToken,
+		"api.token":                         &c.API.Token,
+		"calendar.token":                    &c.Calendar.Token,
+		"stream.token":                      &c.Stream.Token,
+		"dashboard.session_secret":          &c.Dashboard.SessionSecret,
+		"telegram_bot.webhook.secret_token": &c.TelegramBot.Webhook.SecretToken,
+	}
+	for i := range c.Dashboard.Users {
+		res[fmt.Sprintf("dashboard.users.%s.password", c.Dashboard.Users[i].Username)] = &c.Dashboard.Users[i].Password
+	}
+	// iCal exports of OTAs are authenticated by a token in their url
+	for i := range c.Bookings.Feeds {
+		res[fmt.Sprintf("bookings.feeds.%s.url", c.Bookings.Feeds[i].Name)] = &c.Bookings.Feeds[i].URL
+	}
+	return res
+}
+
+// resolveSecrets replaces the secret references (env:, file: and enc:) with the secrets themselves
+func (c *Config) resolveSecrets() error {
+	secrets := c.secrets()
+	for _, s := range c.Senders {
+		if s.Telegram != nil {
+			secrets[fmt.Sprintf("senders.%s.telegram.token", s.Name)] = &s.Telegram.Token
+		}
+	}
+
+	keys := maps.Keys(secrets)
+	slices.Sort(keys)
+	var errs []error
+	for _, key := range keys {
+		value := secrets[key]
+		resolved, err := secret.Resolve(*value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		*value = resolved
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the configuration without its secrets, to be displayed
+func (c Config) Redacted() Config {
+	c.Dashboard.Users = slices.Clone(c.Dashboard.Users)
+	c.Bookings.Feeds = slices.Clone(c.Bookings.Feeds)
+	for _, value := range c.secrets() {
+		*value = secret.Redact(*value)
+	}
+	c.Senders = slices.Clone(c.Senders)
+	for i, s := range c.Senders {
+		if s.Telegram != nil {
+			c.Senders[i].Telegram = s.Telegram.WithToken(secret.Redact(s.Telegram.Token))
+		}
+	}
+	return c
+}
+
+// String never displays secrets, whatever the format used to print the configuration
+func (c Config) String() string {
+	type plain Config
+	return fmt.Sprintf("%+v", plain(c.Redacted()))
+}
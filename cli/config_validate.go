@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/nmaupu/nuki-logger/model"
+)
+
+// Validate checks the whole configuration, returning all the problems found
+func (c *Config) Validate() error {
+	var errs []error
+	addErr := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	checkTimezone := func(key, tz string) {
+		if _, err := time.LoadLocation(tz); err != nil {
+			addErr("%s: unknown timezone %q", key, tz)
+		}
+	}
+	checkLanguage := func(key, lang string) {
+		if lang != "" && !i18n.IsSupported(strings.ToLower(lang)) {
+			addErr("%s: unsupported language %q, expected one of %v", key, lang, i18n.Languages())
+		}
+	}
+	checkHTTPServer := func(key string) {
+		if c.HealthCheckPort <= 0 {
+			addErr("%s: needs the http server, please set health_check_port", key)
+		}
+	}
+
+	if c.NukiAPIToken == "" {
+		addErr("nuki_api_token is mandatory")
+	}
+	if c.SmartlockID == 0 {
+		addErr("smartlock_id is mandatory")
+	}
+	if c.AddressID == 0 && (c.TelegramBot.Enabled || c.Calendar.Enabled || c.API.Enabled || c.Dashboard.Enabled || c.Polling.Enabled) {
+		addErr("address_id is mandatory to read reservations")
+	}
+	if (c.HTTPServer.TLSCertFile == "") != (c.HTTPServer.TLSKeyFile == "") {
+		addErr("http_server: tls_cert_file and tls_key_file must be set together")
+	}
+
+	names := make(map[string]bool)
+	for i, s := range c.Senders {
+		key := fmt.Sprintf("senders[%d]", i)
+		if s.Name == "" {
+			addErr("%s: name is mandatory", key)
+		} else {
+			key = fmt.Sprintf("senders.%s", s.Name)
+		}
+		if names[s.Name] {
+			addErr("%s: duplicated sender name", key)
+		}
+		names[s.Name] = true
+		if s.Telegram != nil && s.Console != nil {
+			addErr("%s: only one of telegram or console can be set", key)
+		}
+		sender, err := s.GetSender()
+		if err != nil {
+			addErr("%s: %w", key, err)
+			continue
+		}
+		checkTimezone(key+".timezone", sender.GetTimezone())
+		if s.Telegram != nil {
+			checkLanguage(key+".language", s.Telegram.Language)
+		} else if s.Console != nil {
+			checkLanguage(key+".language", s.Console.Language)
+		}
+	}
+
+	if c.TelegramBot.Enabled {
+		sender, err := c.GetSender(c.TelegramBot.SenderName)
+		if err != nil {
+			addErr("telegram_bot.sender_name: %w", err)
+		} else if _, ok := sender.(*messaging.TelegramSender); !ok {
+			addErr("telegram_bot.sender_name: sender %s is not a telegram sender", sender.GetName())
+		}
+		if c.TelegramBot.Webhook.Enabled {
+			checkHTTPServer("telegram_bot.webhook")
+			if c.TelegramBot.Webhook.URL == "" {
+				addErr("telegram_bot.webhook.url is mandatory")
+			}
+			if err := c.TelegramBot.Webhook.ValidateSecretToken(); err != nil {
+				addErr("telegram_bot.webhook.%w", err)
+			}
+		}
+		for _, t := range c.TelegramBot.Guests.LateCheckoutTimes {
+			if _, err := time.Parse(model.FormatTimeHoursMinutes, t); err != nil {
+				addErr("telegram_bot.guests.late_checkout_times: invalid time %q, HH:MM expected", t)
+			}
+		}
+	}
+
+	if c.API.Enabled {
+		checkHTTPServer("api")
+		if c.API.Token == "" {
+			addErr("api.token is mandatory")
+		}
+	}
+	if c.Calendar.Enabled {
+		checkHTTPServer("calendar")
+		if c.Calendar.Token == "" {
+			addErr("calendar.token is mandatory")
+		}
+		checkTimezone("calendar.timezone", c.Calendar.Timezone)
+		checkLanguage("calendar.language", c.Calendar.Language)
+	}
+	if c.Dashboard.Enabled {
+		checkHTTPServer("dashboard")
+		if len(c.Dashboard.Users) == 0 && !c.Dashboard.Telegram.IsEnabled() {
+			addErr("dashboard: at least a user or the telegram login is needed")
+		}
+		if c.Dashboard.Telegram.IsEnabled() && !c.TelegramBot.Enabled {
+			addErr("dashboard.telegram_login: needs the telegram bot to verify logins")
+		}
+		checkTimezone("dashboard.timezone", c.Dashboard.Timezone)
+		checkLanguage("dashboard.language", c.Dashboard.Language)
+	}
+	if c.Stream.Enabled {
+		checkHTTPServer("stream")
+		if c.Stream.Token == "" {
+			addErr("stream.token is mandatory")
+		}
+	}
+	if c.Bookings.IsEnabled() && !c.TelegramBot.Enabled {
+		addErr("bookings: needs the telegram bot to be enabled")
+	}
+	for i, f := range c.Bookings.Feeds {
+		if err := f.Validate(); err != nil {
+			addErr("bookings.feeds[%d] (%s): %w", i, f.Name, err)
+		}
+	}
+	if err := c.Drift.Validate(); err != nil {
+		addErr("drift: %w", err)
+	}
+	if err := c.Lifecycle.Validate(); err != nil {
+		addErr("lifecycle: %w", err)
+	}
+	if c.Hygiene.Enabled && !c.TelegramBot.Enabled {
+		addErr("hygiene: needs the telegram bot to be enabled")
+	}
+	if err := c.Hygiene.Validate(); err != nil {
+		addErr("hygiene: %w", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckAPI checks that the Nuki API token gives access to the smartlock and the address
+func (c *Config) CheckAPI() error {
+	if _, err := c.SmartlockReader.Execute(); err != nil {
+		return fmt.Errorf("unable to read smartlock %d: %w", c.SmartlockID, err)
+	}
+	if c.AddressID != 0 {
+		if _, err := c.ReservationsReader.Execute(); err != nil {
+			return fmt.Errorf("unable to read reservations of address %d: %w", c.AddressID, err)
+		}
+	}
+	return nil
+}
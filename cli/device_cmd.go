@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/lifecycle"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagDeviceJSON     = "device-json"
+	FlagDeviceLanguage = "device-lang"
+)
+
+var (
+	DeviceCmd = &cobra.Command{
+		Use:   "device",
+		Short: "Print the smartlock's versions and subscription with the history recorded by the server",
+		// Printing to stdout, senders are not needed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if viper.GetString(PersistentFlagConfig) == "" {
+				return fmt.Errorf("the following flag(s) are required: %s", PersistentFlagConfig)
+			}
+			return loadConfig()
+		},
+		RunE: DeviceRun,
+	}
+)
+
+func init() {
+	DeviceCmd.Flags().Bool(FlagJson, false, "Output the device and its history in json")
+	DeviceCmd.Flags().String(FlagStatusLanguage, i18n.DefaultLanguage, fmt.Sprintf("Language of the output %v", i18n.Languages()))
+	_ = viper.BindPFlag(FlagDeviceJSON, DeviceCmd.Flags().Lookup(FlagJson))
+	_ = viper.BindPFlag(FlagDeviceLanguage, DeviceCmd.Flags().Lookup(FlagStatusLanguage))
+}
+
+func DeviceRun(_ *cobra.Command, _ []string) error {
+	res, err := config.SmartlockReader.Execute()
+	if err != nil {
+		return err
+	}
+
+	// The history is recorded by the server, it can only be read from its cache
+	var historyCache cache.Cache
+	if len(config.MemcachedServers) == 0 {
+		log.Warn().Msg("no cache server configured, the device history cannot be read")
+	} else {
+		historyCache = cache.NewMemcached(config.MemcachedServers)
+	}
+	events := lifecycle.NewTracker(config.Lifecycle, historyCache).Events()
+
+	if viper.GetBool(FlagDeviceJSON) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Name                string              `json:"name"`
+			FirmwareVersion     string              `json:"firmware_version"`
+			HardwareVersion     string              `json:"hardware_version"`
+			CurrentSubscription any                 `json:"current_subscription"`
+			Events              []model.DeviceEvent `json:"events"`
+		}{
+			Name:                res.Name,
+			FirmwareVersion:     model.FormatFirmwareVersion(res.FirmwareVersion),
+			HardwareVersion:     model.FormatHardwareVersion(res.HardwareVersion),
+			CurrentSubscription: res.CurrentSubscription,
+			Events:              events,
+		})
+	}
+	fmt.Println(lifecycle.Format(viper.GetString(FlagDeviceLanguage), time.Local, *res, events))
+	return nil
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/hygiene"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagHygieneJSON     = "hygiene-json"
+	FlagHygieneLanguage = "hygiene-lang"
+	FlagHygieneApply    = "hygiene-apply"
+	FlagHygieneAction   = "hygiene-action"
+	FlagHygieneKind     = "hygiene-kind"
+)
+
+var (
+	HygieneCmd = &cobra.Command{
+		Use:   "hygiene",
+		Short: "Review stale authorizations, disabling or deleting them with --apply",
+		// Printing to stdout, senders are not needed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if viper.GetString(PersistentFlagConfig) == "" {
+				return fmt.Errorf("the following flag(s) are required: %s", PersistentFlagConfig)
+			}
+			return loadConfig()
+		},
+		RunE: HygieneRun,
+	}
+)
+
+func init() {
+	defaultKinds := make([]string, 0, len(hygiene.DefaultApplyKinds))
+	for _, k := range hygiene.DefaultApplyKinds {
+		defaultKinds = append(defaultKinds, string(k))
+	}
+	HygieneCmd.Flags().Bool(FlagJson, false, "Output the report in json")
+	HygieneCmd.Flags().String(FlagStatusLanguage, i18n.DefaultLanguage, fmt.Sprintf("Language of the output %v", i18n.Languages()))
+	HygieneCmd.Flags().Bool("apply", false, "Act on the reported authorizations, only printing the report otherwise")
+	HygieneCmd.Flags().String("action", string(hygiene.ActionDisable),
+		fmt.Sprintf("Action applied to the authorizations (%s or %s)", hygiene.ActionDisable, hygiene.ActionDelete))
+	HygieneCmd.Flags().StringSlice("kind", defaultKinds, fmt.Sprintf("Findings acted upon with --apply %v", hygiene.Kinds))
+	_ = viper.BindPFlag(FlagHygieneJSON, HygieneCmd.Flags().Lookup(FlagJson))
+	_ = viper.BindPFlag(FlagHygieneLanguage, HygieneCmd.Flags().Lookup(FlagStatusLanguage))
+	_ = viper.BindPFlag(FlagHygieneApply, HygieneCmd.Flags().Lookup("apply"))
+	_ = viper.BindPFlag(FlagHygieneAction, HygieneCmd.Flags().Lookup("action"))
+	_ = viper.BindPFlag(FlagHygieneKind, HygieneCmd.Flags().Lookup("kind"))
+}
+
+func HygieneRun(_ *cobra.Command, _ []string) error {
+	action, err := hygiene.ParseAction(viper.GetString(FlagHygieneAction))
+	if err != nil {
+		return err
+	}
+	var kinds []hygiene.FindingKind
+	for _, k := range viper.GetStringSlice(FlagHygieneKind) {
+		kind, err := hygiene.ParseKind(k)
+		if err != nil {
+			return err
+		}
+		kinds = append(kinds, kind)
+	}
+
+	auths, err := config.SmartlockAuthReader.Execute()
+	if err != nil {
+		return err
+	}
+	// Codes are named after their reservation's reference
+	reservations, err := config.ReservationsReader.Execute()
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to get reservations, relying on auths' expiry only")
+	}
+	report := hygiene.Analyze(config.Hygiene, auths, reservations, time.Now())
+
+	if viper.GetBool(FlagHygieneJSON) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(report.Format(viper.GetString(FlagHygieneLanguage), time.Local))
+	}
+
+	if !viper.GetBool(FlagHygieneApply) {
+		return nil
+	}
+	modifier := nukiapi.SmartlockAuthModifier{
+		APICaller:   config.SmartlockAuthReader.APICaller,
+		SmartlockID: config.SmartlockAuthReader.SmartlockID,
+	}
+	failed := 0
+	for _, f := range report.Select(kinds) {
+		if action == hygiene.ActionDisable && !f.Enabled {
+			continue
+		}
+		if err := f.Apply(modifier, action); err != nil {
+			failed++
+			log.Error().Err(err).Str("auth", f.Name).Str("finding", string(f.Kind)).Msgf("Unable to %s authorization", action)
+			continue
+		}
+		log.Info().Str("auth", f.Name).Str("finding", string(f.Kind)).Msgf("Authorization %sd", action)
+	}
+	if failed > 0 {
+		return fmt.Errorf("unable to %s %d authorization(s)", action, failed)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/outbox"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagOutboxDead = "dead"
+	FlagOutboxAll  = "all"
+)
+
+var (
+	OutboxCmd = &cobra.Command{
+		Use:   "outbox",
+		Short: "Inspect and replay events waiting to be delivered to senders",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := RootCmd.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+			if !config.Outbox.IsEnabled() {
+				return fmt.Errorf("outbox is disabled, please set outbox.dir")
+			}
+			return nil
+		},
+	}
+	OutboxListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List pending events or dead letters",
+		RunE:  OutboxListRun,
+	}
+	OutboxReplayCmd = &cobra.Command{
+		Use:   "replay [id...]",
+		Short: "Move dead letters back to pending events, the server retrying to deliver them",
+		RunE:  OutboxReplayRun,
+	}
+)
+
+func init() {
+	OutboxListCmd.Flags().Bool(FlagOutboxDead, false, "List dead letters instead of pending events")
+	OutboxReplayCmd.Flags().Bool(FlagOutboxAll, false, "Replay all dead letters")
+	_ = viper.BindPFlags(OutboxListCmd.Flags())
+	_ = viper.BindPFlags(OutboxReplayCmd.Flags())
+
+	OutboxCmd.AddCommand(OutboxListCmd)
+	OutboxCmd.AddCommand(OutboxReplayCmd)
+}
+
+func OutboxListRun(_ *cobra.Command, _ []string) error {
+	store := outbox.Store{Dir: config.Outbox.Dir}
+	list := store.Pending
+	if viper.GetBool(FlagOutboxDead) {
+		list = store.DeadLetters
+	}
+	entries, err := list()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSENDER\tKIND\tEVENTS\tCREATED\tATTEMPTS\tNEXT ATTEMPT\tLAST ERROR")
+	for _, e := range entries {
+		next := "-"
+		if !e.NextAttempt.IsZero() {
+			next = e.NextAttempt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+			e.ID, e.Sender, e.Kind, len(e.Events), e.CreatedAt.Local().Format(time.DateTime), e.Attempts, next, e.LastError)
+	}
+	return w.Flush()
+}
+
+func OutboxReplayRun(_ *cobra.Command, args []string) error {
+	store := outbox.Store{Dir: config.Outbox.Dir}
+	ids := args
+	if viper.GetBool(FlagOutboxAll) {
+		dead, err := store.DeadLetters()
+		if err != nil {
+			return err
+		}
+		ids = nil
+		for _, e := range dead {
+			ids = append(ids, e.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("no dead letter to replay, give ids or --%s", FlagOutboxAll)
+	}
+
+	for _, id := range ids {
+		if err := store.Replay(id); err != nil {
+			return err
+		}
+		log.Info().Str("id", id).Msg("Dead letter moved back to pending events")
+	}
+	return nil
+}
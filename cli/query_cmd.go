@@ -2,21 +2,32 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
 	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/nmaupu/nuki-logger/messaging"
 	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
-	FlagLimit    = "limit"
-	FlagFromDate = "from"
-	FlagToDate   = "to"
-	FlagJson     = "json"
+	FlagLimit        = "limit"
+	FlagFromDate     = "from"
+	FlagToDate       = "to"
+	FlagSince        = "since"
+	FlagMaxRequests  = "max-requests"
+	FlagJson         = "json"
+	FlagFollow       = "follow"
+	FlagPollInterval = "poll-interval"
+	FlagFormat       = "format"
+	FlagGroupBy      = "group-by"
 
 	FromDateTime = "fromDateTime"
 	ToDateTime   = "toDateTime"
@@ -30,27 +41,62 @@ var (
 	QueryCmd = &cobra.Command{
 		Use:   "query",
 		Short: "Query logs from the Nuki API",
+		Long: "Query logs from the Nuki API, sending them to the given senders or printing them to stdout " +
+			"when no sender is given or when --format is set.",
+		// Printing to stdout, senders are not needed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !viper.GetBool(FlagFollow) && len(viper.GetStringSlice(PersistentFlagSender)) > 0 {
+				return RootCmd.PersistentPreRunE(cmd, args)
+			}
+			if viper.GetString(PersistentFlagConfig) == "" {
+				return fmt.Errorf("the following flag(s) are required: %s", PersistentFlagConfig)
+			}
+			return loadConfig()
+		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			lim := viper.GetInt(FlagLimit)
 			if lim <= 0 || lim > 50 {
 				return fmt.Errorf("limit is out of bound. Should be between 1 and 50")
 			}
 
+			now := time.Now()
+			if viper.GetString(FlagFromDate) != "" && viper.GetString(FlagSince) != "" {
+				return fmt.Errorf("--%s and --%s cannot be used together", FlagFromDate, FlagSince)
+			}
 			if viper.GetString(FlagFromDate) != "" {
-				fromDate, err := time.Parse(time.RFC3339, viper.GetString(FlagFromDate))
+				fromDate, err := parseQueryDate(viper.GetString(FlagFromDate), now)
 				if err != nil {
 					return err
 				}
 				viper.Set(FromDateTime, fromDate)
 			}
+			if viper.GetString(FlagSince) != "" {
+				d, err := parseQueryDuration(viper.GetString(FlagSince))
+				if err != nil {
+					return fmt.Errorf("invalid --%s duration: %w", FlagSince, err)
+				}
+				viper.Set(FromDateTime, now.Add(-d))
+			}
 			if viper.GetString(FlagToDate) != "" {
-				toDate, err := time.Parse(time.RFC3339, viper.GetString(FlagToDate))
+				if viper.GetBool(FlagFollow) {
+					return fmt.Errorf("--%s cannot be used with --%s", FlagToDate, FlagFollow)
+				}
+				toDate, err := parseQueryDate(viper.GetString(FlagToDate), now)
 				if err != nil {
 					return err
 				}
 				viper.Set(ToDateTime, toDate)
 			}
 
+			for _, f := range viper.GetStringSlice(FlagGroupBy) {
+				if err := checkLogField(f); err != nil {
+					return fmt.Errorf("invalid --%s: %w", FlagGroupBy, err)
+				}
+			}
+			if len(viper.GetStringSlice(FlagGroupBy)) > 0 && viper.GetBool(FlagFollow) {
+				return fmt.Errorf("--%s cannot be used with --%s", FlagGroupBy, FlagFollow)
+			}
+
 			return nil
 		},
 		RunE: QueryRun,
@@ -58,30 +104,87 @@ var (
 )
 
 func init() {
-	QueryCmd.Flags().IntP(FlagLimit, "l", 20, "Limits number of logs returned by the Nuki API (max: 50)")
-	QueryCmd.Flags().String(FlagFromDate, "", "Retrieve logs from this date (RFC3339")
-	QueryCmd.Flags().String(FlagToDate, "", "Retrieve logs to this date (RFC3339)")
+	QueryCmd.Flags().IntP(FlagLimit, "l", 20, "Limits number of logs returned by the Nuki API (max: 50), all logs being returned for a date range")
+	QueryCmd.Flags().String(FlagFromDate, "", "Retrieve logs from this date: RFC3339, 2006-01-02 15:04, 2006-01-02, today, yesterday or a duration ago (24h, 7d)")
+	QueryCmd.Flags().String(FlagToDate, "", "Retrieve logs to this date, same formats as --from")
+	QueryCmd.Flags().String(FlagSince, "", "Retrieve logs for this duration until now (e.g. 24h, 7d)")
+	QueryCmd.Flags().Int(FlagMaxRequests, 20, "Maximum number of requests made to the Nuki API for a date range, 50 logs each")
 	QueryCmd.Flags().Bool(FlagJson, false, "Output results in json")
+	QueryCmd.Flags().BoolP(FlagFollow, "f", false, "Print the last logs then new ones as they appear, to stdout")
+	QueryCmd.Flags().Duration(FlagPollInterval, time.Second*10, "Interval at which to check new logs when following")
+	QueryCmd.Flags().StringP(FlagFormat, "o", FormatTable, fmt.Sprintf("Output format to stdout (%s)", strings.Join(queryFormats, ", ")))
+	QueryCmd.Flags().StringSlice(FlagGroupBy, []string{}, fmt.Sprintf("Count logs by these fields (%s)", strings.Join(logFields, ", ")))
+	QueryCmd.Flags().StringSlice(FlagFilterAction, []string{}, "Only logs with these actions, names (e.g. unlock) or numbers")
+	QueryCmd.Flags().StringSlice(FlagFilterTrigger, []string{}, "Only logs with these triggers, names (e.g. keypad) or numbers")
+	QueryCmd.Flags().StringSlice(FlagFilterState, []string{}, "Only logs with these states, names (e.g. success) or numbers")
+	QueryCmd.Flags().StringSlice(FlagFilterSource, []string{}, "Only logs with these sources, names (e.g. \"keypad code\") or numbers")
+	QueryCmd.Flags().String(FlagFilterName, "", "Only logs whose name or reservation's name matches this regex")
+	QueryCmd.Flags().StringArray(FlagFilterWhere, []string{},
+		"Only logs matching this condition, e.g. 'auth_id=123', 'hour>=22', 'name~^A', 'state!=success' (repeatable)")
 
 	_ = viper.BindPFlags(QueryCmd.Flags())
 }
 
-func QueryRun(_ *cobra.Command, _ []string) error {
+func QueryRun(cmd *cobra.Command, _ []string) error {
+	filter, err := newLogFilterFromFlags(time.Now())
+	if err != nil {
+		return err
+	}
+
 	logsReader := config.LogsReader
 	logsReader.Limit = viper.GetInt(FlagLimit)
 	logsReader.FromDate = viper.GetTime(FromDateTime)
 	logsReader.ToDate = viper.GetTime(ToDateTime)
-	logs, err := logsReader.Execute()
+	var logs []model.NukiSmartlockLogResponse
+	if logsReader.FromDate.IsZero() && logsReader.ToDate.IsZero() {
+		logs, err = logsReader.Execute()
+	} else {
+		logs, err = logsReader.ExecuteAll(viper.GetInt(FlagMaxRequests))
+	}
 	if err != nil {
 		return err
 	}
 
 	slices.Reverse(logs)
+	events, err := toQueryEvents(logs, filter)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool(FlagFollow) {
+		return queryFollow(logs, events, filter)
+	}
+
+	if len(senders) == 0 || cmd.Flags().Changed(FlagFormat) {
+		printer, err := newLogPrinter(os.Stdout, viper.GetString(FlagFormat), false)
+		if err != nil {
+			return err
+		}
+		if groupBy := viper.GetStringSlice(FlagGroupBy); len(groupBy) > 0 {
+			return printer.PrintGroups(groupBy, groupLogs(events, groupBy))
+		}
+		return printer.Print(events)
+	}
+
+	for _, sender := range senders {
+		if err := sender.Send(events); err != nil {
+			log.Error().
+				Err(err).
+				Str("sender", sender.GetName()).
+				Msg("Unable to send message")
+		}
+	}
+
+	return nil
+}
 
+// toQueryEvents converts the logs matching filter to events, resolving reservations' names
+func toQueryEvents(logs []model.NukiSmartlockLogResponse, filter logFilter) ([]*messaging.Event, error) {
 	var events []*messaging.Event
 	for _, l := range logs {
 		var reservationName string
 		if l.Trigger == model.NukiTriggerKeypad && l.Source == model.NukiSourceKeypadCode && l.State != model.NukiStateWrongKeypadCode {
+			var err error
 			reservationName, err = config.ReservationsReader.GetReservationName(l.Name)
 			if err != nil {
 				log.Error().
@@ -92,21 +195,62 @@ func QueryRun(_ *cobra.Command, _ []string) error {
 			}
 		}
 
-		events = append(events, &messaging.Event{
+		e := &messaging.Event{
 			Log:             l,
 			ReservationName: reservationName,
 			Json:            viper.GetBool(FlagJson),
-		})
+		}
+		ok, err := filter.Match(e)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			events = append(events, e)
+		}
 	}
+	return events, nil
+}
 
-	for _, sender := range senders {
-		if err := sender.Send(events); err != nil {
-			log.Error().
-				Err(err).
-				Str("sender", sender.GetName()).
-				Msg("Unable to send message")
-		}
+// queryFollow prints the last logs then polls for new ones the same way the server does, until interrupted
+func queryFollow(known []model.NukiSmartlockLogResponse, last []*messaging.Event, filter logFilter) error {
+	printer, err := newLogPrinter(os.Stdout, viper.GetString(FlagFormat), true)
+	if err != nil {
+		return err
+	}
+	if err := printer.Print(last); err != nil {
+		return err
 	}
 
-	return nil
+	reader := config.LogsReader
+	reader.Limit = viper.GetInt(FlagLimit)
+	tracker := nukiapi.NewLogsTracker(reader, known)
+
+	interruptSigChan := make(chan os.Signal, 1)
+	signal.Notify(interruptSigChan, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(viper.GetDuration(FlagPollInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			logs, err := tracker.Poll()
+			if err != nil {
+				log.Error().Err(err).Msg("An error occurred getting logs from API")
+				continue
+			}
+			if len(logs) == 0 {
+				continue
+			}
+			filter.now = time.Now()
+			events, err := toQueryEvents(logs, filter)
+			if err != nil {
+				return err
+			}
+			if err := printer.Print(events); err != nil {
+				return err
+			}
+			tracker.Commit(logs)
+		case <-interruptSigChan:
+			return nil
+		}
+	}
 }
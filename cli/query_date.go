@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryDateLayouts are the absolute dates accepted, in local time when the zone is not given
+var queryDateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", time.DateTime, time.DateOnly}
+
+// parseQueryDate parses an absolute date, now, today, yesterday or a duration ago such as 24h, 7d or -90m
+func parseQueryDate(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch lower {
+	case "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if d, err := parseQueryDuration(strings.TrimSuffix(strings.TrimPrefix(lower, "-"), " ago")); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range queryDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected a date (RFC3339, 2006-01-02 15:04 or 2006-01-02), "+
+		"now, today, yesterday or a duration ago such as 24h or 7d", s)
+}
+
+// parseQueryDuration parses a duration, days being allowed with the d unit
+func parseQueryDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Hour * 24 * time.Duration(n), nil
+	}
+	return time.ParseDuration(s)
+}
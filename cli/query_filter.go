@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagFilterAction  = "action"
+	FlagFilterTrigger = "trigger"
+	FlagFilterState   = "state"
+	FlagFilterSource  = "source"
+	FlagFilterName    = "name"
+	FlagFilterWhere   = "where"
+)
+
+var (
+	// logFields are the fields logs can be filtered and grouped by, day and hour being buckets of the log's date
+	logFields = []string{
+		"id", "date", "day", "hour", "smartlock_id", "device_type", "account_user_id", "auth_id",
+		"name", "reservation_name", "action", "trigger", "state", "source", "auto_unlock",
+	}
+	// conditionOperators are ordered so that the longest operators are found first
+	conditionOperators = []string{"!=", ">=", "<=", "!~", "=", "~", ">", "<"}
+	conditionRegex     = regexp.MustCompile(`^([a-z_]+)\s*(!=|>=|<=|!~|=|~|>|<)\s*(.*)$`)
+)
+
+// fieldValue is the value of a log's field, enums having both a name and a number
+type fieldValue struct {
+	text     string
+	number   int64
+	isNumber bool
+	date     time.Time
+}
+
+func (v fieldValue) equals(s string) bool {
+	return strings.EqualFold(v.text, s) || (v.isNumber && strconv.FormatInt(v.number, 10) == s)
+}
+
+// compare returns -1, 0 or 1 comparing the value to s as a date, a number or a text
+func (v fieldValue) compare(s string, now time.Time) (int, error) {
+	switch {
+	case !v.date.IsZero():
+		d, err := parseQueryDate(s, now)
+		if err != nil {
+			return 0, err
+		}
+		return v.date.Compare(d), nil
+	case v.isNumber:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s is not a number", s)
+		}
+		return compareInt(v.number, n), nil
+	default:
+		return strings.Compare(strings.ToLower(v.text), strings.ToLower(s)), nil
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// logFieldValue returns the value of one of logFields
+func logFieldValue(e *messaging.Event, field string) fieldValue {
+	l := e.Log
+	switch field {
+	case "id":
+		return fieldValue{text: l.ID}
+	case "date":
+		return fieldValue{text: l.Date.Local().Format(time.RFC3339), date: l.Date}
+	case "day":
+		return fieldValue{text: l.Date.Local().Format(time.DateOnly)}
+	case "hour":
+		return fieldValue{text: l.Date.Local().Format("15"), number: int64(l.Date.Local().Hour()), isNumber: true}
+	case "smartlock_id":
+		return fieldValue{text: strconv.FormatInt(l.SmartLockID, 10), number: l.SmartLockID, isNumber: true}
+	case "device_type":
+		return fieldValue{text: l.DeviceType.String(), number: int64(l.DeviceType), isNumber: true}
+	case "account_user_id":
+		return fieldValue{text: strconv.Itoa(int(l.AccountUserID)), number: int64(l.AccountUserID), isNumber: true}
+	case "auth_id":
+		return fieldValue{text: l.AuthID}
+	case "name":
+		return fieldValue{text: l.Name}
+	case "reservation_name":
+		return fieldValue{text: e.ReservationName}
+	case "action":
+		return fieldValue{text: l.Action.String(), number: int64(l.Action), isNumber: true}
+	case "trigger":
+		return fieldValue{text: l.Trigger.String(), number: int64(l.Trigger), isNumber: true}
+	case "state":
+		return fieldValue{text: l.State.String(), number: int64(l.State), isNumber: true}
+	case "source":
+		return fieldValue{text: l.Source.String(), number: int64(l.Source), isNumber: true}
+	case "auto_unlock":
+		return fieldValue{text: strconv.FormatBool(l.AutoUnlock)}
+	}
+	return fieldValue{}
+}
+
+func checkLogField(field string) error {
+	if !slices.Contains(logFields, field) {
+		return fmt.Errorf("unknown field %s, expected one of %s", field, strings.Join(logFields, ", "))
+	}
+	return nil
+}
+
+// condition is a field compared to values: = and != match any of comma separated values (names or numbers
+// for enums), ~ and !~ match a regex, <, <=, > and >= compare dates, numbers or texts
+type condition struct {
+	field    string
+	operator string
+	values   []string
+	regex    *regexp.Regexp
+}
+
+func parseCondition(s string) (condition, error) {
+	m := conditionRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return condition{}, fmt.Errorf("invalid condition %q, expected <field><operator><value> with operator one of %s",
+			s, strings.Join(conditionOperators, " "))
+	}
+	c := condition{field: m[1], operator: m[2]}
+	if err := checkLogField(c.field); err != nil {
+		return c, err
+	}
+	switch c.operator {
+	case "~", "!~":
+		re, err := regexp.Compile(m[3])
+		if err != nil {
+			return c, fmt.Errorf("invalid regex in condition %q: %w", s, err)
+		}
+		c.regex = re
+	case "=", "!=":
+		c.values = filterValues(strings.Split(m[3], ","))
+	default:
+		c.values = []string{strings.TrimSpace(m[3])}
+	}
+	return c, nil
+}
+
+func (c condition) Match(e *messaging.Event, now time.Time) (bool, error) {
+	v := logFieldValue(e, c.field)
+	switch c.operator {
+	case "~":
+		return c.regex.MatchString(v.text), nil
+	case "!~":
+		return !c.regex.MatchString(v.text), nil
+	case "=", "!=":
+		found := slices.ContainsFunc(c.values, v.equals)
+		return found == (c.operator == "="), nil
+	}
+
+	cmp, err := v.compare(c.values[0], now)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", c.field, err)
+	}
+	switch c.operator {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// logFilter selects logs client side, all its conditions having to match.
+// The name regex matches the log's name or its reservation's name.
+type logFilter struct {
+	conditions []condition
+	name       *regexp.Regexp
+	now        time.Time
+}
+
+func newLogFilterFromFlags(now time.Time) (logFilter, error) {
+	f := logFilter{now: now}
+	for _, field := range []string{FlagFilterAction, FlagFilterTrigger, FlagFilterState, FlagFilterSource} {
+		if values := filterValues(viper.GetStringSlice(field)); len(values) > 0 {
+			f.conditions = append(f.conditions, condition{field: field, operator: "=", values: values})
+		}
+	}
+	for _, s := range viper.GetStringSlice(FlagFilterWhere) {
+		c, err := parseCondition(s)
+		if err != nil {
+			return f, err
+		}
+		f.conditions = append(f.conditions, c)
+	}
+	if expr := viper.GetString(FlagFilterName); expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return f, fmt.Errorf("invalid --%s regex: %w", FlagFilterName, err)
+		}
+		f.name = re
+	}
+	return f, nil
+}
+
+func filterValues(values []string) []string {
+	var res []string
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (f logFilter) Match(e *messaging.Event) (bool, error) {
+	for _, c := range f.conditions {
+		if ok, err := c.Match(e, f.now); !ok || err != nil {
+			return false, err
+		}
+	}
+	return f.name == nil || f.name.MatchString(e.Log.Name) || f.name.MatchString(e.ReservationName), nil
+}
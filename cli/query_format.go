@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+)
+
+const (
+	FormatTable  = "table"
+	FormatCSV    = "csv"
+	FormatLogfmt = "logfmt"
+	FormatNDJSON = "ndjson"
+	// FormatJSON is kept as an alias of FormatNDJSON
+	FormatJSON = "json"
+)
+
+var queryFormats = []string{FormatTable, FormatCSV, FormatLogfmt, FormatNDJSON}
+
+// column is a named value of a printed row
+type column struct {
+	key   string
+	value any
+}
+
+// logColumns returns all the fields of a log as printed to stdout, enums being named
+func logColumns(e *messaging.Event) []column {
+	l := e.Log
+	reservationName := ""
+	if e.ReservationName != l.Name {
+		reservationName = e.ReservationName
+	}
+	return []column{
+		{"date", l.Date.Local()},
+		{"action", l.Action.String()},
+		{"trigger", l.Trigger.String()},
+		{"state", l.State.String()},
+		{"source", l.Source.String()},
+		{"name", l.Name},
+		{"reservation_name", reservationName},
+		{"auto_unlock", l.AutoUnlock},
+		{"device_type", l.DeviceType.String()},
+		{"account_user_id", l.AccountUserID},
+		{"auth_id", l.AuthID},
+		{"smartlock_id", l.SmartLockID},
+		{"id", l.ID},
+	}
+}
+
+// logTableColumns are the fields of a log shown in a table, the others being too wide
+func logTableColumns(e *messaging.Event) []column {
+	name := e.Log.Name
+	if e.ReservationName != "" && e.ReservationName != e.Log.Name {
+		name = fmt.Sprintf("%s (%s)", e.ReservationName, e.Log.Name)
+	}
+	return []column{
+		{"date", e.Log.Date.Local()},
+		{"action", e.Log.Action.String()},
+		{"trigger", e.Log.Trigger.String()},
+		{"state", e.Log.State.String()},
+		{"source", e.Log.Source.String()},
+		{"name", name},
+	}
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case string:
+		return val
+	}
+	return fmt.Sprint(v)
+}
+
+// logPrinter prints logs to a writer, a batch at a time so that it can follow new logs
+type logPrinter struct {
+	w      io.Writer
+	format string
+	// streaming prints tables with fixed columns widths, batches being printed as they come
+	streaming     bool
+	headerPrinted bool
+}
+
+func newLogPrinter(w io.Writer, format string, streaming bool) (*logPrinter, error) {
+	if format == FormatJSON {
+		format = FormatNDJSON
+	}
+	if !slices.Contains(queryFormats, format) {
+		return nil, fmt.Errorf("unknown format %s, expected one of %s", format, strings.Join(queryFormats, ", "))
+	}
+	return &logPrinter{w: w, format: format, streaming: streaming}, nil
+}
+
+// Print prints events, oldest first
+func (p *logPrinter) Print(events []*messaging.Event) error {
+	rows := make([][]column, 0, len(events))
+	for _, e := range events {
+		if p.format == FormatTable {
+			rows = append(rows, logTableColumns(e))
+		} else {
+			rows = append(rows, logColumns(e))
+		}
+	}
+	return p.printRows(rows)
+}
+
+// PrintGroups prints the number of logs by group
+func (p *logPrinter) PrintGroups(fields []string, groups []logGroup) error {
+	rows := make([][]column, 0, len(groups))
+	for _, g := range groups {
+		var row []column
+		for i, f := range fields {
+			row = append(row, column{f, g.keys[i]})
+		}
+		row = append(row, column{"count", g.count}, column{"first", g.first.Local()}, column{"last", g.last.Local()})
+		rows = append(rows, row)
+	}
+	return p.printRows(rows)
+}
+
+func (p *logPrinter) printRows(rows [][]column) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	switch p.format {
+	case FormatNDJSON:
+		return p.printNDJSON(rows)
+	case FormatCSV:
+		return p.printCSV(rows)
+	case FormatLogfmt:
+		return p.printLogfmt(rows)
+	}
+	if p.streaming {
+		return p.printFixedTable(rows)
+	}
+	return p.printTable(rows)
+}
+
+func (p *logPrinter) header(row []column) []string {
+	var res []string
+	for _, c := range row {
+		res = append(res, c.key)
+	}
+	return res
+}
+
+func upper(values []string) []string {
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		res = append(res, strings.ToUpper(v))
+	}
+	return res
+}
+
+func tableValue(v any) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.DateTime)
+	}
+	return formatValue(v)
+}
+
+func (p *logPrinter) printTable(rows [][]column) error {
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if !p.headerPrinted {
+		fmt.Fprintln(w, strings.Join(upper(p.header(rows[0])), "\t"))
+		p.headerPrinted = true
+	}
+	for _, row := range rows {
+		var values []string
+		for _, c := range row {
+			values = append(values, tableValue(c.value))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
+
+// printFixedTable uses fixed column widths, logs being printed as they come when following
+func (p *logPrinter) printFixedTable(rows [][]column) error {
+	widths := []int{19, 22, 10, 20, 12}
+	line := func(values []string) string {
+		var sb strings.Builder
+		for i, v := range values {
+			if i < len(widths) {
+				fmt.Fprintf(&sb, "%-*s  ", widths[i], v)
+			} else {
+				sb.WriteString(v)
+			}
+		}
+		return strings.TrimRight(sb.String(), " ")
+	}
+	if !p.headerPrinted {
+		if _, err := fmt.Fprintln(p.w, line(upper(p.header(rows[0])))); err != nil {
+			return err
+		}
+		p.headerPrinted = true
+	}
+	for _, row := range rows {
+		var values []string
+		for _, c := range row {
+			values = append(values, tableValue(c.value))
+		}
+		if _, err := fmt.Fprintln(p.w, line(values)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *logPrinter) printCSV(rows [][]column) error {
+	w := csv.NewWriter(p.w)
+	if !p.headerPrinted {
+		if err := w.Write(p.header(rows[0])); err != nil {
+			return err
+		}
+		p.headerPrinted = true
+	}
+	for _, row := range rows {
+		var values []string
+		for _, c := range row {
+			values = append(values, formatValue(c.value))
+		}
+		if err := w.Write(values); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (p *logPrinter) printLogfmt(rows [][]column) error {
+	for _, row := range rows {
+		var pairs []string
+		for _, c := range row {
+			v := formatValue(c.value)
+			if v == "" {
+				continue
+			}
+			pairs = append(pairs, c.key+"="+logfmtValue(v))
+		}
+		if _, err := fmt.Fprintln(p.w, strings.Join(pairs, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printNDJSON prints an object per line, keeping the columns' order
+func (p *logPrinter) printNDJSON(rows [][]column) error {
+	for _, row := range rows {
+		var sb strings.Builder
+		sb.WriteString("{")
+		for i, c := range row {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			key, _ := json.Marshal(c.key)
+			value, err := json.Marshal(c.value)
+			if err != nil {
+				return err
+			}
+			sb.Write(key)
+			sb.WriteString(":")
+			sb.Write(value)
+		}
+		sb.WriteString("}")
+		if _, err := fmt.Fprintln(p.w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+)
+
+// logGroup counts the logs sharing the same values of the grouped by fields
+type logGroup struct {
+	keys  []string
+	count int
+	first time.Time
+	last  time.Time
+}
+
+// groupLogs groups events by fields, the largest groups first
+func groupLogs(events []*messaging.Event, fields []string) []logGroup {
+	var groups []logGroup
+	index := make(map[string]int)
+	for _, e := range events {
+		var keys []string
+		for _, f := range fields {
+			keys = append(keys, logFieldValue(e, f).text)
+		}
+		id := strings.Join(keys, "\x00")
+		i, ok := index[id]
+		if !ok {
+			i = len(groups)
+			index[id] = i
+			groups = append(groups, logGroup{keys: keys, first: e.Log.Date, last: e.Log.Date})
+		}
+		g := &groups[i]
+		g.count++
+		if e.Log.Date.Before(g.first) {
+			g.first = e.Log.Date
+		}
+		if e.Log.Date.After(g.last) {
+			g.last = e.Log.Date
+		}
+	}
+
+	slices.SortStableFunc(groups, func(a, b logGroup) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return slices.Compare(a.keys, b.keys)
+	})
+	return groups
+}
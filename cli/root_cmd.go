@@ -51,14 +51,7 @@ var (
 				return fmt.Errorf("the following flag(s) are required: %s", strings.Join(requiredFlagsMissing, ", "))
 			}
 
-			if viper.GetString(PersistentFlagConfig) != "" {
-				viper.AddConfigPath(".")
-				viper.AddConfigPath("/")
-				viper.SetConfigType("yaml")
-				viper.SetConfigName(viper.GetString(PersistentFlagConfig))
-			}
-
-			if err := config.LoadConfig(viper.GetViper()); err != nil {
+			if err := loadConfig(); err != nil {
 				return err
 			}
 
@@ -75,6 +68,12 @@ func init() {
 	RootCmd.AddCommand(VersionCmd)
 	RootCmd.AddCommand(QueryCmd)
 	RootCmd.AddCommand(ServerCmd)
+	RootCmd.AddCommand(OutboxCmd)
+	RootCmd.AddCommand(ConfigCmd)
+	RootCmd.AddCommand(SecretCmd)
+	RootCmd.AddCommand(StatusCmd)
+	RootCmd.AddCommand(DeviceCmd)
+	RootCmd.AddCommand(HygieneCmd)
 
 	viper.AutomaticEnv()
 	viper.SetConfigName("config")
@@ -83,6 +82,16 @@ func init() {
 	_ = viper.BindPFlags(RootCmd.PersistentFlags())
 }
 
+func loadConfig() error {
+	if viper.GetString(PersistentFlagConfig) != "" {
+		viper.AddConfigPath(".")
+		viper.AddConfigPath("/")
+		viper.SetConfigType("yaml")
+		viper.SetConfigName(viper.GetString(PersistentFlagConfig))
+	}
+	return config.LoadConfig(viper.GetViper())
+}
+
 func initSenders() error {
 	for _, v := range viper.GetStringSlice(PersistentFlagSender) {
 		s, err := config.GetSender(v)
@@ -101,7 +110,7 @@ func initSenders() error {
 
 func run(_ *cobra.Command, _ []string) error {
 	log.Debug().
-		Msg(fmt.Sprintf("%+v", config))
+		Msg(fmt.Sprintf("%+v", config.Redacted()))
 	log.Error().
 		Err(fmt.Errorf("please use one of the supported sub-command")).
 		Send()
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nmaupu/nuki-logger/secret"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagSecretOutput = "output"
+)
+
+var (
+	SecretCmd = &cobra.Command{
+		Use:   "secret",
+		Short: "Manage the secrets referenced from the configuration with enc:<file>",
+		// Overriding the root's checks, neither the configuration nor senders are needed here
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	SecretKeygenCmd = &cobra.Command{
+		Use:   "keygen",
+		Short: fmt.Sprintf("Print a new key to set in %s", secret.EnvKey),
+		RunE:  SecretKeygenRun,
+	}
+	SecretEncryptCmd = &cobra.Command{
+		Use:   "encrypt",
+		Short: fmt.Sprintf("Encrypt a secret read from stdin with the key from %s", secret.EnvKey),
+		RunE:  SecretEncryptRun,
+	}
+)
+
+func init() {
+	SecretEncryptCmd.Flags().StringP(FlagSecretOutput, "o", "", "File to write the encrypted secret to, stdout when empty")
+	_ = viper.BindPFlags(SecretEncryptCmd.Flags())
+
+	SecretCmd.AddCommand(SecretKeygenCmd)
+	SecretCmd.AddCommand(SecretEncryptCmd)
+}
+
+func SecretKeygenRun(_ *cobra.Command, _ []string) error {
+	key, err := secret.GenerateKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
+
+func SecretEncryptRun(_ *cobra.Command, _ []string) error {
+	key, err := secret.LoadKey()
+	if err != nil {
+		return err
+	}
+	plaintext, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	// Dropping the new line added when piping from echo or typing the secret
+	plaintext = []byte(strings.TrimRight(string(plaintext), "\r\n"))
+	if len(plaintext) == 0 {
+		return fmt.Errorf("nothing to encrypt, please write the secret to stdin")
+	}
+
+	encrypted, err := secret.Encrypt(key, plaintext)
+	if err != nil {
+		return err
+	}
+	output := viper.GetString(FlagSecretOutput)
+	if output == "" {
+		_, err = os.Stdout.Write(encrypted)
+		return err
+	}
+	return os.WriteFile(output, encrypted, 0o600)
+}
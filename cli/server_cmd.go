@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,9 +12,21 @@ import (
 	"time"
 
 	"github.com/mymmrac/telego"
+	"github.com/nmaupu/nuki-logger/api"
 	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/calendar"
+	"github.com/nmaupu/nuki-logger/dashboard"
+	"github.com/nmaupu/nuki-logger/drift"
+	"github.com/nmaupu/nuki-logger/eventbus"
+	"github.com/nmaupu/nuki-logger/httpserver"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/lifecycle"
 	"github.com/nmaupu/nuki-logger/messaging"
 	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/nmaupu/nuki-logger/outbox"
+	"github.com/nmaupu/nuki-logger/polling"
+	"github.com/nmaupu/nuki-logger/stream"
 	"github.com/nmaupu/nuki-logger/telegrambot"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -21,8 +34,7 @@ import (
 )
 
 const (
-	FlagServerInterval     = "interval"
-	SmartlockCheckInterval = time.Hour * 2
+	FlagServerInterval = "interval"
 )
 
 var (
@@ -42,16 +54,27 @@ func init() {
 }
 
 func RunServer(_ *cobra.Command, _ []string) error {
+	if err := i18n.Validate(); err != nil {
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration, run config validate for details:\n%w", err)
+	}
+
 	log.Debug().Dur(FlagServerInterval, viper.GetDuration(FlagServerInterval)).Send()
-	tickerLogs := time.NewTicker(viper.GetDuration(FlagServerInterval))
-	// NOTE: Nuki logs API bug: sometimes, logs endpoint does not return the last logs entries
-	// as a result, there is a diff and logs are resent over to senders...
-	// To avoid that, we store the last log date at each call and sent
-	// logs over only when the last date received is AFTER this var
-	var lastLogDate time.Time
-	tickerSmartlock := time.NewTicker(SmartlockCheckInterval)
+	// Logs are polled faster around check-ins and check-outs, and slower when the property is vacant
+	scheduler := polling.NewScheduler(config.Polling, viper.GetDuration(FlagServerInterval), config.ReservationsReader)
+	timerLogs := time.NewTimer(viper.GetDuration(FlagServerInterval))
+	tickerSmartlock := time.NewTicker(config.Polling.GetSmartlockInterval())
 	interruptSigChan := make(chan os.Signal, 1)
 	signal.Notify(interruptSigChan, syscall.SIGINT, syscall.SIGTERM)
+	reloadSigChan := make(chan os.Signal, 1)
+	signal.Notify(reloadSigChan, syscall.SIGHUP)
+
+	// The configuration is applied again when its file changes or on SIGHUP, senders being replaced in place
+	reloader, reloadableSenders := newConfigReloader(config, senders)
+	reloader.scheduler = scheduler
+	reloader.tickerSmartlock = tickerSmartlock
 
 	// cache init
 	var memcache cache.Cache
@@ -75,7 +98,7 @@ func RunServer(_ *cobra.Command, _ []string) error {
 		if len(cacheLogs) == 0 {
 			// No cache, creating one
 			log.Info().Msg("No cache yet, creating one")
-			cacheLogs, err := config.LogsReader.Execute()
+			cacheLogs, err = config.LogsReader.Execute()
 			if err != nil {
 				return err
 			}
@@ -85,6 +108,48 @@ func RunServer(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	// New logs are found by their ids, the Nuki logs API sometimes not returning the last logs entries
+	// or returning them late, and more logs than the page limit possibly occurring between polls
+	logsTracker := nukiapi.NewLogsTracker(config.LogsReader, cacheLogs)
+
+	// Smartlock checks also compare its configuration with the previous check and the desired state
+	driftDetector := drift.NewDetector(config.Drift, memcache, nukiapi.SmartlockConfigUpdater{
+		APICaller:   config.SmartlockReader.APICaller,
+		SmartlockID: config.SmartlockID,
+	})
+	reloader.driftDetector = driftDetector
+	// and record its firmware, hardware and subscription changes
+	lifecycleTracker := lifecycle.NewTracker(config.Lifecycle, memcache)
+	reloader.lifecycleTracker = lifecycleTracker
+
+	// Events are fanned out to senders and services, each of them handling them from its own queue
+	bus := eventbus.New()
+	// With the outbox, events are written to disk before being delivered to senders, failed deliveries being retried
+	var box *outbox.Outbox
+	if config.Outbox.IsEnabled() {
+		var err error
+		if box, err = outbox.New(config.Outbox, reloadableSenders); err != nil {
+			return err
+		}
+		box.Start()
+	} else {
+		log.Warn().Msg("no outbox directory configured, events failing to be sent to senders are lost")
+		for _, sender := range reloadableSenders {
+			bus.SubscribeSender(sender, eventbus.DefaultQueueSize)
+		}
+	}
+
+	// The http server is shared by all services (health check, telegram webhook, etc.)
+	var httpServer *httpserver.Server
+	if config.HealthCheckPort > 0 {
+		httpServer = httpserver.NewServer(config.HealthCheckPort, config.HTTPServer.TLSCertFile, config.HTTPServer.TLSKeyFile)
+		httpServer.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "ok")
+		})
+	}
+
+	var nukiBot telegrambot.NukiBot
 	if config.TelegramBot.Enabled {
 		tgSenderInterface, err := config.GetSender(config.TelegramBot.SenderName)
 		if err != nil {
@@ -93,7 +158,12 @@ func RunServer(_ *cobra.Command, _ []string) error {
 		tgSender := tgSenderInterface.(*messaging.TelegramSender)
 		defCheckIn := config.TelegramBot.DefaultCheckIn
 		defCheckOut := config.TelegramBot.DefaultCheckOut
-		nukiBot, err := telegrambot.NewNukiBot(tgSender,
+		var sessionsCache cache.Cache
+		if config.TelegramBot.PersistSessions {
+			sessionsCache = memcache
+		}
+		sessions := telegrambot.NewSessionManager(config.TelegramBot.SessionTimeout, sessionsCache)
+		nukiBot, err = telegrambot.NewNukiBot(tgSender,
 			config.LogsReader,
 			config.SmartlockReader,
 			config.ReservationsReader,
@@ -101,6 +171,8 @@ func RunServer(_ *cobra.Command, _ []string) error {
 			time.Time(defCheckIn),
 			time.Time(defCheckOut),
 			memcache,
+			bus,
+			sessions,
 		)
 		if err != nil {
 			return err
@@ -110,12 +182,34 @@ func RunServer(_ *cobra.Command, _ []string) error {
 			log.Info().
 				Ints64("chat_ids", config.TelegramBot.RestrictToChatIDs).
 				Msg("Restricting bot access")
-			nukiBot.AddFilter(func(update telego.Update) bool {
-				if update.Message == nil || !telegrambot.IsPrivateMessage(update) {
-					return true
-				}
-				return slices.Contains(config.TelegramBot.RestrictToChatIDs, update.Message.From.ID)
-			})
+		}
+		// Always filtering as the restricted chats can be set by a reload
+		restrictedChatIDs := reloader.restrictedChatIDs
+		nukiBot.AddFilter(func(update telego.Update) bool {
+			if update.Message == nil || !telegrambot.IsPrivateMessage(update) || restrictedChatIDs.IsEmpty() {
+				return true
+			}
+			return restrictedChatIDs.Contains(update.Message.From.ID) ||
+				nukiBot.IsGuestAllowed(update)
+		})
+		reloader.nukiBot = nukiBot
+
+		if !config.TelegramBot.Roles.IsEmpty() {
+			log.Info().
+				Interface("roles", config.TelegramBot.Roles).
+				Msg("Restricting bot commands using roles")
+		}
+		nukiBot.SetRoles(config.TelegramBot.Roles)
+		nukiBot.SetGuestsConfig(config.TelegramBot.Guests)
+		nukiBot.SetBookingsConfig(config.Bookings)
+		nukiBot.SetLifecycleTracker(lifecycleTracker)
+		nukiBot.SetHygieneConfig(config.Hygiene)
+
+		if config.TelegramBot.Webhook.Enabled {
+			if httpServer == nil {
+				return fmt.Errorf("telegram webhook needs the http server, please set health_check_port")
+			}
+			nukiBot.UseWebhook(config.TelegramBot.Webhook, httpServer.Mux())
 		}
 
 		if err := nukiBot.Start(); err != nil {
@@ -123,71 +217,183 @@ func RunServer(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	wg := sync.WaitGroup{}
-	if config.HealthCheckPort > 0 {
-		go func() {
-			log.Info().Int("port", config.HealthCheckPort).Msg("Starting health check service")
-			http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-				fmt.Fprint(w, "ok")
-			})
-			if err := http.ListenAndServe(fmt.Sprintf(":%d", config.HealthCheckPort), nil); err != nil {
-				log.Panic().Err(err).Int("port", config.HealthCheckPort).Msg("Unable to start health check service")
+	if config.Calendar.Enabled {
+		if httpServer == nil {
+			return fmt.Errorf("calendar feed needs the http server, please set health_check_port")
+		}
+		var pendingModifications func() []model.ReservationPendingModification
+		if nukiBot != nil {
+			pendingModifications = nukiBot.GetAllPendingModifications
+		}
+		feed, err := calendar.NewFeed(config.Calendar, config.ReservationsReader, config.LogsReader, pendingModifications)
+		if err != nil {
+			return err
+		}
+		log.Info().
+			Str("path", config.Calendar.GetPath()).
+			Msg("Publishing calendar feed")
+		httpServer.Handle(config.Calendar.GetPath(), feed)
+	}
+
+	var apiServer *api.Server
+	if config.API.Enabled {
+		if httpServer == nil {
+			return fmt.Errorf("api needs the http server, please set health_check_port")
+		}
+		var err error
+		apiServer, err = api.NewServer(config.API,
+			config.LogsReader,
+			config.SmartlockReader,
+			config.ReservationsReader,
+			config.SmartlockAuthReader,
+			time.Time(config.TelegramBot.DefaultCheckIn),
+			time.Time(config.TelegramBot.DefaultCheckOut),
+		)
+		if err != nil {
+			return err
+		}
+		if nukiBot != nil {
+			apiServer.SetPendingModificationRoutine(nukiBot.PendingModificationRoutine())
+		}
+		apiServer.SetStoredLogs(cacheLogs)
+		reloader.apiServer = apiServer
+		log.Info().
+			Str("prefix", config.API.GetPrefix()).
+			Msg("Serving REST API")
+		apiServer.Register(httpServer.Mux())
+	}
+
+	var dashboardServer *dashboard.Dashboard
+	if config.Dashboard.Enabled {
+		if httpServer == nil {
+			return fmt.Errorf("dashboard needs the http server, please set health_check_port")
+		}
+		// The telegram login widget is verified using the bot's token
+		var botToken string
+		if config.TelegramBot.Enabled {
+			if s, err := config.GetSender(config.TelegramBot.SenderName); err == nil {
+				botToken = s.(*messaging.TelegramSender).Token
 			}
-		}()
+		}
+		var err error
+		dashboardServer, err = dashboard.New(config.Dashboard,
+			httpServer.IsTLS(),
+			botToken,
+			config.SmartlockReader,
+			config.ReservationsReader,
+			config.SmartlockAuthReader,
+			time.Time(config.TelegramBot.DefaultCheckIn),
+			time.Time(config.TelegramBot.DefaultCheckOut),
+			memcache,
+		)
+		if err != nil {
+			return err
+		}
+		if nukiBot != nil {
+			dashboardServer.SetPendingModificationRoutine(nukiBot.PendingModificationRoutine())
+		}
+		dashboardServer.SetStoredLogs(cacheLogs)
+		reloader.dashboard = dashboardServer
+		bus.Subscribe("dashboard", eventbus.DefaultQueueSize, func(e eventbus.Event) {
+			dashboardServer.RecordBattery(*e.Smartlock)
+		}, eventbus.KindSmartlock)
+		log.Info().
+			Str("path", config.Dashboard.GetPath()).
+			Msg("Serving web dashboard")
+		dashboardServer.Register(httpServer.Mux())
+	}
+
+	var eventStream *stream.Stream
+	if config.Stream.Enabled {
+		if httpServer == nil {
+			return fmt.Errorf("event stream needs the http server, please set health_check_port")
+		}
+		var err error
+		if eventStream, err = stream.New(config.Stream); err != nil {
+			return err
+		}
+		bus.Subscribe("stream", eventbus.DefaultQueueSize, func(e eventbus.Event) {
+			if e.Kind == eventbus.KindModificationDone {
+				eventStream.Publish([]*messaging.Event{{Modification: e.Modification}})
+				return
+			}
+			eventStream.Publish(e.Messages)
+		}, eventbus.KindLog, eventbus.KindAlert, eventbus.KindModificationDone)
+		log.Info().
+			Str("path", config.Stream.GetPath()).
+			Msg("Streaming events")
+		httpServer.Handle(config.Stream.GetPath(), eventStream)
+	}
+
+	if config.Bookings.IsEnabled() && nukiBot == nil {
+		log.Warn().Msg("Bookings import needs the telegram bot to be enabled, ignoring")
+	}
+	if config.Hygiene.Enabled && nukiBot == nil {
+		log.Warn().Msg("Authorizations review needs the telegram bot to be enabled, ignoring")
+	}
+
+	alert := func(resp *model.SmartlockResponse, messages []*messaging.Event) {
+		if box != nil {
+			if err := box.Enqueue(string(eventbus.KindAlert), messages); err != nil {
+				log.Error().Err(err).Msg("Unable to write alert to the outbox")
+			}
+		}
+		bus.Publish(eventbus.Event{
+			Kind:      eventbus.KindAlert,
+			Smartlock: resp,
+			Messages:  messages,
+		})
+	}
+
+	checkSmartlock := func() {
+		log.Info().Msg("Checking smartlock for issues")
+		resp, err := config.SmartlockReader.Execute()
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to check smartlock")
+			return
+		}
+		bus.Publish(eventbus.Event{Kind: eventbus.KindSmartlock, Smartlock: resp})
+		if report, err := driftDetector.Check(*resp); err != nil {
+			log.Error().Err(err).Msg("Unable to check smartlock configuration")
+		} else if report != nil {
+			alert(resp, []*messaging.Event{{Drift: report}})
+		}
+		for _, e := range lifecycleTracker.Check(*resp, time.Now()) {
+			alert(resp, []*messaging.Event{{Device: &e}})
+		}
+		if resp.State.BatteryCritical ||
+			resp.State.KeypadBatteryCritical ||
+			resp.State.DoorsensorBatteryCritical ||
+			resp.State.BatteryCharge <= 30 {
+			alert(resp, []*messaging.Event{{Smartlock: *resp}})
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	if httpServer != nil {
+		httpServer.Start()
 	}
+	reloadChan := make(chan bool, 1)
+	reloader.watch(reloadChan)
 	wg.Add(1)
 	go func() {
 		for {
 			select {
 			case <-tickerSmartlock.C:
-				log.Info().Msg("Checking smartlock for issues")
-				resp, err := config.SmartlockReader.Execute()
-				if err != nil {
-					log.Error().Err(err).Msg("Unable to check smartlock")
-				}
-				if resp.State.BatteryCritical ||
-					resp.State.KeypadBatteryCritical ||
-					resp.State.DoorsensorBatteryCritical ||
-					resp.State.BatteryCharge <= 30 {
-					for _, sender := range senders {
-						e := &messaging.Event{Smartlock: *resp}
-						if err := sender.Send([]*messaging.Event{e}); err != nil {
-							log.Error().
-								Err(err).
-								Str("sender", sender.GetName()).
-								Msg("Unable to send message to sender")
-						}
-					}
-				}
+				checkSmartlock()
 
-			case <-tickerLogs.C:
+			case <-timerLogs.C:
 				log.Info().Msg("Getting logs from api")
 
-				newResponses, err := config.LogsReader.Execute()
+				diff, err := logsTracker.Poll()
 				if err != nil {
 					log.Error().Err(err).Msg("An error occurred getting logs from API")
 				}
+				scheduler.Report(err)
+				next := scheduler.Next(time.Now())
+				log.Debug().Dur("next_poll", next).Send()
+				timerLogs.Reset(next)
 
-				if len(newResponses) > 0 {
-					// init date with the last one if needed
-					newRespDate := newResponses[0].Date
-					if lastLogDate.IsZero() {
-						lastLogDate = newRespDate
-					}
-					if newRespDate.Before(lastLogDate) {
-						// Nuki api bug, do not send anything
-						log.Warn().
-							Time("last_log", lastLogDate).
-							Time("api_log", newRespDate).
-							Msg("Nuki logs api bug: missing last logs entries, ignoring.")
-
-						continue
-					}
-					lastLogDate = newRespDate
-				}
-
-				diff := model.Diff(newResponses, cacheLogs)
 				var events []*messaging.Event
 				if len(diff) > 0 {
 					for _, d := range diff {
@@ -209,27 +415,62 @@ func RunServer(_ *cobra.Command, _ []string) error {
 						})
 					}
 
-					// log those new messages
-					for _, sender := range senders {
-						if err := sender.Send(events); err != nil {
-							log.Error().
-								Err(err).
-								Str("sender", sender.GetName()).
-								Msg("Unable to send message")
+					if box != nil {
+						// Logs are not committed until written, the same diff being computed again next time
+						if err := box.Enqueue(string(eventbus.KindLog), events); err != nil {
+							log.Error().Err(err).Msg("Unable to write logs to the outbox, retrying next time")
+							continue
 						}
 					}
+					bus.Publish(eventbus.Event{Kind: eventbus.KindLog, Messages: events})
 
-					cacheLogs = newResponses
+					logsTracker.Commit(diff)
+					cacheLogs = logsTracker.Logs()
+					if apiServer != nil {
+						apiServer.SetStoredLogs(cacheLogs)
+					}
+					if dashboardServer != nil {
+						dashboardServer.SetStoredLogs(cacheLogs)
+					}
 					if cacheEnabled {
 						if err := memcacheLogs.Save(cacheLogs); err != nil {
 							log.Error().Err(err).Msg("Unable to save cache file to disk")
 						}
 					}
+					// Recording the new firmware without waiting for the next smartlock check
+					if slices.ContainsFunc(diff, func(l model.NukiSmartlockLogResponse) bool {
+						return l.Action == model.NukiActionFirmwareUpdate
+					}) {
+						checkSmartlock()
+					}
 				}
+			case <-reloadSigChan:
+				reloader.Reload()
+			case <-reloadChan:
+				reloader.Reload()
 			case <-interruptSigChan:
 				log.Info().Msg("Stopping.")
-				tickerLogs.Stop()
+				timerLogs.Stop()
 				tickerSmartlock.Stop()
+				if nukiBot != nil {
+					if err := nukiBot.Stop(); err != nil {
+						log.Error().Err(err).Msg("Unable to stop telegram bot")
+					}
+				}
+				bus.Close()
+				if box != nil {
+					box.Stop()
+				}
+				if eventStream != nil {
+					eventStream.Close()
+				}
+				if httpServer != nil {
+					ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+					if err := httpServer.Shutdown(ctx); err != nil {
+						log.Error().Err(err).Msg("Unable to stop http server")
+					}
+					cancel()
+				}
 				wg.Done()
 			}
 		}
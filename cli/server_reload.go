@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/fsnotify/fsnotify"
+	"github.com/nmaupu/nuki-logger/api"
+	"github.com/nmaupu/nuki-logger/dashboard"
+	"github.com/nmaupu/nuki-logger/drift"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/lifecycle"
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/nmaupu/nuki-logger/polling"
+	"github.com/nmaupu/nuki-logger/telegrambot"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// reloadDebounce groups the file events of a single save, editors often writing a file several times
+	reloadDebounce = time.Second
+	// reloadMaxDiffLines is the maximum number of changes listed in the reload notification
+	reloadMaxDiffLines = 40
+)
+
+var (
+	// reloadableKeys are the settings applied without restarting, any key under them being reloadable too
+	reloadableKeys = []string{
+		"nuki_api_token",
+		"senders",
+		"telegram_bot.default_check_in",
+		"telegram_bot.default_check_out",
+		"telegram_bot.restrict_private_chat_ids",
+		"telegram_bot.roles",
+		"telegram_bot.guests",
+		"polling",
+		"drift",
+		"lifecycle",
+		"hygiene",
+	}
+	// restartKeys are exceptions to reloadableKeys
+	restartKeys = []string{
+		"telegram_bot.guests.enabled",
+		"hygiene.enabled",
+	}
+	// secretKeys are the suffixes of keys whose values are never displayed
+	secretKeys = []string{"token", "password", "secret"}
+)
+
+// chatIDs is a list of chat ids which can be replaced while in use
+type chatIDs struct {
+	mutex sync.RWMutex
+	ids   []int64
+}
+
+func (c *chatIDs) Set(ids []int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ids = ids
+}
+
+// IsEmpty returns true if no chat id is set
+func (c *chatIDs) IsEmpty() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.ids) == 0
+}
+
+func (c *chatIDs) Contains(id int64) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return slices.Contains(c.ids, id)
+}
+
+// configReloader applies a new configuration file to the running server
+type configReloader struct {
+	file              string
+	token             *nukiapi.Token
+	settings          map[string]string
+	current           Config
+	senders           map[string]*messaging.ReloadableSender
+	nukiBot           telegrambot.NukiBot
+	apiServer         *api.Server
+	dashboard         *dashboard.Dashboard
+	scheduler         *polling.Scheduler
+	driftDetector     *drift.Detector
+	lifecycleTracker  *lifecycle.Tracker
+	tickerSmartlock   *time.Ticker
+	restrictedChatIDs *chatIDs
+}
+
+// newConfigReloader creates a reloader from the running configuration, wrapping senders so that they can be replaced
+func newConfigReloader(running Config, senders []messaging.Sender) (*configReloader, []messaging.Sender) {
+	r := &configReloader{
+		file:              viper.ConfigFileUsed(),
+		token:             running.LogsReader.Token,
+		current:           running,
+		senders:           make(map[string]*messaging.ReloadableSender),
+		restrictedChatIDs: &chatIDs{ids: running.TelegramBot.RestrictToChatIDs},
+	}
+	if vi, err := r.read(); err != nil {
+		log.Error().Err(err).Msg("Unable to read configuration file, every setting will be reported as changed on reload")
+	} else {
+		r.settings = flattenSettings(vi.AllSettings())
+	}
+
+	var res []messaging.Sender
+	for _, s := range senders {
+		rs := messaging.NewReloadableSender(s)
+		r.senders[s.GetName()] = rs
+		res = append(res, rs)
+	}
+	return r, res
+}
+
+// watch sends to reload each time the configuration file changes
+func (r *configReloader) watch(reload chan<- bool) {
+	trigger := func() {
+		select {
+		case reload <- true:
+		default: // a reload is already pending
+		}
+	}
+
+	var mutex sync.Mutex
+	var timer *time.Timer
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		log.Debug().Str("file", e.Name).Str("op", e.Op.String()).Msg("Configuration file changed")
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(reloadDebounce, trigger)
+	})
+	viper.WatchConfig()
+}
+
+// read reads the configuration file in a fresh viper, leaving the running configuration untouched
+func (r *configReloader) read() (*viper.Viper, error) {
+	vi := viper.New()
+	vi.AutomaticEnv()
+	vi.SetConfigType("yaml")
+	vi.SetConfigFile(r.file)
+	if err := vi.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return vi, nil
+}
+
+// Reload loads, validates and applies the configuration file, the running configuration being kept on error.
+// It must be called from the server's loop as it changes the polling scheduler.
+func (r *configReloader) Reload() {
+	log.Info().Str("file", r.file).Msg("Reloading configuration")
+
+	vi, newConfig, err := r.load()
+	if err != nil {
+		log.Error().Err(err).Str("file", r.file).Msg("Configuration rejected, keeping the running one")
+		r.notify("config.reload_rejected", emoji.CrossMark.String(), r.file, err)
+		return
+	}
+
+	settings := flattenSettings(vi.AllSettings())
+	changes, restart := diffSettings(r.settings, settings)
+	if r.current.TelegramBot.Enabled && r.botSenderChanged(newConfig) {
+		restart = append(restart, "telegram_bot.sender_name")
+	}
+	restart = append(restart, r.sendersChanged(newConfig)...)
+
+	r.apply(newConfig)
+	r.settings = settings
+	r.current = newConfig
+
+	log.Info().
+		Strs("changes", changes).
+		Strs("restart_needed", restart).
+		Msg("Configuration reloaded")
+	if len(changes) == 0 {
+		r.notify("config.reload_unchanged", emoji.CheckMarkButton.String(), r.file)
+		return
+	}
+	if len(changes) > reloadMaxDiffLines {
+		changes = append(changes[:reloadMaxDiffLines], fmt.Sprintf("… +%d", len(changes)-reloadMaxDiffLines))
+	}
+	summary := strings.Join(changes, "\n")
+	if len(restart) > 0 {
+		summary += "\n\n" + r.translate("config.reload_restart", strings.Join(restart, ", "))
+	}
+	r.notify("config.reloaded", emoji.CheckMarkButton.String(), r.file, summary)
+}
+
+// load reads and validates the configuration file
+func (r *configReloader) load() (*viper.Viper, Config, error) {
+	vi, err := r.read()
+	if err != nil {
+		return nil, Config{}, err
+	}
+	newConfig := Config{}
+	if err := newConfig.LoadConfig(vi); err != nil {
+		return nil, Config{}, err
+	}
+	if err := newConfig.Validate(); err != nil {
+		return nil, Config{}, err
+	}
+	return vi, newConfig, nil
+}
+
+// apply applies the reloadable settings, the others being taken into account on restart
+func (r *configReloader) apply(c Config) {
+	// Readers are shared by all services, only their token can be changed
+	r.token.Set(c.NukiAPIToken)
+
+	// Senders added or removed are taken into account on restart, removed ones being kept until then
+	for name, rs := range r.senders {
+		s, err := c.GetSender(name)
+		if err != nil {
+			continue
+		}
+		// The bot keeps using the running client, sharing it so that per chat rate limits still apply to both
+		if tg, ok := s.(*messaging.TelegramSender); ok {
+			if old, err := r.current.GetSender(name); err == nil {
+				if oldTg, ok := old.(*messaging.TelegramSender); ok {
+					tg.ShareClient(oldTg)
+				}
+			}
+		}
+		rs.Swap(s)
+	}
+
+	defCheckIn := time.Time(c.TelegramBot.DefaultCheckIn)
+	defCheckOut := time.Time(c.TelegramBot.DefaultCheckOut)
+	if r.nukiBot != nil {
+		r.nukiBot.Reload(telegrambot.Settings{
+			DefaultCheckIn:  defCheckIn,
+			DefaultCheckOut: defCheckOut,
+			Roles:           c.TelegramBot.Roles,
+			Guests:          c.TelegramBot.Guests,
+		})
+	}
+	r.restrictedChatIDs.Set(c.TelegramBot.RestrictToChatIDs)
+	if r.apiServer != nil {
+		r.apiServer.SetDefaultCheckTimes(defCheckIn, defCheckOut)
+	}
+	if r.dashboard != nil {
+		r.dashboard.SetDefaultCheckTimes(defCheckIn, defCheckOut)
+	}
+
+	if r.scheduler != nil {
+		r.scheduler.SetConfig(c.Polling)
+	}
+	if r.driftDetector != nil {
+		r.driftDetector.SetConfig(c.Drift)
+	}
+	if r.lifecycleTracker != nil {
+		r.lifecycleTracker.SetConfig(c.Lifecycle)
+	}
+	if r.nukiBot != nil {
+		r.nukiBot.SetHygieneConfig(c.Hygiene)
+	}
+	if r.tickerSmartlock != nil && c.Polling.GetSmartlockInterval() != r.current.Polling.GetSmartlockInterval() {
+		r.tickerSmartlock.Reset(c.Polling.GetSmartlockInterval())
+	}
+}
+
+// botSenderChanged returns true if the bot's Telegram client would need to be recreated
+func (r *configReloader) botSenderChanged(c Config) bool {
+	old, err := r.current.GetSender(r.current.TelegramBot.SenderName)
+	if err != nil {
+		return true
+	}
+	s, err := c.GetSender(c.TelegramBot.SenderName)
+	if err != nil {
+		return true
+	}
+	oldTg, _ := old.(*messaging.TelegramSender)
+	tg, ok := s.(*messaging.TelegramSender)
+	if !ok || oldTg == nil {
+		return true
+	}
+	return oldTg.Token != tg.Token || oldTg.APIServer != tg.APIServer || oldTg.ChatID != tg.ChatID
+}
+
+// sendersChanged returns the senders added or removed, which are only taken into account on restart
+func (r *configReloader) sendersChanged(c Config) []string {
+	var res []string
+	for _, s := range c.Senders {
+		if _, ok := r.senders[s.Name]; !ok {
+			res = append(res, "senders: +"+s.Name)
+		}
+	}
+	for _, name := range sortedSenderNames(r.senders) {
+		if _, err := c.GetSender(name); err != nil {
+			res = append(res, "senders: -"+name)
+		}
+	}
+	return res
+}
+
+func sortedSenderNames(senders map[string]*messaging.ReloadableSender) []string {
+	names := make([]string, 0, len(senders))
+	for name := range senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// notify reports to the bot's chat, reloads being only logged when the bot is disabled
+func (r *configReloader) notify(key string, args ...any) {
+	if r.nukiBot != nil {
+		r.nukiBot.Notify(key, args...)
+	}
+}
+
+func (r *configReloader) translate(key string, args ...any) string {
+	lang := ""
+	if s, err := r.current.GetSender(r.current.TelegramBot.SenderName); err == nil {
+		lang = s.GetLanguage()
+	}
+	return i18n.T(lang, key, args...)
+}
+
+// flattenSettings flattens viper's settings to dotted keys, list items being indexed
+func flattenSettings(settings map[string]any) map[string]string {
+	res := make(map[string]string)
+	var flatten func(prefix string, v any)
+	flatten = func(prefix string, v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, sub := range val {
+				key := k
+				if prefix != "" {
+					key = prefix + "." + k
+				}
+				flatten(key, sub)
+			}
+		case []any:
+			for i, sub := range val {
+				flatten(fmt.Sprintf("%s[%d]", prefix, i), sub)
+			}
+		default:
+			res[prefix] = fmt.Sprint(val)
+		}
+	}
+	flatten("", settings)
+	return res
+}
+
+// diffSettings returns the changes between two flattened settings, and the keys needing a restart
+func diffSettings(old, current map[string]string) (changes, restart []string) {
+	keys := make(map[string]bool)
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range current {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		oldVal, inOld := old[k]
+		newVal, inNew := current[k]
+		if inOld && inNew && oldVal == newVal {
+			continue
+		}
+		if isSecretKey(k) {
+			oldVal, newVal = "***", "***"
+		}
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("+ %s: %s", k, newVal))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("- %s: %s", k, oldVal))
+		default:
+			changes = append(changes, fmt.Sprintf("~ %s: %s → %s", k, oldVal, newVal))
+		}
+		if !isReloadableKey(k) {
+			restart = append(restart, k)
+		}
+	}
+	return changes, restart
+}
+
+func hasKeyPrefix(key string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if key == p || strings.HasPrefix(key, p+".") || strings.HasPrefix(key, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func isReloadableKey(key string) bool {
+	return hasKeyPrefix(key, reloadableKeys) && !hasKeyPrefix(key, restartKeys)
+}
+
+func isSecretKey(key string) bool {
+	last := key[strings.LastIndex(key, ".")+1:]
+	for _, s := range secretKeys {
+		if strings.HasSuffix(last, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagStatusJSON     = "status-json"
+	FlagStatusLanguage = "lang"
+)
+
+var (
+	StatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Print the full status of the smartlock",
+		// Printing to stdout, senders are not needed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if viper.GetString(PersistentFlagConfig) == "" {
+				return fmt.Errorf("the following flag(s) are required: %s", PersistentFlagConfig)
+			}
+			return loadConfig()
+		},
+		RunE: StatusRun,
+	}
+)
+
+func init() {
+	StatusCmd.Flags().Bool(FlagJson, false, "Output the status in json")
+	StatusCmd.Flags().String(FlagStatusLanguage, i18n.DefaultLanguage, fmt.Sprintf("Language of the status %v", i18n.Languages()))
+	_ = viper.BindPFlag(FlagStatusJSON, StatusCmd.Flags().Lookup(FlagJson))
+	_ = viper.BindPFlag(FlagStatusLanguage, StatusCmd.Flags().Lookup(FlagStatusLanguage))
+}
+
+func StatusRun(_ *cobra.Command, _ []string) error {
+	res, err := config.SmartlockReader.Execute()
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool(FlagStatusJSON) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res.ToStatus())
+	}
+	fmt.Println(res.StatusFormat(viper.GetString(FlagStatusLanguage)))
+	return nil
+}
@@ -0,0 +1,192 @@
+package dashboard
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	sessionCookieName = "nuki_logger_session"
+	// telegramAuthMaxAge is the maximum age of a Telegram login widget authentication
+	telegramAuthMaxAge = time.Hour * 24
+)
+
+// User can log in to the dashboard with a password
+type User struct {
+	Username string `mapstructure:"username"`
+	// Password is either in clear text or sha256:<hex digest>
+	Password string `mapstructure:"password"`
+}
+
+func (u User) checkPassword(password string) bool {
+	expected := u.Password
+	if digest, ok := strings.CutPrefix(u.Password, "sha256:"); ok {
+		sum := sha256.Sum256([]byte(password))
+		password = hex.EncodeToString(sum[:])
+		expected = strings.ToLower(digest)
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1
+}
+
+// TelegramLoginConfig configures the Telegram login widget
+type TelegramLoginConfig struct {
+	// BotUsername is the username of the bot the widget is linked to (see /setdomain on BotFather)
+	BotUsername string `mapstructure:"bot_username"`
+	// UserIDs are the Telegram users allowed to log in
+	UserIDs []int64 `mapstructure:"user_ids"`
+}
+
+func (c TelegramLoginConfig) IsEnabled() bool {
+	return c.BotUsername != "" && len(c.UserIDs) > 0
+}
+
+// session is stored in a signed cookie: <username>|<expiry unix>|<signature>
+type session struct {
+	Username string
+	Expiry   time.Time
+}
+
+func (d *Dashboard) sign(value string) string {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dashboard) setSession(w http.ResponseWriter, username string) {
+	expiry := time.Now().Add(d.config.GetSessionDuration())
+	value := fmt.Sprintf("%s|%d", base64.RawURLEncoding.EncodeToString([]byte(username)), expiry.Unix())
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value + "|" + d.sign(value),
+		Path:     d.config.GetPath(),
+		Expires:  expiry,
+		HttpOnly: true,
+		Secure:   d.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (d *Dashboard) clearSession(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     d.config.GetPath(),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   d.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (d *Dashboard) getSession(r *http.Request) (*session, bool) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil, false
+	}
+	i := strings.LastIndex(cookie.Value, "|")
+	if i < 0 {
+		return nil, false
+	}
+	value, signature := cookie.Value[:i], cookie.Value[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(d.sign(value))) {
+		return nil, false
+	}
+	encodedUsername, expiryStr, _ := strings.Cut(value, "|")
+	username, err := base64.RawURLEncoding.DecodeString(encodedUsername)
+	if err != nil {
+		return nil, false
+	}
+	expiry, err := strconv.ParseInt(expiryStr, 10, 64)
+	if err != nil || time.Now().After(time.Unix(expiry, 0)) {
+		return nil, false
+	}
+	return &session{Username: string(username), Expiry: time.Unix(expiry, 0)}, true
+}
+
+// csrfToken returns the token forms must send back, bound to the session
+func (d *Dashboard) csrfToken(s *session) string {
+	return d.sign(fmt.Sprintf("csrf|%s|%d", s.Username, s.Expiry.Unix()))
+}
+
+func (d *Dashboard) checkCSRF(r *http.Request, s *session) bool {
+	return hmac.Equal([]byte(r.PostFormValue("csrf")), []byte(d.csrfToken(s)))
+}
+
+// authenticated redirects to the login page when no valid session is found
+func (d *Dashboard) authenticated(next func(w http.ResponseWriter, r *http.Request, s *session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := d.getSession(r)
+		if !ok {
+			http.Redirect(w, r, d.url("/login"), http.StatusSeeOther)
+			return
+		}
+		if r.Method == http.MethodPost && !d.checkCSRF(r, s) {
+			http.Error(w, "invalid csrf token", http.StatusForbidden)
+			return
+		}
+		next(w, r, s)
+	}
+}
+
+func (d *Dashboard) checkPassword(username, password string) bool {
+	for _, u := range d.config.Users {
+		if u.Username == username && u.checkPassword(password) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTelegramLogin verifies the data sent by the Telegram login widget and returns the user's id.
+// See https://core.telegram.org/widgets/login#checking-authorization
+func (d *Dashboard) checkTelegramLogin(values url.Values) (int64, error) {
+	if !d.config.Telegram.IsEnabled() || d.botToken == "" {
+		return 0, fmt.Errorf("telegram login is disabled")
+	}
+
+	hash := values.Get("hash")
+	keys := slices.DeleteFunc(maps.Keys(values), func(k string) bool { return k == "hash" })
+	slices.Sort(keys)
+	var lines []string
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, values.Get(k)))
+	}
+	secret := sha256.Sum256([]byte(d.botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	if !hmac.Equal([]byte(hash), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return 0, fmt.Errorf("invalid telegram login hash")
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil || time.Since(time.Unix(authDate, 0)) > telegramAuthMaxAge {
+		return 0, fmt.Errorf("telegram login expired")
+	}
+	id, err := strconv.ParseInt(values.Get("id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid telegram user id")
+	}
+	if !slices.Contains(d.config.Telegram.UserIDs, id) {
+		return 0, fmt.Errorf("telegram user %d is not allowed", id)
+	}
+	return id, nil
+}
+
+func randomSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	return secret, err
+}
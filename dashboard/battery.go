@@ -0,0 +1,114 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	batteryHistoryCacheKey = "battery-history"
+	// batteryHistoryRetention is how long battery samples are kept
+	batteryHistoryRetention = time.Hour * 24 * 90
+	// batteryHistoryMinInterval avoids recording a sample each time the dashboard is displayed
+	batteryHistoryMinInterval = time.Minute * 30
+
+	chartWidth  = 600
+	chartHeight = 150
+)
+
+// BatterySample is the charge of the smartlock's battery at a given time
+type BatterySample struct {
+	Date   time.Time `json:"date"`
+	Charge int32     `json:"charge"`
+}
+
+// batteryHistory keeps battery samples, saving them to the cache when available
+type batteryHistory struct {
+	mutex   sync.Mutex
+	cache   cache.Cache
+	samples []BatterySample
+}
+
+func newBatteryHistory(cache cache.Cache) *batteryHistory {
+	return &batteryHistory{cache: cache}
+}
+
+func (h *batteryHistory) Record(date time.Time, charge int32) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if n := len(h.samples); n > 0 && date.Sub(h.samples[n-1].Date) < batteryHistoryMinInterval {
+		return
+	}
+	h.samples = append(h.samples, BatterySample{Date: date, Charge: charge})
+	for len(h.samples) > 0 && date.Sub(h.samples[0].Date) > batteryHistoryRetention {
+		h.samples = h.samples[1:]
+	}
+
+	if h.cache == nil {
+		return
+	}
+	if err := h.cache.Save(batteryHistoryCacheKey, h.samples); err != nil {
+		log.Error().Err(err).Msg("Unable to save battery history to cache")
+	}
+}
+
+func (h *batteryHistory) Samples() []BatterySample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]BatterySample(nil), h.samples...)
+}
+
+func (h *batteryHistory) load() error {
+	if h.cache == nil {
+		return cache.ErrCacheNoClient
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	err := h.cache.Load(batteryHistoryCacheKey, &h.samples)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNoServers):
+		return nil
+	default:
+		return err
+	}
+}
+
+// batteryChart renders samples as an inline SVG line chart, charge going from 0 to 100%
+func batteryChart(samples []BatterySample, loc *time.Location) template.HTML {
+	if len(samples) < 2 {
+		return ""
+	}
+	first, last := samples[0].Date, samples[len(samples)-1].Date
+	span := last.Sub(first).Seconds()
+	if span <= 0 {
+		return ""
+	}
+
+	var points []string
+	for _, s := range samples {
+		x := s.Date.Sub(first).Seconds() / span * chartWidth
+		y := chartHeight - float64(s.Charge)/100*chartHeight
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg class="chart" viewBox="0 -10 %d %d" preserveAspectRatio="none" role="img">`, chartWidth, chartHeight+30)
+	for _, level := range []int{0, 30, 100} {
+		y := chartHeight - float64(level)/100*chartHeight
+		fmt.Fprintf(&sb, `<line x1="0" y1="%.1f" x2="%d" y2="%.1f" class="grid"/><text x="2" y="%.1f">%d%%</text>`, y, chartWidth, y, y-2, level)
+	}
+	fmt.Fprintf(&sb, `<polyline points="%s"/>`, strings.Join(points, " "))
+	fmt.Fprintf(&sb, `<text x="0" y="%d">%s</text>`, chartHeight+16, first.In(loc).Format("02/01 15:04"))
+	fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth, chartHeight+16, last.In(loc).Format("02/01 15:04"))
+	sb.WriteString(`</svg>`)
+	// Only numbers and dates formatted above are part of the svg
+	return template.HTML(sb.String())
+}
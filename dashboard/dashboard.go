@@ -0,0 +1,438 @@
+package dashboard
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	tgbroutine "github.com/nmaupu/nuki-logger/telegrambot/routine"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultPath            = "/dashboard"
+	DefaultSessionDuration = time.Hour * 12
+	activityFeedSize       = 30
+	dateTimeFormat         = "02/01 15:04"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// Config configures the web dashboard served by the http server
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path of the dashboard on the http server
+	Path  string `mapstructure:"path"`
+	Users []User `mapstructure:"users"`
+	// Telegram enables the Telegram login widget, using the bot's token to verify logins
+	Telegram TelegramLoginConfig `mapstructure:"telegram_login"`
+	// SessionSecret signs session cookies, a random one is generated at startup when empty
+	SessionSecret   string        `mapstructure:"session_secret"`
+	SessionDuration time.Duration `mapstructure:"session_duration"`
+	Timezone        string        `mapstructure:"timezone"`
+	Language        string        `mapstructure:"language"`
+}
+
+func (c Config) GetPath() string {
+	if c.Path == "" {
+		return DefaultPath
+	}
+	return strings.TrimSuffix(c.Path, "/")
+}
+
+func (c Config) GetSessionDuration() time.Duration {
+	if c.SessionDuration <= 0 {
+		return DefaultSessionDuration
+	}
+	return c.SessionDuration
+}
+
+// Dashboard is a server-rendered web UI showing activity, batteries, reservations and pending modifications
+type Dashboard struct {
+	config              Config
+	loc                 *time.Location
+	secret              []byte
+	secure              bool
+	botToken            string
+	templates           *template.Template
+	smartlockReader     nukiapi.SmartlockReader
+	reservationsReader  nukiapi.ReservationsReader
+	smartlockAuthReader nukiapi.SmartlockAuthReader
+	mutexDefaults       sync.RWMutex
+	defaultCheckIn      time.Time
+	defaultCheckOut     time.Time
+	battery             *batteryHistory
+	// pendingModifications is nil when the telegram bot is disabled
+	pendingModifications tgbroutine.ReservationPendingModificationRoutine
+
+	mutexLogs  sync.RWMutex
+	storedLogs []model.NukiSmartlockLogResponse
+}
+
+func New(config Config,
+	secure bool,
+	botToken string,
+	smartlockReader nukiapi.SmartlockReader,
+	reservationsReader nukiapi.ReservationsReader,
+	smartlockAuthReader nukiapi.SmartlockAuthReader,
+	defaultCheckIn, defaultCheckOut time.Time,
+	historyCache cache.Cache) (*Dashboard, error) {
+	if len(config.Users) == 0 && !config.Telegram.IsEnabled() {
+		return nil, fmt.Errorf("dashboard needs at least a user or the telegram login")
+	}
+	loc, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load dashboard timezone %s: %w", config.Timezone, err)
+	}
+	config.Language = i18n.Normalize(config.Language)
+
+	secret := []byte(config.SessionSecret)
+	if len(secret) == 0 {
+		if secret, err = randomSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	templates, err := template.New("").Funcs(template.FuncMap{
+		"url": func(path string) string { return config.GetPath() + path },
+	}).ParseFS(templatesFS, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dashboard{
+		config:              config,
+		loc:                 loc,
+		secret:              secret,
+		secure:              secure,
+		botToken:            botToken,
+		templates:           templates,
+		smartlockReader:     smartlockReader,
+		reservationsReader:  reservationsReader,
+		smartlockAuthReader: smartlockAuthReader,
+		defaultCheckIn:      defaultCheckIn,
+		defaultCheckOut:     defaultCheckOut,
+		battery:             newBatteryHistory(historyCache),
+	}
+	if err := d.battery.load(); err != nil && !errors.Is(err, cache.ErrCacheNoClient) {
+		log.Error().Err(err).Msg("Unable to load battery history from cache")
+	}
+	return d, nil
+}
+
+// SetPendingModificationRoutine enables pending modifications edition
+func (d *Dashboard) SetPendingModificationRoutine(r tgbroutine.ReservationPendingModificationRoutine) {
+	d.pendingModifications = r
+}
+
+// SetDefaultCheckTimes changes the access times suggested when editing a modification
+func (d *Dashboard) SetDefaultCheckTimes(checkIn, checkOut time.Time) {
+	d.mutexDefaults.Lock()
+	defer d.mutexDefaults.Unlock()
+	d.defaultCheckIn = checkIn
+	d.defaultCheckOut = checkOut
+}
+
+func (d *Dashboard) getDefaultCheckTimes() (time.Time, time.Time) {
+	d.mutexDefaults.RLock()
+	defer d.mutexDefaults.RUnlock()
+	return d.defaultCheckIn, d.defaultCheckOut
+}
+
+// SetStoredLogs updates the logs of the activity feed, newest first
+func (d *Dashboard) SetStoredLogs(logs []model.NukiSmartlockLogResponse) {
+	d.mutexLogs.Lock()
+	defer d.mutexLogs.Unlock()
+	d.storedLogs = logs
+}
+
+// RecordBattery adds the smartlock's battery charge to the battery history
+func (d *Dashboard) RecordBattery(smartlock model.SmartlockResponse) {
+	d.battery.Record(time.Now(), smartlock.State.BatteryCharge)
+}
+
+func (d *Dashboard) url(path string) string {
+	return d.config.GetPath() + path
+}
+
+// Register registers all the dashboard's handlers on mux
+func (d *Dashboard) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+d.url("/login"), d.handleLoginPage)
+	mux.HandleFunc("POST "+d.url("/login"), d.handleLogin)
+	mux.HandleFunc("GET "+d.url("/auth/telegram"), d.handleTelegramLogin)
+	mux.HandleFunc("POST "+d.url("/logout"), d.authenticated(d.handleLogout))
+	mux.HandleFunc("GET "+d.url("/{$}"), d.authenticated(d.handleIndex))
+	mux.HandleFunc("GET "+d.url("/activity"), d.authenticated(d.handleActivity))
+	mux.HandleFunc("GET "+d.url("/code/{ref}"), d.authenticated(d.handleCode))
+	mux.HandleFunc("POST "+d.url("/modifications"), d.authenticated(d.handleSaveModification))
+	mux.HandleFunc("POST "+d.url("/modifications/{ref}/delete"), d.authenticated(d.handleDeleteModification))
+	mux.Handle("GET "+d.config.GetPath(), http.RedirectHandler(d.url("/"), http.StatusMovedPermanently))
+}
+
+func (d *Dashboard) render(w http.ResponseWriter, status int, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := d.templates.ExecuteTemplate(w, name, data); err != nil {
+		log.Error().Err(err).Str("template", name).Msg("Unable to render dashboard template")
+	}
+}
+
+type loginPage struct {
+	Error            string
+	TelegramBot      string
+	TelegramAuthURL  string
+	PasswordsEnabled bool
+}
+
+func (d *Dashboard) loginPage(errMsg string) loginPage {
+	p := loginPage{Error: errMsg, PasswordsEnabled: len(d.config.Users) > 0}
+	if d.config.Telegram.IsEnabled() {
+		p.TelegramBot = d.config.Telegram.BotUsername
+		p.TelegramAuthURL = d.url("/auth/telegram")
+	}
+	return p
+}
+
+func (d *Dashboard) handleLoginPage(w http.ResponseWriter, _ *http.Request) {
+	d.render(w, http.StatusOK, "login.html", d.loginPage(""))
+}
+
+func (d *Dashboard) handleLogin(w http.ResponseWriter, r *http.Request) {
+	username := r.PostFormValue("username")
+	if !d.checkPassword(username, r.PostFormValue("password")) {
+		log.Warn().
+			Str("username", username).
+			Str("remote_addr", r.RemoteAddr).
+			Msg("Dashboard login failed")
+		d.render(w, http.StatusUnauthorized, "login.html", d.loginPage("Invalid username or password"))
+		return
+	}
+	d.setSession(w, username)
+	http.Redirect(w, r, d.url("/"), http.StatusSeeOther)
+}
+
+func (d *Dashboard) handleTelegramLogin(w http.ResponseWriter, r *http.Request) {
+	id, err := d.checkTelegramLogin(r.URL.Query())
+	if err != nil {
+		log.Warn().Err(err).
+			Str("remote_addr", r.RemoteAddr).
+			Msg("Dashboard telegram login failed")
+		d.render(w, http.StatusUnauthorized, "login.html", d.loginPage("Telegram login refused"))
+		return
+	}
+	d.setSession(w, fmt.Sprintf("telegram:%d", id))
+	http.Redirect(w, r, d.url("/"), http.StatusSeeOther)
+}
+
+func (d *Dashboard) handleLogout(w http.ResponseWriter, r *http.Request, _ *session) {
+	d.clearSession(w)
+	http.Redirect(w, r, d.url("/login"), http.StatusSeeOther)
+}
+
+type activityLine struct {
+	Date    string
+	Action  string
+	Trigger string
+	State   string
+	Name    string
+	Failed  bool
+}
+
+type reservationLine struct {
+	Name      string
+	Reference string
+	Start     string
+	End       string
+	Current   bool
+	Pending   *model.ReservationPendingModification
+}
+
+type indexPage struct {
+	Username      string
+	CSRF          string
+	Error         string
+	Smartlock     *model.SmartlockResponse
+	BatteryChart  template.HTML
+	Activity      []activityLine
+	Reservations  []reservationLine
+	Modifications []model.ReservationPendingModification
+	CanModify     bool
+	DefaultIn     string
+	DefaultOut    string
+}
+
+func (d *Dashboard) handleIndex(w http.ResponseWriter, _ *http.Request, s *session) {
+	defaultIn, defaultOut := d.getDefaultCheckTimes()
+	page := indexPage{
+		Username:   s.Username,
+		CSRF:       d.csrfToken(s),
+		CanModify:  d.pendingModifications != nil,
+		DefaultIn:  defaultIn.Format(model.FormatTimeHoursMinutes),
+		DefaultOut: defaultOut.Format(model.FormatTimeHoursMinutes),
+	}
+	var errs []string
+
+	smartlock, err := d.smartlockReader.Execute()
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("unable to read smartlock: %v", err))
+	} else {
+		page.Smartlock = smartlock
+		d.RecordBattery(*smartlock)
+	}
+	page.BatteryChart = batteryChart(d.battery.Samples(), d.loc)
+
+	resas, err := d.reservationsReader.Execute()
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("unable to get reservations: %v", err))
+	}
+	page.Activity = d.activity(resas)
+
+	pendings := make(map[string]model.ReservationPendingModification)
+	if d.pendingModifications != nil {
+		page.Modifications = d.pendingModifications.GetAllPendingModifications()
+		slices.SortFunc(page.Modifications, func(a, b model.ReservationPendingModification) int {
+			return strings.Compare(a.ReservationRef, b.ReservationRef)
+		})
+		for _, p := range page.Modifications {
+			pendings[p.ReservationRef] = p
+		}
+	}
+
+	now := time.Now()
+	slices.SortFunc(resas, func(a, b model.NukiReservationResponse) int {
+		return a.StartDate.Compare(b.StartDate)
+	})
+	for _, r := range resas {
+		if r.EndDate.Before(now) {
+			continue
+		}
+		line := reservationLine{
+			Name:      r.Name,
+			Reference: r.Reference,
+			Start:     r.StartDate.In(d.loc).Format(dateTimeFormat),
+			End:       r.EndDate.In(d.loc).Format(dateTimeFormat),
+			Current:   now.After(r.StartDate),
+		}
+		if p, ok := pendings[r.Reference]; ok {
+			line.Pending = &p
+		}
+		page.Reservations = append(page.Reservations, line)
+	}
+
+	page.Error = strings.Join(errs, ", ")
+	d.render(w, http.StatusOK, "index.html", page)
+}
+
+func (d *Dashboard) handleActivity(w http.ResponseWriter, _ *http.Request, _ *session) {
+	resas, err := d.reservationsReader.Execute()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to get reservations to resolve names")
+	}
+	d.render(w, http.StatusOK, "activity.html", d.activity(resas))
+}
+
+// activity returns the last logs, keypad codes being resolved to reservations' names
+func (d *Dashboard) activity(resas []model.NukiReservationResponse) []activityLine {
+	names := make(map[string]string)
+	for _, r := range resas {
+		names[r.Reference] = r.Name
+	}
+
+	d.mutexLogs.RLock()
+	logs := d.storedLogs[:min(activityFeedSize, len(d.storedLogs))]
+	d.mutexLogs.RUnlock()
+
+	var lines []activityLine
+	for _, l := range logs {
+		e := messaging.Event{Log: l, ReservationName: names[l.Name]}
+		values := e.GetValues(false, false, d.config.Timezone, d.config.Language)
+		name := l.Name
+		if values["name"] != "" {
+			name = fmt.Sprintf("%s (%s)", values["name"], l.Name)
+		}
+		lines = append(lines, activityLine{
+			Date:    l.Date.In(d.loc).Format("02/01 15:04:05"),
+			Action:  values["action"],
+			Trigger: values["trigger"],
+			State:   values["state"],
+			Name:    name,
+			Failed:  l.State != model.NukiStateSuccess,
+		})
+	}
+	return lines
+}
+
+func (d *Dashboard) handleCode(w http.ResponseWriter, r *http.Request, s *session) {
+	ref := r.PathValue("ref")
+	auths, err := d.smartlockAuthReader.Execute()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for _, auth := range auths {
+		if auth.Name == ref {
+			log.Info().
+				Str("username", s.Username).
+				Str("ref", ref).
+				Msg("Door code revealed on dashboard")
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			fmt.Fprint(w, auth.Code)
+			return
+		}
+	}
+	http.Error(w, "no code found", http.StatusNotFound)
+}
+
+func (d *Dashboard) handleSaveModification(w http.ResponseWriter, r *http.Request, s *session) {
+	if d.pendingModifications == nil {
+		http.Error(w, "pending modifications need the telegram bot", http.StatusServiceUnavailable)
+		return
+	}
+
+	ref := strings.TrimSpace(r.PostFormValue("reservation_ref"))
+	checkIn, errIn := time.Parse(model.FormatTimeHoursMinutes, r.PostFormValue("check_in"))
+	checkOut, errOut := time.Parse(model.FormatTimeHoursMinutes, r.PostFormValue("check_out"))
+	if ref == "" || errIn != nil || errOut != nil {
+		http.Error(w, "a reservation reference and check in/out times (HH:MM) are mandatory", http.StatusBadRequest)
+		return
+	}
+
+	log.Info().
+		Str("username", s.Username).
+		Str("ref", ref).
+		Msg("Pending modification saved from dashboard")
+	d.pendingModifications.AddPendingModification(model.ReservationPendingModification{
+		ReservationRef: ref,
+		CheckInTime:    checkIn,
+		CheckOutTime:   checkOut,
+		GuestName:      strings.TrimSpace(r.PostFormValue("guest_name")),
+	})
+	http.Redirect(w, r, d.url("/#modifications"), http.StatusSeeOther)
+}
+
+func (d *Dashboard) handleDeleteModification(w http.ResponseWriter, r *http.Request, s *session) {
+	if d.pendingModifications == nil {
+		http.Error(w, "pending modifications need the telegram bot", http.StatusServiceUnavailable)
+		return
+	}
+	ref := r.PathValue("ref")
+	log.Info().
+		Str("username", s.Username).
+		Str("ref", ref).
+		Msg("Pending modification deleted from dashboard")
+	d.pendingModifications.DeletePendingModification(ref)
+	http.Redirect(w, r, d.url("/#modifications"), http.StatusSeeOther)
+}
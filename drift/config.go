@@ -0,0 +1,106 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+)
+
+// Config configures the detection of changes of the smartlock's configuration
+type Config struct {
+	// Enabled snapshots the smartlock's configuration on each smartlock check, alerting on changes
+	Enabled bool `mapstructure:"enabled"`
+	// Desired is the declared state by section (config, advanced_config or web_config) and field,
+	// names being matched to the API's ones regardless of case and underscores (auto_lock matches autoLock)
+	Desired map[string]map[string]any `mapstructure:"desired"`
+	// Enforce pushes the desired state back to the smartlock when it drifts
+	Enforce bool `mapstructure:"enforce"`
+}
+
+// Validate checks that the desired fields exist, can be updated and have the type of the API's ones
+func (c Config) Validate() error {
+	_, err := c.desiredState()
+	if c.Enforce && len(c.Desired) == 0 {
+		err = errors.Join(err, errors.New("enforce needs a desired state"))
+	}
+	return err
+}
+
+// desiredState returns the desired values by snapshot key, converted to the types of the API's JSON
+func (c Config) desiredState() (Snapshot, error) {
+	known, _ := TakeSnapshot(model.SmartlockResponse{})
+	sections := make(map[string]string)
+	fields := make(map[string]string)
+	for key := range known {
+		section, field, _ := strings.Cut(key, ".")
+		sections[normalize(section)] = section
+		fields[normalize(section)+"."+normalize(field)] = key
+	}
+
+	var errs []error
+	res := make(Snapshot)
+	for section, values := range c.Desired {
+		s, ok := sections[normalize(section)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("desired.%s: unknown section", section))
+			continue
+		}
+		for field, value := range values {
+			key, ok := fields[normalize(s)+"."+normalize(field)]
+			if !ok {
+				errs = append(errs, fmt.Errorf("desired.%s.%s: unknown field", section, field))
+				continue
+			}
+			if sec, f, _ := strings.Cut(key, "."); nukiapi.IsReadOnlyConfigField(sec, f) {
+				errs = append(errs, fmt.Errorf("desired.%s.%s: read-only field", section, field))
+				continue
+			}
+			v, err := toJSONValue(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("desired.%s.%s: %w", section, field, err))
+				continue
+			}
+			if reflect.TypeOf(v) != reflect.TypeOf(known[key]) {
+				errs = append(errs, fmt.Errorf("desired.%s.%s: invalid value %v, %s expected", section, field, value, jsonTypeName(known[key])))
+				continue
+			}
+			res[key] = v
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return res, errors.Join(errs...)
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// toJSONValue converts v to the value it would have once decoded from JSON
+func toJSONValue(v any) (any, error) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res any
+	err = json.Unmarshal(bytes, &res)
+	return res, err
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
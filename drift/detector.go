@@ -0,0 +1,232 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/maps"
+)
+
+const stateCacheKey = "smartlock-config-snapshot"
+
+// Snapshot is the smartlock's configuration by section and field JSON names, e.g. advancedConfig.autoLock
+type Snapshot map[string]any
+
+// TakeSnapshot returns the configuration sections of s
+func TakeSnapshot(s model.SmartlockResponse) (Snapshot, error) {
+	res := make(Snapshot)
+	for name, section := range sections(s) {
+		values, err := sectionValues(section)
+		if err != nil {
+			return nil, err
+		}
+		for field, v := range values {
+			res[name+"."+field] = v
+		}
+	}
+	return res, nil
+}
+
+func sections(s model.SmartlockResponse) map[string]any {
+	return map[string]any{
+		nukiapi.SectionConfig:         s.Config,
+		nukiapi.SectionAdvancedConfig: s.AdvancedConfig,
+		nukiapi.SectionWebConfig:      s.WebConfig,
+	}
+}
+
+func sectionValues(section any) (map[string]any, error) {
+	bytes, err := json.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	err = json.Unmarshal(bytes, &values)
+	return values, err
+}
+
+// state is saved to the cache so that changes made while the server is stopped are reported
+type state struct {
+	Snapshot Snapshot `json:"snapshot"`
+	// Drifts are the last reported drifts, not reported again as long as they and the enforce error don't change
+	Drifts       []model.SmartlockConfigDrift `json:"drifts"`
+	EnforceError string                       `json:"enforce_error"`
+}
+
+// Detector compares the smartlock's configuration with the previous check and the desired state
+type Detector struct {
+	mutex   sync.Mutex
+	config  Config
+	desired Snapshot
+	cache   cache.Cache
+	updater nukiapi.SmartlockConfigUpdater
+	state   state
+}
+
+func NewDetector(config Config, stateCache cache.Cache, updater nukiapi.SmartlockConfigUpdater) *Detector {
+	d := &Detector{cache: stateCache, updater: updater}
+	d.SetConfig(config)
+	if err := d.load(); err != nil && !errors.Is(err, cache.ErrCacheNoClient) {
+		log.Error().Err(err).Msg("Unable to load smartlock configuration snapshot from cache")
+	}
+	return d
+}
+
+// SetConfig replaces the configuration, which is expected to be valid
+func (d *Detector) SetConfig(config Config) {
+	desired, err := config.desiredState()
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid desired smartlock configuration, ignoring invalid fields")
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.config = config
+	d.desired = desired
+}
+
+// Check returns the changes of s since the previous check and its new drifts from the desired state,
+// pushing the desired state back when enforced. It returns nil when there is nothing to report.
+func (d *Detector) Check(s model.SmartlockResponse) (*model.SmartlockDrift, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if !d.config.Enabled {
+		return nil, nil
+	}
+
+	snapshot, err := TakeSnapshot(s)
+	if err != nil {
+		return nil, err
+	}
+
+	report := model.SmartlockDrift{Name: s.Name}
+	if d.state.Snapshot != nil {
+		report.Changes = diff(d.state.Snapshot, snapshot)
+	}
+
+	var drifts []model.SmartlockConfigDrift
+	for _, key := range sortedKeys(d.desired) {
+		if actual := snapshot[key]; !reflect.DeepEqual(actual, d.desired[key]) {
+			drifts = append(drifts, model.SmartlockConfigDrift{Field: key, Desired: d.desired[key], Actual: actual})
+		}
+	}
+
+	if d.config.Enforce && len(drifts) > 0 {
+		if err := d.enforce(drifts); err != nil {
+			log.Error().Err(err).Msg("Unable to enforce the desired smartlock configuration")
+			report.EnforceError = err.Error()
+		} else {
+			report.Enforced = true
+		}
+	}
+	if len(drifts) > 0 &&
+		(report.Enforced || report.EnforceError != d.state.EnforceError || !reflect.DeepEqual(drifts, d.state.Drifts)) {
+		report.Drifts = drifts
+	}
+
+	if report.Enforced {
+		// The desired values are expected on the next check, not being reported as changes
+		for _, dr := range drifts {
+			snapshot[dr.Field] = dr.Desired
+		}
+		drifts = nil
+	}
+	d.state = state{Snapshot: snapshot, Drifts: drifts, EnforceError: report.EnforceError}
+	d.save()
+
+	if len(report.Changes) == 0 && len(report.Drifts) == 0 {
+		return nil, nil
+	}
+	return &report, nil
+}
+
+// enforce sends the drifting sections with their desired fields, the API expecting whole sections.
+// Sections are read again as sent by the API so that the fields not modeled are sent back unchanged.
+func (d *Detector) enforce(drifts []model.SmartlockConfigDrift) error {
+	drifting := make(map[string]map[string]any)
+	for _, dr := range drifts {
+		section, field, _ := strings.Cut(dr.Field, ".")
+		if drifting[section] == nil {
+			drifting[section] = make(map[string]any)
+		}
+		drifting[section][field] = dr.Desired
+	}
+
+	current, err := d.updater.Read()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range sortedKeys(drifting) {
+		values, ok := current[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: section not returned by the API", name))
+			continue
+		}
+		for field, v := range drifting[name] {
+			values[field] = v
+		}
+		log.Info().Str("section", name).Msg("Pushing the desired smartlock configuration")
+		if err := d.updater.Execute(name, values); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Detector) save() {
+	if d.cache == nil {
+		return
+	}
+	if err := d.cache.Save(stateCacheKey, d.state); err != nil {
+		log.Error().Err(err).Msg("Unable to save smartlock configuration snapshot to cache")
+	}
+}
+
+func (d *Detector) load() error {
+	if d.cache == nil {
+		return cache.ErrCacheNoClient
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	err := d.cache.Load(stateCacheKey, &d.state)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNoServers):
+		return nil
+	default:
+		return err
+	}
+}
+
+// diff returns the fields whose values differ between old and current
+func diff(old, current Snapshot) []model.SmartlockConfigChange {
+	keys := make(map[string]any)
+	for k := range old {
+		keys[k] = nil
+	}
+	for k := range current {
+		keys[k] = nil
+	}
+	var changes []model.SmartlockConfigChange
+	for _, k := range sortedKeys(keys) {
+		if !reflect.DeepEqual(old[k], current[k]) {
+			changes = append(changes, model.SmartlockConfigChange{Field: k, Old: old[k], New: current[k]})
+		}
+	}
+	return changes
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
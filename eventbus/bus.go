@@ -0,0 +1,205 @@
+package eventbus
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultQueueSize = 100
+)
+
+type Kind string
+
+const (
+	// KindLog is published with the new smartlock logs
+	KindLog Kind = "log"
+	// KindSmartlock is published each time the smartlock's state is checked
+	KindSmartlock Kind = "smartlock"
+	// KindReservation is published when reservations have changed
+	KindReservation Kind = "reservation"
+	// KindModificationDone is published when a pending modification has been applied
+	KindModificationDone Kind = "modification_done"
+	// KindModificationFailed is published when a pending modification cannot be processed
+	KindModificationFailed Kind = "modification_failed"
+	// KindAlert is published when the smartlock needs attention (e.g. low batteries)
+	KindAlert Kind = "alert"
+)
+
+// Event is published on the bus, fields being set depending on its kind
+type Event struct {
+	Kind Kind
+	Date time.Time
+	// Messages are the events formatted by senders (log and alert kinds)
+	Messages     []*messaging.Event
+	Smartlock    *model.SmartlockResponse
+	Reservations []model.NukiReservationResponse
+	// Modification can be nil for a failed modification when the error is not related to a specific one
+	Modification *model.ReservationPendingModification
+	Err          error
+}
+
+// Logs returns the logs carried by the event's messages
+func (e Event) Logs() []model.NukiSmartlockLogResponse {
+	var logs []model.NukiSmartlockLogResponse
+	for _, m := range e.Messages {
+		if m.IsLogEvent() {
+			logs = append(logs, m.Log)
+		}
+	}
+	return logs
+}
+
+type Handler func(Event)
+
+// subscriber handles events in its own goroutine, reading them from a buffered queue
+type subscriber struct {
+	name    string
+	kinds   []Kind
+	queue   chan Event
+	handler Handler
+	// lossless subscribers keep the events their queue cannot hold instead of dropping them
+	lossless bool
+}
+
+func (s *subscriber) accepts(kind Kind) bool {
+	return len(s.kinds) == 0 || slices.Contains(s.kinds, kind)
+}
+
+func (s *subscriber) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	queue := (<-chan Event)(s.queue)
+	if s.lossless {
+		queue = s.spill()
+	}
+	for e := range queue {
+		s.handler(e)
+	}
+}
+
+// spill moves events from the queue to an unbounded backlog as soon as they are published,
+// the returned channel being closed once the queue is closed and the backlog handled
+func (s *subscriber) spill() <-chan Event {
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		var backlog []Event
+		in := s.queue
+		for in != nil || len(backlog) > 0 {
+			var send chan Event
+			var next Event
+			if len(backlog) > 0 {
+				send = out
+				next = backlog[0]
+			}
+			select {
+			case e, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				backlog = append(backlog, e)
+			case send <- next:
+				backlog = backlog[1:]
+			}
+		}
+	}()
+	return out
+}
+
+// Bus fans events out from producers to subscribers, a slow subscriber never delaying producers or other subscribers
+type Bus struct {
+	mutex       sync.RWMutex
+	subscribers []*subscriber
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+func New() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for the given kinds, all of them when none is given.
+// When the handler's queue of queueSize events is full, new events are dropped for it.
+func (b *Bus) Subscribe(name string, queueSize int, handler Handler, kinds ...Kind) {
+	b.subscribe(name, queueSize, handler, false, kinds)
+}
+
+// SubscribeLossless registers a handler like Subscribe, events that its queue cannot hold being kept in memory
+// instead of dropped. Publishing is never delayed by the handler.
+func (b *Bus) SubscribeLossless(name string, queueSize int, handler Handler, kinds ...Kind) {
+	b.subscribe(name, queueSize, handler, true, kinds)
+}
+
+func (b *Bus) subscribe(name string, queueSize int, handler Handler, lossless bool, kinds []Kind) {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	s := &subscriber{
+		name:     name,
+		kinds:    kinds,
+		queue:    make(chan Event, queueSize),
+		handler:  handler,
+		lossless: lossless,
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return
+	}
+	b.subscribers = append(b.subscribers, s)
+	b.wg.Add(1)
+	go s.run(&b.wg)
+}
+
+// Publish queues the event for all subscribers interested in its kind
+func (b *Bus) Publish(e Event) {
+	if e.Date.IsZero() {
+		e.Date = time.Now()
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, s := range b.subscribers {
+		if !s.accepts(e.Kind) {
+			continue
+		}
+		if s.lossless {
+			// The queue is continuously drained to the subscriber's backlog
+			s.queue <- e
+			continue
+		}
+		select {
+		case s.queue <- e:
+		default:
+			log.Warn().
+				Str("subscriber", s.name).
+				Str("kind", string(e.Kind)).
+				Msg("Subscriber's queue is full, dropping event")
+		}
+	}
+}
+
+// Close stops accepting events and waits for subscribers to handle the queued ones
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return
+	}
+	b.closed = true
+	for _, s := range b.subscribers {
+		close(s.queue)
+	}
+	b.mutex.Unlock()
+	b.wg.Wait()
+}
@@ -0,0 +1,22 @@
+package eventbus
+
+import (
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/rs/zerolog/log"
+)
+
+// SubscribeSender sends log and alert events to sender from its own worker, never dropping them
+func (b *Bus) SubscribeSender(sender messaging.Sender, queueSize int) {
+	b.SubscribeLossless("sender:"+sender.GetName(), queueSize, func(e Event) {
+		if len(e.Messages) == 0 {
+			return
+		}
+		if err := sender.Send(e.Messages); err != nil {
+			log.Error().
+				Err(err).
+				Str("sender", sender.GetName()).
+				Str("kind", string(e.Kind)).
+				Msg("Unable to send message to sender")
+		}
+	}, KindLog, KindAlert)
+}
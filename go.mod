@@ -5,6 +5,7 @@ go 1.22
 require (
 	github.com/bradfitz/gomemcache v0.0.0-20230905024940-24af94b03874
 	github.com/enescakir/emoji v1.0.0
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/looplab/fsm v1.0.1
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/mymmrac/telego v0.29.2
@@ -20,7 +21,6 @@ require (
 	github.com/chenzhuoyu/base64x v0.0.0-20230717121745-296ad89f973d // indirect
 	github.com/chenzhuoyu/iasm v0.9.1 // indirect
 	github.com/fasthttp/router v1.5.0 // indirect
-	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/grbit/go-json v0.11.0 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,75 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Server is the HTTP server shared by all the services exposed by nuki-logger
+type Server struct {
+	Port        int
+	TLSCertFile string
+	TLSKeyFile  string
+	mux         *http.ServeMux
+	server      *http.Server
+}
+
+func NewServer(port int, tlsCertFile, tlsKeyFile string) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		Port:        port,
+		TLSCertFile: tlsCertFile,
+		TLSKeyFile:  tlsKeyFile,
+		mux:         mux,
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", port),
+			Handler: mux,
+		},
+	}
+}
+
+// Mux returns the underlying ServeMux to register handlers on
+func (s *Server) Mux() *http.ServeMux {
+	return s.mux
+}
+
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// IsTLS returns true if the server serves HTTPS by itself (i.e. not behind a reverse proxy)
+func (s *Server) IsTLS() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
+}
+
+// Start starts listening in the background
+func (s *Server) Start() {
+	go func() {
+		log.Info().
+			Int("port", s.Port).
+			Bool("tls", s.IsTLS()).
+			Msg("Starting http server")
+
+		var err error
+		if s.IsTLS() {
+			err = s.server.ListenAndServeTLS(s.TLSCertFile, s.TLSKeyFile)
+		} else {
+			err = s.server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Panic().Err(err).Int("port", s.Port).Msg("Unable to start http server")
+		}
+	}()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
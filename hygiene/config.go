@@ -0,0 +1,48 @@
+package hygiene
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultInterval    = time.Hour * 24 * 7
+	DefaultUnusedAfter = time.Hour * 24 * 90
+)
+
+// Config configures the review of the smartlock's authorizations
+type Config struct {
+	// Enabled sends a periodic report to the bot's hosts, with buttons to disable or delete authorizations
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the time between two reports
+	Interval time.Duration `mapstructure:"interval"`
+	// UnusedAfter is how long an enabled authorization can stay unused before being reported
+	UnusedAfter time.Duration `mapstructure:"unused_after"`
+	// IgnoredNames are authorizations never reported, e.g. the owners' ones
+	IgnoredNames []string `mapstructure:"ignored_names"`
+}
+
+func (c Config) GetInterval() time.Duration {
+	if c.Interval <= 0 {
+		return DefaultInterval
+	}
+	return c.Interval
+}
+
+func (c Config) GetUnusedAfter() time.Duration {
+	if c.UnusedAfter <= 0 {
+		return DefaultUnusedAfter
+	}
+	return c.UnusedAfter
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.Interval < 0 {
+		errs = append(errs, errors.New("interval cannot be negative"))
+	}
+	if c.UnusedAfter < 0 {
+		errs = append(errs, errors.New("unused_after cannot be negative"))
+	}
+	return errors.Join(errs...)
+}
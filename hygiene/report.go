@@ -0,0 +1,214 @@
+package hygiene
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+)
+
+type FindingKind string
+
+const (
+	// FindingReservationEnded is an enabled code of a reservation which has ended, or an enabled expired auth
+	FindingReservationEnded FindingKind = "reservation_ended"
+	// FindingUnused is an enabled auth not used for Config.UnusedAfter
+	FindingUnused FindingKind = "unused"
+	// FindingNoExpiry is an enabled auth allowed forever
+	FindingNoExpiry FindingKind = "no_expiry"
+	// FindingDuplicateName is an auth having the name of another one
+	FindingDuplicateName FindingKind = "duplicate_name"
+	// FindingRemoteNeverUsed is an auth allowed to operate the smartlock remotely which has never been used
+	FindingRemoteNeverUsed FindingKind = "remote_never_used"
+)
+
+var (
+	// Kinds are all the findings, in the order of the reports
+	Kinds = []FindingKind{
+		FindingReservationEnded,
+		FindingUnused,
+		FindingNoExpiry,
+		FindingDuplicateName,
+		FindingRemoteNeverUsed,
+	}
+	// DefaultApplyKinds are the findings acted upon by default, the other ones possibly being auths in use
+	DefaultApplyKinds = []FindingKind{FindingReservationEnded, FindingUnused}
+)
+
+type Action string
+
+const (
+	ActionDisable Action = "disable"
+	ActionDelete  Action = "delete"
+)
+
+func ParseAction(action string) (Action, error) {
+	switch a := Action(strings.ToLower(action)); a {
+	case ActionDisable, ActionDelete:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown action %q, expected %s or %s", action, ActionDisable, ActionDelete)
+	}
+}
+
+func ParseKind(kind string) (FindingKind, error) {
+	k := FindingKind(strings.ToLower(kind))
+	if !slices.Contains(Kinds, k) {
+		return "", fmt.Errorf("unknown finding %q, expected one of %v", kind, Kinds)
+	}
+	return k, nil
+}
+
+// Finding is an auth which should be reviewed, the code of keypad auths being left out
+type Finding struct {
+	Kind             FindingKind `json:"kind"`
+	AuthID           string      `json:"auth_id"`
+	Name             string      `json:"name"`
+	Type             string      `json:"type"`
+	Enabled          bool        `json:"enabled"`
+	RemoteAllowed    bool        `json:"remote_allowed"`
+	LastActiveDate   time.Time   `json:"last_active_date"`
+	AllowedUntilDate time.Time   `json:"allowed_until_date"`
+}
+
+func newFinding(kind FindingKind, a model.SmartlockAuthResponse) Finding {
+	authType := a.AuthTypeAsString
+	if authType == "" {
+		authType = fmt.Sprintf("type %d", a.Type)
+	}
+	return Finding{
+		Kind:             kind,
+		AuthID:           a.Id,
+		Name:             a.Name,
+		Type:             authType,
+		Enabled:          a.Enabled,
+		RemoteAllowed:    a.RemoteAllowed,
+		LastActiveDate:   a.LastActiveDate,
+		AllowedUntilDate: a.AllowedUntilDate,
+	}
+}
+
+// Apply disables or deletes the finding's auth
+func (f Finding) Apply(modifier nukiapi.SmartlockAuthModifier, action Action) error {
+	auth := model.SmartlockAuthResponse{Id: f.AuthID, Name: f.Name}
+	if action == ActionDelete {
+		return modifier.Delete(auth)
+	}
+	return modifier.SetEnabled(auth, false)
+}
+
+// Report lists the auths to review
+type Report struct {
+	Date        time.Time     `json:"date"`
+	UnusedAfter time.Duration `json:"unused_after"`
+	Findings    []Finding     `json:"findings"`
+}
+
+// Analyze reviews auths, reservations giving the end of their codes
+func Analyze(c Config, auths []model.SmartlockAuthResponse, reservations []model.NukiReservationResponse, now time.Time) Report {
+	ended := make(map[string]bool)
+	for _, r := range reservations {
+		if !r.EndDate.IsZero() && r.EndDate.Before(now) {
+			ended[r.Reference] = true
+		}
+	}
+	normalize := func(name string) string {
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	names := make(map[string]int)
+	for _, a := range auths {
+		names[normalize(a.Name)]++
+	}
+	unusedAfter := c.GetUnusedAfter()
+
+	report := Report{Date: now, UnusedAfter: unusedAfter}
+	for _, a := range auths {
+		if slices.ContainsFunc(c.IgnoredNames, func(n string) bool { return normalize(n) == normalize(a.Name) }) {
+			continue
+		}
+		add := func(kind FindingKind) {
+			report.Findings = append(report.Findings, newFinding(kind, a))
+		}
+
+		expired := !a.AllowedUntilDate.IsZero() && a.AllowedUntilDate.Before(now)
+		lastUsed := a.LastActiveDate
+		if lastUsed.IsZero() {
+			lastUsed = a.CreationDate
+		}
+		switch {
+		case a.Enabled && (ended[a.Name] || expired):
+			add(FindingReservationEnded)
+		case a.Enabled && !lastUsed.IsZero() && now.Sub(lastUsed) > unusedAfter:
+			add(FindingUnused)
+		}
+		if a.Enabled && a.AllowedUntilDate.IsZero() {
+			add(FindingNoExpiry)
+		}
+		if names[normalize(a.Name)] > 1 {
+			add(FindingDuplicateName)
+		}
+		if a.RemoteAllowed && a.LockCount == 0 && a.LastActiveDate.IsZero() {
+			add(FindingRemoteNeverUsed)
+		}
+	}
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		if d := slices.Index(Kinds, a.Kind) - slices.Index(Kinds, b.Kind); d != 0 {
+			return d
+		}
+		return strings.Compare(normalize(a.Name), normalize(b.Name))
+	})
+	return report
+}
+
+// Select returns the findings of the given kinds, an auth being returned once
+func (r Report) Select(kinds []FindingKind) []Finding {
+	seen := make(map[string]bool)
+	var res []Finding
+	for _, f := range r.Findings {
+		if !slices.Contains(kinds, f.Kind) || seen[f.AuthID] {
+			continue
+		}
+		seen[f.AuthID] = true
+		res = append(res, f)
+	}
+	return res
+}
+
+// Format returns the findings grouped by kind in the given language, dates being in loc
+func (r Report) Format(lang string, loc *time.Location) string {
+	auths := r.Select(Kinds)
+	if len(auths) == 0 {
+		return i18n.T(lang, "hygiene.none", emoji.Broom)
+	}
+
+	lines := []string{i18n.T(lang, "hygiene.title", emoji.Broom, len(auths))}
+	for _, kind := range Kinds {
+		findings := r.Select([]FindingKind{kind})
+		if len(findings) == 0 {
+			continue
+		}
+		lines = append(lines, "")
+		if kind == FindingUnused {
+			lines = append(lines, i18n.T(lang, "hygiene.kind."+string(kind), int(r.UnusedAfter.Hours()/24)))
+		} else {
+			lines = append(lines, i18n.T(lang, "hygiene.kind."+string(kind)))
+		}
+		for _, f := range findings {
+			lastUsed := i18n.T(lang, "hygiene.never")
+			if !f.LastActiveDate.IsZero() {
+				lastUsed = f.LastActiveDate.In(loc).Format(time.DateOnly)
+			}
+			state := ""
+			if !f.Enabled {
+				state = " " + i18n.T(lang, "hygiene.disabled_state")
+			}
+			lines = append(lines, i18n.T(lang, "hygiene.auth", f.Name, f.Type, lastUsed)+state)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,295 @@
+package i18n
+
+var de = map[string]string{
+	"lang.name": "Deutsch",
+	"lang.ask":  "Wählen Sie Ihre Sprache",
+	"lang.set":  "Sprache eingestellt: %s",
+
+	"error.generic":          "Ein Fehler ist aufgetreten: %v",
+	"error.retry":            "Ein Fehler ist aufgetreten, bitte erneut versuchen",
+	"error.api_reservations": "Reservierungen konnten nicht von der API abgerufen werden, err=%v",
+	"error.api_smartlock":    "Schlossstatus konnte nicht von der API gelesen werden, err=%v",
+	"error.api_auth":         "Berechtigungen konnten nicht von der API abgerufen werden, err=%v",
+	"error.api_logs":         "Protokolle konnten nicht von der API abgerufen werden, err=%v",
+	"error.parse":            "%s konnte nicht gelesen werden",
+	"error.cache_save":       "Speichern im Cache nicht möglich, err=%v",
+	"error.bot_info":         "Bot-Informationen konnten nicht abgerufen werden, err=%v",
+	"error.no_reservation":   "Keine Reservierung verfügbar",
+
+	"common.done":      "Erledigt.",
+	"common.yes":       "Ja",
+	"common.no":        "Nein",
+	"common.on":        "an",
+	"common.off":       "aus",
+	"common.confirmed": "Bestätigt!",
+	"common.canceled":  "Abgebrochen...",
+
+	"bot.not_allowed":         "Sie dürfen diesen Befehl nicht ausführen %s",
+	"bot.not_understood":      "Ich verstehe nicht %s",
+	"bot.button_expired":      "Diese Schaltfläche ist abgelaufen, nutzen Sie das Menü oder starten Sie einen neuen Befehl!",
+	"bot.session_expired":     "%s Sitzung abgelaufen, nutzen Sie das Menü oder starten Sie einen neuen Befehl!",
+	"bot.access_denied_admin": "%s Zugriff verweigert für %s %s (@%s, id=%d) beim Ausführen von %s",
+	"bot.help":                "Folgende Befehle sind verfügbar: \n%s",
+	"bot.menu":                "Menü",
+	"bot.version":             "%s, Version %s, Build %s",
+	"bot.modif_error":         "Fehler bei der Verarbeitung der ausstehenden Änderung, err=%v",
+	"bot.modif_done":          "Ausstehende Änderung durchgeführt für %s (%s -> %s)",
+
+	"cmd.help":           "Hilfe anzeigen",
+	"cmd.menu":           "Hauptmenü anzeigen",
+	"cmd.battery":        "Batteriestatus anzeigen",
+	"cmd.status":         "Vollständigen Status des Schlosses anzeigen",
+	"cmd.device":         "Firmware- und Abonnementverlauf des Schlosses anzeigen",
+	"cmd.hygiene":        "Veraltete Berechtigungen überprüfen",
+	"cmd.resa":           "Alle Reservierungen auflisten",
+	"cmd.logs":           "Protokolle des Nuki-Schlosses anzeigen",
+	"cmd.code":           "Türcode einer Reservierung anzeigen",
+	"cmd.version":        "Bot-Version anzeigen",
+	"cmd.modify":         "Check-in/-out einer Reservierung ändern",
+	"cmd.listmodify":     "Alle ausstehenden Änderungen auflisten",
+	"cmd.deletemodify":   "Eine ausstehende Änderung löschen",
+	"cmd.savemodify":     "Alle Änderungen im Cache speichern",
+	"cmd.applymodify":    "Alle ausstehenden Änderungen jetzt anwenden",
+	"cmd.link":           "Ihr Konto mit Ihrer Reservierung verknüpfen",
+	"cmd.mycode":         "Ihren Türcode anzeigen",
+	"cmd.latecheckout":   "Einen späten Check-out anfragen",
+	"cmd.guestlink":      "Einmal-Link für einen Gast erzeugen",
+	"cmd.lang":           "Sprache des Bots ändern",
+	"cmd.importbookings": "Buchungen der OTA-Feeds jetzt importieren",
+
+	"menu.battery":      "Batterie",
+	"menu.code":         "Code",
+	"menu.help":         "Hilfe",
+	"menu.logs":         "Protokolle",
+	"menu.resas":        "Reservierungen",
+	"menu.listmodify":   "Änderungen",
+	"menu.modify":       "Ändern",
+	"menu.mycode":       "Mein Code",
+	"menu.latecheckout": "Später Check-out",
+
+	"code.ask":       "Für welche *Reservierung* möchten Sie den Code?",
+	"code.result":    "Code für *%s*: %d",
+	"code.not_found": "Kein Code für *%s* gefunden",
+
+	"deletemodify.none": "Keine ausstehende Änderung verfügbar",
+	"deletemodify.ask":  "Welche *ausstehende Änderung* möchten Sie löschen?",
+	"deletemodify.done": "Erledigt\nKlicken Sie auf /listmodify, um die neue Liste anzuzeigen",
+	"listmodify.none":   "Keine ausstehende Änderung",
+
+	"logs.title":       "%s Protokolle, Seite %d",
+	"logs.filters":     "Filter: %s",
+	"logs.none":        "Keine Protokolle gefunden",
+	"logs.newer":       "Neuere",
+	"logs.older":       "Ältere",
+	"logs.action":      "Aktion",
+	"logs.trigger":     "Auslöser",
+	"logs.resa":        "Reservierung",
+	"logs.user":        "Benutzer",
+	"logs.failed":      "Nur Fehler",
+	"logs.date":        "Datum",
+	"logs.reset":       "Filter zurücksetzen",
+	"logs.close":       "Schließen",
+	"logs.closed":      "Protokollansicht geschlossen.",
+	"logs.all":         "Alle",
+	"logs.back":        "Zurück",
+	"logs.ask_action":  "Protokolle welcher Aktion anzeigen?",
+	"logs.ask_trigger": "Protokolle welches Auslösers anzeigen?",
+	"logs.ask_resa":    "Protokolle welcher Reservierung anzeigen?",
+	"logs.ask_user":    "Senden Sie den Namen eines Benutzers oder einer Reservierung",
+	"logs.ask_date":    "Protokolle welches Tages anzeigen?",
+
+	"date.months":   "Januar,Februar,März,April,Mai,Juni,Juli,August,September,Oktober,November,Dezember",
+	"date.weekdays": "Mo,Di,Mi,Do,Fr,Sa,So",
+
+	"modify.ask_resa":      "Geben Sie die *Reservierungs*-ID ein",
+	"modify.ask_check_in":  "Geben Sie die Check-in-Zeit ein (Standard: %s)",
+	"modify.ask_check_out": "Geben Sie die Check-out-Zeit ein (Standard: %s)",
+	"modify.confirm":       "%s Bestätigen Sie?\n*Reservierung*: %s\n*Check-in*: %s\n*Check-out*: %s",
+
+	"resa.title": "Reservierungen:\n%s",
+	"resa.ids":   "Reservierungs-IDs zum Kopieren/Einfügen:",
+
+	"guest.welcome_linked":   "%s Sie sind jetzt mit Ihrer Reservierung *%s* verknüpft.\nNutzen Sie /mycode für Ihren Türcode und /latecheckout für einen späten Check-out.",
+	"guest.link_invalid":     "Dieser Link ist ungültig oder abgelaufen, bitten Sie Ihren Gastgeber um einen neuen oder nutzen Sie /link",
+	"guest.resa_unavailable": "Diese Reservierung ist nicht mehr verfügbar",
+	"guest.ask_ref":          "Bitte senden Sie Ihre *Buchungsnummer*",
+	"guest.ref_not_found":    "Ihre Reservierung wurde nicht gefunden, bitte prüfen Sie Ihre Buchungsnummer",
+	"guest.not_linked":       "Sie sind mit keiner Reservierung verknüpft, nutzen Sie /link",
+	"guest.resa_over":        "Ihre Reservierung ist beendet, vielen Dank für Ihren Aufenthalt!",
+	"guest.code_unavailable": "Ihr Code ist derzeit nicht abrufbar, bitte versuchen Sie es später erneut",
+	"guest.code":             "%s Ihr Türcode: *%d*\nGültig von %s bis %s",
+	"guest.code_not_yet":     "Ihr Code ist noch nicht verfügbar, bitte versuchen Sie es später erneut",
+	"guest.ask_checkout":     "Um wie viel Uhr möchten Sie auschecken?",
+	"guest.request_sent":     "Ihre Anfrage wurde an Ihren Gastgeber gesendet, Sie werden über die Antwort informiert.",
+	"guest.host_request":     "%s *%s* (%s) fragt einen späten Check-out um *%s* an",
+	"guest.approve":          "Annehmen",
+	"guest.deny":             "Ablehnen",
+	"guest.answer_forbidden": "Sie dürfen diese Anfrage nicht beantworten %s",
+	"guest.already_answered": "Diese Anfrage wurde bereits beantwortet",
+	"guest.denied_host":      "Später Check-out für %s abgelehnt",
+	"guest.denied_guest":     "Leider konnte Ihr Gastgeber Ihre Anfrage für einen späten Check-out nicht annehmen.",
+	"guest.approved_host":    "Später Check-out um %s für %s angenommen",
+	"guest.approved_guest":   "%s Ihr später Check-out um %s wurde angenommen!",
+	"guest.ask_resa_link":    "Für welche *Reservierung* möchten Sie einen Gast-Link?",
+	"guest.link":             "Senden Sie diesen Einmal-Link an den Gast von %s (gültig %s):\nhttps://t.me/%s?start=%s",
+	"guest.welcome":          "%s Willkommen! Wir wünschen Ihnen einen schönen Aufenthalt. Nutzen Sie /latecheckout, wenn Sie später abreisen möchten.",
+
+	"guest.ref_already_linked": "Diese Reservierung ist bereits mit einem anderen Gast verknüpft, bitte fragen Sie Ihren Gastgeber nach einem Link",
+	"guest.link_locked":        "Zu viele falsche Buchungsnummern, bitte versuchen Sie es nach %s erneut oder fragen Sie Ihren Gastgeber nach einem Link",
+	"guest.host_linked":        "%s %s (%d) hat sich mit der Reservierung %s verknüpft",
+	"guest.host_link_locked":   "%s %s (%d) ist nach zu vielen falschen Buchungsnummern für /link gesperrt, zuletzt: %s",
+
+	"calendar.name":              "Nuki-Reservierungen",
+	"calendar.description":       "Reservierung: %s\nGäste: %d",
+	"calendar.pending":           "Ausstehende Änderung: Check-in %s, Check-out %s",
+	"calendar.access":            "Zugang: %s",
+	"calendar.first_entry":       "Erster Zutritt: %s",
+	"calendar.last_exit":         "Letztes Verlassen: %s",
+	"calendar.category_stay":     "Aufenthalt",
+	"calendar.category_access":   "Zugang",
+	"calendar.category_activity": "Aktivität",
+
+	"booking.conflict":                "%s Buchungskonflikt: %s\nFeed: %s\nReferenz: %s\nDaten: %s\nKonflikt mit: %s",
+	"booking.conflict_dates":          "Daten weichen von Nuki ab",
+	"booking.conflict_ambiguous":      "mehrere Reservierungen passen",
+	"booking.conflict_overlap":        "überschneidet sich mit einer anderen Reservierung",
+	"booking.conflict_double_booking": "Doppelbuchung",
+
+	"config.reloaded":         "%s Konfiguration neu geladen aus %s\n%s",
+	"config.reload_unchanged": "%s Konfiguration neu geladen aus %s, keine Änderungen",
+	"config.reload_rejected":  "%s Neue Konfiguration %s abgelehnt, die laufende wird beibehalten:\n%s",
+	"config.reload_restart":   "Änderungen werden erst nach einem Neustart übernommen: %s",
+
+	"drift.changed":        "%s Die Konfiguration des Schlosses %s wurde geändert:",
+	"drift.drifted":        "%s Die Konfiguration des Schlosses %s weicht vom Sollzustand ab:",
+	"drift.field":          "≠ %s: %v (Soll: %v)",
+	"drift.enforced":       "%s Sollkonfiguration an das Schloss übertragen",
+	"drift.enforce_failed": "%s Sollkonfiguration konnte nicht übertragen werden: %s",
+
+	"device.current":             "%s %s\nFirmware: %s, Hardware: %s\nAbonnement: %s",
+	"device.subscription_since":  "%s seit %s",
+	"device.no_subscription":     "keines",
+	"device.history":             "Verlauf:",
+	"device.no_history":          "Noch kein Verlauf",
+	"device.tracked":             "%s Schloss %s wird verfolgt, Firmware %s",
+	"device.firmware":            "%s Firmware des Schlosses %s aktualisiert: %s → %s",
+	"device.hardware":            "%s Hardware des Schlosses %s geändert: %s → %s",
+	"device.subscription":        "%s Abonnement des Schlosses %s geändert: %s → %s",
+	"device.subscription_expiry": "%[1]s Abonnement %[3]s des Schlosses %[2]s endet oder verlängert sich am %[4]s",
+
+	"hygiene.title":                  "%s Überprüfung der Berechtigungen: %d zu prüfen",
+	"hygiene.none":                   "%s Überprüfung der Berechtigungen: nichts aufzuräumen",
+	"hygiene.kind.reservation_ended": "Noch aktive Codes beendeter Reservierungen:",
+	"hygiene.kind.unused":            "Seit %d Tagen nicht benutzt:",
+	"hygiene.kind.no_expiry":         "Ohne Ablaufdatum:",
+	"hygiene.kind.duplicate_name":    "Doppelte Namen:",
+	"hygiene.kind.remote_never_used": "Fernzugriff erlaubt, aber nie benutzt:",
+	"hygiene.auth":                   "• %s (%s), zuletzt benutzt: %s",
+	"hygiene.never":                  "nie",
+	"hygiene.disabled_state":         "[deaktiviert]",
+	"hygiene.disable":                "%s deaktivieren",
+	"hygiene.delete":                 "%s löschen",
+	"hygiene.buttons_truncated":      "Schaltflächen werden nur für die ersten %d Berechtigungen angezeigt",
+	"hygiene.disabled":               "%s Berechtigung %s deaktiviert",
+	"hygiene.deleted":                "%s Berechtigung %s gelöscht",
+	"hygiene.not_found":              "Berechtigung %s nicht gefunden, sie wurde eventuell bereits gelöscht",
+	"hygiene.forbidden":              "%s Nur Gastgeber können Berechtigungen ändern",
+	"hygiene.action_failed":          "%s Berechtigung %s konnte nicht geändert werden: %v",
+
+	"sender.keypad_code": "%s%s %s durch '%s' %s",
+	"smartlock.pretty":   "*Schloss %s*\nBatterie: %s (%d%%)\nKeypad: %s\nTürsensor: %s",
+
+	"status.title":               "%s %s (%s)",
+	"status.lock_state":          "%s Schloss: %s",
+	"status.door_state":          "%s Tür: %s",
+	"status.mode":                "Modus: %s",
+	"status.last_action":         "Letzte Aktion: %s durch %s %s",
+	"status.night_mode":          "%s Nachtmodus: %s",
+	"status.battery":             "%s Batterie: %d%%%s",
+	"status.accessories_battery": "Keypad-Batterie: %s, Türsensor-Batterie: %s",
+	"status.versions":            "Firmware: %s, Hardware: %s, automatische Updates: %s",
+	"status.server_state":        "%s Server: %s",
+	"status.auto_lock":           "Automatisches Sperren: %s",
+	"status.auto_lock_timeout":   "%s nach %s",
+	"status.settings":            "Automatisches Entriegeln: %s, Taste: %s, LED: %s, Kopplung: %s",
+
+	"action.1":   "aufsperren",
+	"action.2":   "zusperren",
+	"action.3":   "entriegeln",
+	"action.4":   "lock'n'go",
+	"action.5":   "lock'n'go mit Entriegeln",
+	"action.208": "Warnung Tür angelehnt",
+	"action.209": "Warnung Türstatus abweichend",
+	"action.224": "Klingelerkennung (nur Opener)",
+	"action.240": "Tür geöffnet",
+	"action.241": "Tür geschlossen",
+	"action.242": "Türsensor blockiert",
+	"action.243": "Firmware-Update",
+	"action.250": "Türprotokoll aktiviert",
+	"action.251": "Türprotokoll deaktiviert",
+	"action.252": "Initialisierung",
+	"action.253": "Kalibrierung",
+	"action.254": "Protokoll aktiviert",
+	"action.255": "Protokoll deaktiviert",
+
+	"state.0":   "Erfolg",
+	"state.1":   "Motor blockiert",
+	"state.2":   "Abgebrochen",
+	"state.3":   "Zu kurz hintereinander",
+	"state.4":   "Beschäftigt",
+	"state.5":   "Niedrige Motorspannung",
+	"state.6":   "Kupplungsfehler",
+	"state.7":   "Motorstromfehler",
+	"state.8":   "Unvollständig",
+	"state.9":   "Abgelehnt",
+	"state.10":  "Abgelehnt (Nachtmodus)",
+	"state.224": "Falscher Keypad-Code",
+	"state.254": "Anderer Fehler",
+	"state.255": "Unbekannter Fehler",
+
+	"trigger.0":   "System",
+	"trigger.1":   "manuell",
+	"trigger.2":   "Knopf",
+	"trigger.3":   "automatisch",
+	"trigger.4":   "Web",
+	"trigger.5":   "App",
+	"trigger.6":   "Auto-Lock",
+	"trigger.7":   "Zubehör",
+	"trigger.255": "Keypad",
+
+	"source.0": "Standard",
+	"source.1": "Keypad-Code",
+	"source.2": "Fingerabdruck",
+
+	"mode.0": "nicht kalibriert",
+	"mode.1": "Kalibrierung",
+	"mode.2": "Türmodus",
+	"mode.3": "Dauermodus",
+	"mode.4": "Wartungsmodus",
+
+	"lock_state.0":   "nicht kalibriert",
+	"lock_state.1":   "verriegelt",
+	"lock_state.2":   "entriegelt gerade",
+	"lock_state.3":   "entriegelt",
+	"lock_state.4":   "verriegelt gerade",
+	"lock_state.5":   "Falle gezogen",
+	"lock_state.6":   "entriegelt (lock'n'go)",
+	"lock_state.7":   "zieht Falle",
+	"lock_state.254": "Motor blockiert",
+	"lock_state.255": "undefiniert",
+
+	"door_state.0":   "nicht verfügbar",
+	"door_state.1":   "deaktiviert",
+	"door_state.2":   "geschlossen",
+	"door_state.3":   "geöffnet",
+	"door_state.4":   "unbekannt",
+	"door_state.5":   "Kalibrierung",
+	"door_state.16":  "nicht kalibriert",
+	"door_state.240": "entfernt",
+
+	"server_state.0": "ok",
+	"server_state.1": "nicht registriert",
+	"server_state.2": "Auth-UUID ungültig",
+	"server_state.3": "Auth ungültig",
+	"server_state.4": "offline",
+}
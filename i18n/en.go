@@ -0,0 +1,295 @@
+package i18n
+
+var en = map[string]string{
+	"lang.name": "English",
+	"lang.ask":  "Choose your language",
+	"lang.set":  "Language set to %s",
+
+	"error.generic":          "An error occurred: %v",
+	"error.retry":            "An error occurred, please try again",
+	"error.api_reservations": "Unable to get reservations from API, err=%v",
+	"error.api_smartlock":    "Unable to read smartlock status from API, err=%v",
+	"error.api_auth":         "Unable to get smartlock auth from API, err=%v",
+	"error.api_logs":         "Unable to get logs from API, err=%v",
+	"error.parse":            "Unable to parse %s",
+	"error.cache_save":       "Unable to save to cache, err=%v",
+	"error.bot_info":         "Unable to get bot information, err=%v",
+	"error.no_reservation":   "No reservation available",
+
+	"common.done":      "Done.",
+	"common.yes":       "Yes",
+	"common.no":        "No",
+	"common.on":        "on",
+	"common.off":       "off",
+	"common.confirmed": "Confirmed!",
+	"common.canceled":  "Canceled...",
+
+	"bot.not_allowed":         "You are not allowed to run this command %s",
+	"bot.not_understood":      "I don't understand %s",
+	"bot.button_expired":      "This button is expired, use menu or initiate a new command!",
+	"bot.session_expired":     "%s Session expired, use menu or initiate a new command!",
+	"bot.access_denied_admin": "%s Access denied for %s %s (@%s, id=%d) running %s",
+	"bot.help":                "The following commands are available: \n%s",
+	"bot.menu":                "Menu",
+	"bot.version":             "%s, version %s, build %s",
+	"bot.modif_error":         "An error occurred processing pending modification, err=%v",
+	"bot.modif_done":          "Pending modification done for %s (%s -> %s)",
+
+	"cmd.help":           "Display help",
+	"cmd.menu":           "Show the main menu",
+	"cmd.battery":        "Display battery details",
+	"cmd.status":         "Display the smartlock's full status",
+	"cmd.device":         "Display the smartlock's firmware and subscription history",
+	"cmd.hygiene":        "Review stale authorizations",
+	"cmd.resa":           "List all reservations",
+	"cmd.logs":           "Display Nuki lock logs",
+	"cmd.code":           "Display a reservation door code",
+	"cmd.version":        "Display bot version",
+	"cmd.modify":         "Modify check-in/out of a specific reservation",
+	"cmd.listmodify":     "List all pending modifications",
+	"cmd.deletemodify":   "Delete a pending modification",
+	"cmd.savemodify":     "Save all modifications to the cache",
+	"cmd.applymodify":    "Apply all pending modifications now",
+	"cmd.link":           "Link your account to your reservation",
+	"cmd.mycode":         "Display your door code",
+	"cmd.latecheckout":   "Ask for a late check-out",
+	"cmd.guestlink":      "Generate a one-time link for a guest",
+	"cmd.lang":           "Change the bot language",
+	"cmd.importbookings": "Import bookings from the OTA feeds now",
+
+	"menu.battery":      "Battery",
+	"menu.code":         "Code",
+	"menu.help":         "Help",
+	"menu.logs":         "Logs",
+	"menu.resas":        "Resas",
+	"menu.listmodify":   "List modifs",
+	"menu.modify":       "Modify",
+	"menu.mycode":       "My code",
+	"menu.latecheckout": "Late check-out",
+
+	"code.ask":       "What *reservation* do you want the code for?",
+	"code.result":    "Code for *%s*: %d",
+	"code.not_found": "Unable to find any code for *%s*",
+
+	"deletemodify.none": "No pending modification available",
+	"deletemodify.ask":  "What *pending modification* do you want to remove?",
+	"deletemodify.done": "Done\nClick on /listmodify to display the new list",
+	"listmodify.none":   "No pending modification",
+
+	"logs.title":       "%s Logs, page %d",
+	"logs.filters":     "Filters: %s",
+	"logs.none":        "No logs found",
+	"logs.newer":       "Newer",
+	"logs.older":       "Older",
+	"logs.action":      "Action",
+	"logs.trigger":     "Trigger",
+	"logs.resa":        "Reservation",
+	"logs.user":        "User",
+	"logs.failed":      "Failed only",
+	"logs.date":        "Date",
+	"logs.reset":       "Reset filters",
+	"logs.close":       "Close",
+	"logs.closed":      "Log browser closed.",
+	"logs.all":         "All",
+	"logs.back":        "Back",
+	"logs.ask_action":  "Show logs of which action?",
+	"logs.ask_trigger": "Show logs of which trigger?",
+	"logs.ask_resa":    "Show logs of which reservation?",
+	"logs.ask_user":    "Send the name of a user or a reservation to search for",
+	"logs.ask_date":    "Show logs of which day?",
+
+	"date.months":   "January,February,March,April,May,June,July,August,September,October,November,December",
+	"date.weekdays": "Mo,Tu,We,Th,Fr,Sa,Su",
+
+	"modify.ask_resa":      "Enter *reservation* ID",
+	"modify.ask_check_in":  "Enter check-in time (default: %s)",
+	"modify.ask_check_out": "Enter check-out time (default: %s)",
+	"modify.confirm":       "%s Do you confirm?\n*Reservation*: %s\n*Check-in*: %s\n*Check-out*: %s",
+
+	"resa.title": "Reservations:\n%s",
+	"resa.ids":   "Reservation IDs for easy copy/paste:",
+
+	"guest.welcome_linked":   "%s You are now linked to your reservation *%s*.\nUse /mycode to display your door code and /latecheckout to ask for a late check-out.",
+	"guest.link_invalid":     "This link is invalid or expired, please ask your host for a new one or use /link",
+	"guest.resa_unavailable": "This reservation is not available anymore",
+	"guest.ask_ref":          "Please send your *booking reference*",
+	"guest.ref_not_found":    "Unable to find your reservation, please check your booking reference",
+	"guest.not_linked":       "You are not linked to any reservation, use /link",
+	"guest.resa_over":        "Your reservation is over, thank you for your stay!",
+	"guest.code_unavailable": "Unable to get your code for now, please try again later",
+	"guest.code":             "%s Your door code: *%d*\nValid from %s to %s",
+	"guest.code_not_yet":     "Your code is not available yet, please try again later",
+	"guest.ask_checkout":     "What time would you like to check out?",
+	"guest.request_sent":     "Your request has been sent to your host, you will be notified of the answer.",
+	"guest.host_request":     "%s *%s* (%s) asks for a late check-out at *%s*",
+	"guest.approve":          "Approve",
+	"guest.deny":             "Deny",
+	"guest.answer_forbidden": "You are not allowed to answer this request %s",
+	"guest.already_answered": "This request has already been answered",
+	"guest.denied_host":      "Late check-out denied for %s",
+	"guest.denied_guest":     "Sorry, your host could not accept your late check-out request.",
+	"guest.approved_host":    "Late check-out at %s approved for %s",
+	"guest.approved_guest":   "%s Your late check-out at %s has been approved!",
+	"guest.ask_resa_link":    "What *reservation* do you want a guest link for?",
+	"guest.link":             "Send this one-time link to the guest of %s (valid %s):\nhttps://t.me/%s?start=%s",
+	"guest.welcome":          "%s Welcome! We hope you enjoy your stay. Use /latecheckout if you need to leave later.",
+
+	"guest.ref_already_linked": "This reservation is already linked to another guest, please ask your host for a link",
+	"guest.link_locked":        "Too many wrong booking references, please try again after %s or ask your host for a link",
+	"guest.host_linked":        "%s %s (%d) linked to reservation %s",
+	"guest.host_link_locked":   "%s %s (%d) is locked out of /link after too many wrong booking references, last one: %s",
+
+	"calendar.name":              "Nuki reservations",
+	"calendar.description":       "Reservation: %s\nGuests: %d",
+	"calendar.pending":           "Pending modification: check-in %s, check-out %s",
+	"calendar.access":            "Access: %s",
+	"calendar.first_entry":       "First entry: %s",
+	"calendar.last_exit":         "Last exit: %s",
+	"calendar.category_stay":     "Stay",
+	"calendar.category_access":   "Access",
+	"calendar.category_activity": "Activity",
+
+	"booking.conflict":                "%s Booking conflict: %s\nFeed: %s\nReference: %s\nDates: %s\nConflicts with: %s",
+	"booking.conflict_dates":          "dates differ from Nuki",
+	"booking.conflict_ambiguous":      "several reservations match",
+	"booking.conflict_overlap":        "overlaps another reservation",
+	"booking.conflict_double_booking": "double booking",
+
+	"config.reloaded":         "%s Configuration reloaded from %s\n%s",
+	"config.reload_unchanged": "%s Configuration reloaded from %s, nothing changed",
+	"config.reload_rejected":  "%s New configuration %s rejected, keeping the running one:\n%s",
+	"config.reload_restart":   "Changes only applied on restart: %s",
+
+	"drift.changed":        "%s Smartlock %s configuration changed:",
+	"drift.drifted":        "%s Smartlock %s configuration differs from the desired state:",
+	"drift.field":          "≠ %s: %v (desired: %v)",
+	"drift.enforced":       "%s Desired configuration pushed to the smartlock",
+	"drift.enforce_failed": "%s Unable to push the desired configuration: %s",
+
+	"device.current":             "%s %s\nFirmware: %s, hardware: %s\nSubscription: %s",
+	"device.subscription_since":  "%s since %s",
+	"device.no_subscription":     "none",
+	"device.history":             "History:",
+	"device.no_history":          "No history yet",
+	"device.tracked":             "%s Tracking smartlock %s, firmware %s",
+	"device.firmware":            "%s Smartlock %s firmware updated: %s → %s",
+	"device.hardware":            "%s Smartlock %s hardware changed: %s → %s",
+	"device.subscription":        "%s Smartlock %s subscription changed: %s → %s",
+	"device.subscription_expiry": "%s Smartlock %s subscription %s ends or renews on %s",
+
+	"hygiene.title":                  "%s Authorizations review: %d to check",
+	"hygiene.none":                   "%s Authorizations review: nothing to clean up",
+	"hygiene.kind.reservation_ended": "Codes of ended reservations still enabled:",
+	"hygiene.kind.unused":            "Not used for %d days:",
+	"hygiene.kind.no_expiry":         "Without expiry:",
+	"hygiene.kind.duplicate_name":    "Duplicate names:",
+	"hygiene.kind.remote_never_used": "Remote access allowed but never used:",
+	"hygiene.auth":                   "• %s (%s), last used: %s",
+	"hygiene.never":                  "never",
+	"hygiene.disabled_state":         "[disabled]",
+	"hygiene.disable":                "Disable %s",
+	"hygiene.delete":                 "Delete %s",
+	"hygiene.buttons_truncated":      "Buttons are shown for the first %d authorizations only",
+	"hygiene.disabled":               "%s Authorization %s disabled",
+	"hygiene.deleted":                "%s Authorization %s deleted",
+	"hygiene.not_found":              "Authorization %s not found, it may have been deleted already",
+	"hygiene.forbidden":              "%s Only hosts can change authorizations",
+	"hygiene.action_failed":          "%s Unable to change authorization %s: %v",
+
+	"sender.keypad_code": "%s%s %s by '%s' %s",
+	"smartlock.pretty":   "*Smartlock %s*\nBattery pack: %s (%d%%)\nKeypad: %s\nDoor sensor: %s",
+
+	"status.title":               "%s %s (%s)",
+	"status.lock_state":          "%s Lock: %s",
+	"status.door_state":          "%s Door: %s",
+	"status.mode":                "Mode: %s",
+	"status.last_action":         "Last action: %s by %s %s",
+	"status.night_mode":          "%s Night mode: %s",
+	"status.battery":             "%s Battery: %d%%%s",
+	"status.accessories_battery": "Keypad battery: %s, door sensor battery: %s",
+	"status.versions":            "Firmware: %s, hardware: %s, automatic updates: %s",
+	"status.server_state":        "%s Server: %s",
+	"status.auto_lock":           "Auto lock: %s",
+	"status.auto_lock_timeout":   "%s after %s",
+	"status.settings":            "Auto unlatch: %s, button: %s, LED: %s, pairing: %s",
+
+	"action.1":   "unlock",
+	"action.2":   "lock",
+	"action.3":   "unlatch",
+	"action.4":   "lock'n'go",
+	"action.5":   "lock'n'go with unlatch",
+	"action.208": "door warning ajar",
+	"action.209": "door warning status mismatch",
+	"action.224": "doorbell recognition (only Opener)",
+	"action.240": "door opened",
+	"action.241": "door closed",
+	"action.242": "door sensor jammed",
+	"action.243": "firmware update",
+	"action.250": "door log enabled",
+	"action.251": "door log disabled",
+	"action.252": "initialization",
+	"action.253": "calibration",
+	"action.254": "log enabled",
+	"action.255": "log disabled",
+
+	"state.0":   "Success",
+	"state.1":   "Motor blocked",
+	"state.2":   "Canceled",
+	"state.3":   "Too recent",
+	"state.4":   "Busy",
+	"state.5":   "Low motor voltage",
+	"state.6":   "Clutch failure",
+	"state.7":   "Motor power failure",
+	"state.8":   "Incomplete",
+	"state.9":   "Rejected",
+	"state.10":  "Rejected night mode",
+	"state.224": "Wrong keypad code",
+	"state.254": "Other error",
+	"state.255": "Unknown error",
+
+	"trigger.0":   "system",
+	"trigger.1":   "manual",
+	"trigger.2":   "button",
+	"trigger.3":   "automatic",
+	"trigger.4":   "web",
+	"trigger.5":   "app",
+	"trigger.6":   "auto lock",
+	"trigger.7":   "accessory",
+	"trigger.255": "keypad",
+
+	"source.0": "Default",
+	"source.1": "Keypad code",
+	"source.2": "Fingerprint",
+
+	"mode.0": "uncalibrated",
+	"mode.1": "calibration",
+	"mode.2": "door mode",
+	"mode.3": "continuous mode",
+	"mode.4": "maintenance mode",
+
+	"lock_state.0":   "uncalibrated",
+	"lock_state.1":   "locked",
+	"lock_state.2":   "unlocking",
+	"lock_state.3":   "unlocked",
+	"lock_state.4":   "locking",
+	"lock_state.5":   "unlatched",
+	"lock_state.6":   "unlocked (lock'n'go)",
+	"lock_state.7":   "unlatching",
+	"lock_state.254": "motor blocked",
+	"lock_state.255": "undefined",
+
+	"door_state.0":   "unavailable",
+	"door_state.1":   "deactivated",
+	"door_state.2":   "closed",
+	"door_state.3":   "opened",
+	"door_state.4":   "unknown",
+	"door_state.5":   "calibrating",
+	"door_state.16":  "uncalibrated",
+	"door_state.240": "removed",
+
+	"server_state.0": "ok",
+	"server_state.1": "unregistered",
+	"server_state.2": "auth uuid invalid",
+	"server_state.3": "auth invalid",
+	"server_state.4": "offline",
+}
@@ -0,0 +1,295 @@
+package i18n
+
+var es = map[string]string{
+	"lang.name": "Español",
+	"lang.ask":  "Elija su idioma",
+	"lang.set":  "Idioma configurado: %s",
+
+	"error.generic":          "Se produjo un error: %v",
+	"error.retry":            "Se produjo un error, inténtelo de nuevo",
+	"error.api_reservations": "No se pudieron obtener las reservas de la API, err=%v",
+	"error.api_smartlock":    "No se pudo leer el estado de la cerradura desde la API, err=%v",
+	"error.api_auth":         "No se pudieron obtener las autorizaciones de la API, err=%v",
+	"error.api_logs":         "No se pudieron obtener los registros de la API, err=%v",
+	"error.parse":            "No se pudo interpretar %s",
+	"error.cache_save":       "No se pudo guardar en la caché, err=%v",
+	"error.bot_info":         "No se pudo obtener la información del bot, err=%v",
+	"error.no_reservation":   "No hay ninguna reserva disponible",
+
+	"common.done":      "Hecho.",
+	"common.yes":       "Sí",
+	"common.no":        "No",
+	"common.on":        "activado",
+	"common.off":       "desactivado",
+	"common.confirmed": "¡Confirmado!",
+	"common.canceled":  "Cancelado...",
+
+	"bot.not_allowed":         "No tiene permiso para ejecutar este comando %s",
+	"bot.not_understood":      "No entiendo %s",
+	"bot.button_expired":      "Este botón ha caducado, use el menú o inicie un nuevo comando.",
+	"bot.session_expired":     "%s Sesión caducada, use el menú o inicie un nuevo comando.",
+	"bot.access_denied_admin": "%s Acceso denegado para %s %s (@%s, id=%d) al ejecutar %s",
+	"bot.help":                "Los siguientes comandos están disponibles: \n%s",
+	"bot.menu":                "Menú",
+	"bot.version":             "%s, versión %s, compilación %s",
+	"bot.modif_error":         "Se produjo un error al procesar la modificación pendiente, err=%v",
+	"bot.modif_done":          "Modificación pendiente realizada para %s (%s -> %s)",
+
+	"cmd.help":           "Mostrar la ayuda",
+	"cmd.menu":           "Mostrar el menú principal",
+	"cmd.battery":        "Mostrar el estado de las baterías",
+	"cmd.status":         "Mostrar el estado completo de la cerradura",
+	"cmd.device":         "Mostrar el historial de firmware y suscripción de la cerradura",
+	"cmd.hygiene":        "Revisar las autorizaciones obsoletas",
+	"cmd.resa":           "Listar todas las reservas",
+	"cmd.logs":           "Mostrar los registros de la cerradura Nuki",
+	"cmd.code":           "Mostrar el código de una reserva",
+	"cmd.version":        "Mostrar la versión del bot",
+	"cmd.modify":         "Modificar la entrada/salida de una reserva",
+	"cmd.listmodify":     "Listar las modificaciones pendientes",
+	"cmd.deletemodify":   "Eliminar una modificación pendiente",
+	"cmd.savemodify":     "Guardar las modificaciones en la caché",
+	"cmd.applymodify":    "Aplicar ahora las modificaciones pendientes",
+	"cmd.link":           "Vincular su cuenta a su reserva",
+	"cmd.mycode":         "Mostrar su código de acceso",
+	"cmd.latecheckout":   "Solicitar una salida tardía",
+	"cmd.guestlink":      "Generar un enlace de un solo uso para un huésped",
+	"cmd.lang":           "Cambiar el idioma del bot",
+	"cmd.importbookings": "Importar ahora las reservas de las OTA",
+
+	"menu.battery":      "Batería",
+	"menu.code":         "Código",
+	"menu.help":         "Ayuda",
+	"menu.logs":         "Registros",
+	"menu.resas":        "Reservas",
+	"menu.listmodify":   "Lista modifs",
+	"menu.modify":       "Modificar",
+	"menu.mycode":       "Mi código",
+	"menu.latecheckout": "Salida tardía",
+
+	"code.ask":       "¿Para qué *reserva* quiere el código?",
+	"code.result":    "Código para *%s*: %d",
+	"code.not_found": "No se encontró ningún código para *%s*",
+
+	"deletemodify.none": "No hay ninguna modificación pendiente",
+	"deletemodify.ask":  "¿Qué *modificación pendiente* quiere eliminar?",
+	"deletemodify.done": "Hecho\nPulse /listmodify para mostrar la nueva lista",
+	"listmodify.none":   "No hay ninguna modificación pendiente",
+
+	"logs.title":       "%s Registros, página %d",
+	"logs.filters":     "Filtros: %s",
+	"logs.none":        "No se encontraron registros",
+	"logs.newer":       "Más recientes",
+	"logs.older":       "Más antiguos",
+	"logs.action":      "Acción",
+	"logs.trigger":     "Disparador",
+	"logs.resa":        "Reserva",
+	"logs.user":        "Usuario",
+	"logs.failed":      "Solo fallos",
+	"logs.date":        "Fecha",
+	"logs.reset":       "Borrar filtros",
+	"logs.close":       "Cerrar",
+	"logs.closed":      "Navegador de registros cerrado.",
+	"logs.all":         "Todos",
+	"logs.back":        "Volver",
+	"logs.ask_action":  "¿Mostrar los registros de qué acción?",
+	"logs.ask_trigger": "¿Mostrar los registros de qué disparador?",
+	"logs.ask_resa":    "¿Mostrar los registros de qué reserva?",
+	"logs.ask_user":    "Envíe el nombre de un usuario o de una reserva a buscar",
+	"logs.ask_date":    "¿Mostrar los registros de qué día?",
+
+	"date.months":   "Enero,Febrero,Marzo,Abril,Mayo,Junio,Julio,Agosto,Septiembre,Octubre,Noviembre,Diciembre",
+	"date.weekdays": "Lu,Ma,Mi,Ju,Vi,Sá,Do",
+
+	"modify.ask_resa":      "Introduzca el ID de la *reserva*",
+	"modify.ask_check_in":  "Introduzca la hora de entrada (por defecto: %s)",
+	"modify.ask_check_out": "Introduzca la hora de salida (por defecto: %s)",
+	"modify.confirm":       "%s ¿Confirma?\n*Reserva*: %s\n*Entrada*: %s\n*Salida*: %s",
+
+	"resa.title": "Reservas:\n%s",
+	"resa.ids":   "IDs de reserva para copiar/pegar:",
+
+	"guest.welcome_linked":   "%s Ahora está vinculado a su reserva *%s*.\nUse /mycode para ver su código de acceso y /latecheckout para solicitar una salida tardía.",
+	"guest.link_invalid":     "Este enlace no es válido o ha caducado, pida uno nuevo a su anfitrión o use /link",
+	"guest.resa_unavailable": "Esta reserva ya no está disponible",
+	"guest.ask_ref":          "Envíe su *referencia de reserva*",
+	"guest.ref_not_found":    "No se encontró su reserva, compruebe su referencia",
+	"guest.not_linked":       "No está vinculado a ninguna reserva, use /link",
+	"guest.resa_over":        "Su reserva ha terminado, ¡gracias por su estancia!",
+	"guest.code_unavailable": "No se puede obtener su código por ahora, inténtelo más tarde",
+	"guest.code":             "%s Su código de acceso: *%d*\nVálido del %s al %s",
+	"guest.code_not_yet":     "Su código aún no está disponible, inténtelo más tarde",
+	"guest.ask_checkout":     "¿A qué hora desea salir?",
+	"guest.request_sent":     "Su solicitud se ha enviado a su anfitrión, se le notificará la respuesta.",
+	"guest.host_request":     "%s *%s* (%s) solicita una salida tardía a las *%s*",
+	"guest.approve":          "Aceptar",
+	"guest.deny":             "Rechazar",
+	"guest.answer_forbidden": "No tiene permiso para responder a esta solicitud %s",
+	"guest.already_answered": "Esta solicitud ya ha sido respondida",
+	"guest.denied_host":      "Salida tardía rechazada para %s",
+	"guest.denied_guest":     "Lo sentimos, su anfitrión no pudo aceptar su solicitud de salida tardía.",
+	"guest.approved_host":    "Salida tardía a las %s aceptada para %s",
+	"guest.approved_guest":   "%s ¡Su salida tardía a las %s ha sido aceptada!",
+	"guest.ask_resa_link":    "¿Para qué *reserva* quiere un enlace de huésped?",
+	"guest.link":             "Envíe este enlace de un solo uso al huésped de %s (válido %s):\nhttps://t.me/%s?start=%s",
+	"guest.welcome":          "%s ¡Bienvenido! Le deseamos una excelente estancia. Use /latecheckout si necesita salir más tarde.",
+
+	"guest.ref_already_linked": "Esta reserva ya está vinculada a otro huésped, pida un enlace a su anfitrión",
+	"guest.link_locked":        "Demasiadas referencias incorrectas, inténtelo de nuevo después de las %s o pida un enlace a su anfitrión",
+	"guest.host_linked":        "%s %s (%d) se ha vinculado a la reserva %s",
+	"guest.host_link_locked":   "%s %s (%d) está bloqueado en /link tras demasiadas referencias incorrectas, última: %s",
+
+	"calendar.name":              "Reservas Nuki",
+	"calendar.description":       "Reserva: %s\nHuéspedes: %d",
+	"calendar.pending":           "Modificación pendiente: entrada %s, salida %s",
+	"calendar.access":            "Acceso: %s",
+	"calendar.first_entry":       "Primera entrada: %s",
+	"calendar.last_exit":         "Última salida: %s",
+	"calendar.category_stay":     "Estancia",
+	"calendar.category_access":   "Acceso",
+	"calendar.category_activity": "Actividad",
+
+	"booking.conflict":                "%s Conflicto de reserva: %s\nCanal: %s\nReferencia: %s\nFechas: %s\nEn conflicto con: %s",
+	"booking.conflict_dates":          "fechas distintas de Nuki",
+	"booking.conflict_ambiguous":      "varias reservas coinciden",
+	"booking.conflict_overlap":        "se solapa con otra reserva",
+	"booking.conflict_double_booking": "reserva duplicada",
+
+	"config.reloaded":         "%s Configuración recargada desde %s\n%s",
+	"config.reload_unchanged": "%s Configuración recargada desde %s, sin cambios",
+	"config.reload_rejected":  "%s Nueva configuración %s rechazada, se mantiene la actual:\n%s",
+	"config.reload_restart":   "Cambios aplicados solo al reiniciar: %s",
+
+	"drift.changed":        "%s La configuración de la cerradura %s ha cambiado:",
+	"drift.drifted":        "%s La configuración de la cerradura %s difiere del estado deseado:",
+	"drift.field":          "≠ %s: %v (deseado: %v)",
+	"drift.enforced":       "%s Configuración deseada enviada a la cerradura",
+	"drift.enforce_failed": "%s No se puede enviar la configuración deseada: %s",
+
+	"device.current":             "%s %s\nFirmware: %s, hardware: %s\nSuscripción: %s",
+	"device.subscription_since":  "%s desde el %s",
+	"device.no_subscription":     "ninguna",
+	"device.history":             "Historial:",
+	"device.no_history":          "Aún no hay historial",
+	"device.tracked":             "%s Seguimiento de la cerradura %s, firmware %s",
+	"device.firmware":            "%s Firmware de la cerradura %s actualizado: %s → %s",
+	"device.hardware":            "%s Hardware de la cerradura %s cambiado: %s → %s",
+	"device.subscription":        "%s Suscripción de la cerradura %s cambiada: %s → %s",
+	"device.subscription_expiry": "%[1]s La suscripción %[3]s de la cerradura %[2]s termina o se renueva el %[4]s",
+
+	"hygiene.title":                  "%s Revisión de autorizaciones: %d por revisar",
+	"hygiene.none":                   "%s Revisión de autorizaciones: nada que limpiar",
+	"hygiene.kind.reservation_ended": "Códigos de reservas terminadas aún activos:",
+	"hygiene.kind.unused":            "Sin usar desde hace %d días:",
+	"hygiene.kind.no_expiry":         "Sin caducidad:",
+	"hygiene.kind.duplicate_name":    "Nombres duplicados:",
+	"hygiene.kind.remote_never_used": "Acceso remoto permitido pero nunca usado:",
+	"hygiene.auth":                   "• %s (%s), último uso: %s",
+	"hygiene.never":                  "nunca",
+	"hygiene.disabled_state":         "[desactivada]",
+	"hygiene.disable":                "Desactivar %s",
+	"hygiene.delete":                 "Eliminar %s",
+	"hygiene.buttons_truncated":      "Los botones solo se muestran para las %d primeras autorizaciones",
+	"hygiene.disabled":               "%s Autorización %s desactivada",
+	"hygiene.deleted":                "%s Autorización %s eliminada",
+	"hygiene.not_found":              "Autorización %s no encontrada, puede que ya se haya eliminado",
+	"hygiene.forbidden":              "%s Solo los anfitriones pueden modificar las autorizaciones",
+	"hygiene.action_failed":          "%s No se puede modificar la autorización %s: %v",
+
+	"sender.keypad_code": "%s%s %s por '%s' %s",
+	"smartlock.pretty":   "*Cerradura %s*\nBatería: %s (%d%%)\nTeclado: %s\nSensor de puerta: %s",
+
+	"status.title":               "%s %s (%s)",
+	"status.lock_state":          "%s Cerradura: %s",
+	"status.door_state":          "%s Puerta: %s",
+	"status.mode":                "Modo: %s",
+	"status.last_action":         "Última acción: %s por %s %s",
+	"status.night_mode":          "%s Modo nocturno: %s",
+	"status.battery":             "%s Batería: %d%%%s",
+	"status.accessories_battery": "Batería del teclado: %s, batería del sensor de puerta: %s",
+	"status.versions":            "Firmware: %s, hardware: %s, actualizaciones automáticas: %s",
+	"status.server_state":        "%s Servidor: %s",
+	"status.auto_lock":           "Bloqueo automático: %s",
+	"status.auto_lock_timeout":   "%s después de %s",
+	"status.settings":            "Apertura automática: %s, botón: %s, LED: %s, emparejamiento: %s",
+
+	"action.1":   "desbloqueo",
+	"action.2":   "bloqueo",
+	"action.3":   "apertura",
+	"action.4":   "lock'n'go",
+	"action.5":   "lock'n'go con apertura",
+	"action.208": "aviso de puerta entreabierta",
+	"action.209": "aviso de estado de puerta incoherente",
+	"action.224": "reconocimiento de timbre (solo Opener)",
+	"action.240": "puerta abierta",
+	"action.241": "puerta cerrada",
+	"action.242": "sensor de puerta atascado",
+	"action.243": "actualización de firmware",
+	"action.250": "registro de puerta activado",
+	"action.251": "registro de puerta desactivado",
+	"action.252": "inicialización",
+	"action.253": "calibración",
+	"action.254": "registro activado",
+	"action.255": "registro desactivado",
+
+	"state.0":   "Éxito",
+	"state.1":   "Motor bloqueado",
+	"state.2":   "Cancelado",
+	"state.3":   "Demasiado reciente",
+	"state.4":   "Ocupado",
+	"state.5":   "Tensión del motor baja",
+	"state.6":   "Fallo del embrague",
+	"state.7":   "Fallo de alimentación del motor",
+	"state.8":   "Incompleto",
+	"state.9":   "Rechazado",
+	"state.10":  "Rechazado (modo nocturno)",
+	"state.224": "Código de teclado incorrecto",
+	"state.254": "Otro error",
+	"state.255": "Error desconocido",
+
+	"trigger.0":   "sistema",
+	"trigger.1":   "manual",
+	"trigger.2":   "botón",
+	"trigger.3":   "automático",
+	"trigger.4":   "web",
+	"trigger.5":   "aplicación",
+	"trigger.6":   "bloqueo automático",
+	"trigger.7":   "accesorio",
+	"trigger.255": "teclado",
+
+	"source.0": "Predeterminado",
+	"source.1": "Código de teclado",
+	"source.2": "Huella digital",
+
+	"mode.0": "sin calibrar",
+	"mode.1": "calibración",
+	"mode.2": "modo puerta",
+	"mode.3": "modo continuo",
+	"mode.4": "modo mantenimiento",
+
+	"lock_state.0":   "sin calibrar",
+	"lock_state.1":   "bloqueada",
+	"lock_state.2":   "desbloqueando",
+	"lock_state.3":   "desbloqueada",
+	"lock_state.4":   "bloqueando",
+	"lock_state.5":   "pestillo abierto",
+	"lock_state.6":   "desbloqueada (lock'n'go)",
+	"lock_state.7":   "abriendo pestillo",
+	"lock_state.254": "motor bloqueado",
+	"lock_state.255": "indefinido",
+
+	"door_state.0":   "no disponible",
+	"door_state.1":   "desactivado",
+	"door_state.2":   "cerrada",
+	"door_state.3":   "abierta",
+	"door_state.4":   "desconocido",
+	"door_state.5":   "calibrando",
+	"door_state.16":  "sin calibrar",
+	"door_state.240": "retirado",
+
+	"server_state.0": "ok",
+	"server_state.1": "no registrada",
+	"server_state.2": "uuid de autenticación no válido",
+	"server_state.3": "autenticación no válida",
+	"server_state.4": "sin conexión",
+}
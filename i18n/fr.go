@@ -0,0 +1,295 @@
+package i18n
+
+var fr = map[string]string{
+	"lang.name": "Français",
+	"lang.ask":  "Choisissez votre langue",
+	"lang.set":  "Langue définie : %s",
+
+	"error.generic":          "Une erreur est survenue : %v",
+	"error.retry":            "Une erreur est survenue, merci de réessayer",
+	"error.api_reservations": "Impossible de récupérer les réservations depuis l'API, err=%v",
+	"error.api_smartlock":    "Impossible de lire l'état de la serrure depuis l'API, err=%v",
+	"error.api_auth":         "Impossible de récupérer les autorisations depuis l'API, err=%v",
+	"error.api_logs":         "Impossible de récupérer les logs depuis l'API, err=%v",
+	"error.parse":            "Impossible d'interpréter %s",
+	"error.cache_save":       "Impossible de sauvegarder dans le cache, err=%v",
+	"error.bot_info":         "Impossible de récupérer les informations du bot, err=%v",
+	"error.no_reservation":   "Aucune réservation disponible",
+
+	"common.done":      "Terminé.",
+	"common.yes":       "Oui",
+	"common.no":        "Non",
+	"common.on":        "activé",
+	"common.off":       "désactivé",
+	"common.confirmed": "Confirmé !",
+	"common.canceled":  "Annulé...",
+
+	"bot.not_allowed":         "Vous n'êtes pas autorisé à lancer cette commande %s",
+	"bot.not_understood":      "Je ne comprends pas %s",
+	"bot.button_expired":      "Ce bouton a expiré, utilisez le menu ou lancez une nouvelle commande !",
+	"bot.session_expired":     "%s Session expirée, utilisez le menu ou lancez une nouvelle commande !",
+	"bot.access_denied_admin": "%s Accès refusé pour %s %s (@%s, id=%d) lançant %s",
+	"bot.help":                "Les commandes suivantes sont disponibles : \n%s",
+	"bot.menu":                "Menu",
+	"bot.version":             "%s, version %s, build %s",
+	"bot.modif_error":         "Une erreur est survenue lors du traitement de la modification en attente, err=%v",
+	"bot.modif_done":          "Modification en attente effectuée pour %s (%s -> %s)",
+
+	"cmd.help":           "Afficher l'aide",
+	"cmd.menu":           "Afficher le menu principal",
+	"cmd.battery":        "Afficher l'état des batteries",
+	"cmd.status":         "Afficher l'état complet de la serrure",
+	"cmd.device":         "Afficher l'historique du firmware et de l'abonnement de la serrure",
+	"cmd.hygiene":        "Revoir les autorisations obsolètes",
+	"cmd.resa":           "Lister toutes les réservations",
+	"cmd.logs":           "Afficher les logs de la serrure Nuki",
+	"cmd.code":           "Afficher le code d'une réservation",
+	"cmd.version":        "Afficher la version du bot",
+	"cmd.modify":         "Modifier l'arrivée/le départ d'une réservation",
+	"cmd.listmodify":     "Lister les modifications en attente",
+	"cmd.deletemodify":   "Supprimer une modification en attente",
+	"cmd.savemodify":     "Sauvegarder les modifications dans le cache",
+	"cmd.applymodify":    "Appliquer maintenant les modifications en attente",
+	"cmd.link":           "Lier votre compte à votre réservation",
+	"cmd.mycode":         "Afficher votre code d'accès",
+	"cmd.latecheckout":   "Demander un départ tardif",
+	"cmd.guestlink":      "Générer un lien à usage unique pour un voyageur",
+	"cmd.lang":           "Changer la langue du bot",
+	"cmd.importbookings": "Importer maintenant les réservations des OTA",
+
+	"menu.battery":      "Batterie",
+	"menu.code":         "Code",
+	"menu.help":         "Aide",
+	"menu.logs":         "Logs",
+	"menu.resas":        "Résas",
+	"menu.listmodify":   "Liste modifs",
+	"menu.modify":       "Modifier",
+	"menu.mycode":       "Mon code",
+	"menu.latecheckout": "Départ tardif",
+
+	"code.ask":       "Pour quelle *réservation* voulez-vous le code ?",
+	"code.result":    "Code pour *%s* : %d",
+	"code.not_found": "Impossible de trouver un code pour *%s*",
+
+	"deletemodify.none": "Aucune modification en attente",
+	"deletemodify.ask":  "Quelle *modification en attente* voulez-vous supprimer ?",
+	"deletemodify.done": "Terminé\nCliquez sur /listmodify pour afficher la nouvelle liste",
+	"listmodify.none":   "Aucune modification en attente",
+
+	"logs.title":       "%s Logs, page %d",
+	"logs.filters":     "Filtres : %s",
+	"logs.none":        "Aucun log trouvé",
+	"logs.newer":       "Plus récents",
+	"logs.older":       "Plus anciens",
+	"logs.action":      "Action",
+	"logs.trigger":     "Déclencheur",
+	"logs.resa":        "Réservation",
+	"logs.user":        "Utilisateur",
+	"logs.failed":      "Échecs uniquement",
+	"logs.date":        "Date",
+	"logs.reset":       "Réinitialiser",
+	"logs.close":       "Fermer",
+	"logs.closed":      "Navigateur de logs fermé.",
+	"logs.all":         "Tous",
+	"logs.back":        "Retour",
+	"logs.ask_action":  "Afficher les logs de quelle action ?",
+	"logs.ask_trigger": "Afficher les logs de quel déclencheur ?",
+	"logs.ask_resa":    "Afficher les logs de quelle réservation ?",
+	"logs.ask_user":    "Envoyez le nom d'un utilisateur ou d'une réservation à rechercher",
+	"logs.ask_date":    "Afficher les logs de quel jour ?",
+
+	"date.months":   "Janvier,Février,Mars,Avril,Mai,Juin,Juillet,Août,Septembre,Octobre,Novembre,Décembre",
+	"date.weekdays": "Lu,Ma,Me,Je,Ve,Sa,Di",
+
+	"modify.ask_resa":      "Entrez l'identifiant de la *réservation*",
+	"modify.ask_check_in":  "Entrez l'heure d'arrivée (par défaut : %s)",
+	"modify.ask_check_out": "Entrez l'heure de départ (par défaut : %s)",
+	"modify.confirm":       "%s Confirmez-vous ?\n*Réservation* : %s\n*Arrivée* : %s\n*Départ* : %s",
+
+	"resa.title": "Réservations :\n%s",
+	"resa.ids":   "Identifiants des réservations pour copier/coller :",
+
+	"guest.welcome_linked":   "%s Vous êtes maintenant lié à votre réservation *%s*.\nUtilisez /mycode pour afficher votre code d'accès et /latecheckout pour demander un départ tardif.",
+	"guest.link_invalid":     "Ce lien est invalide ou expiré, demandez-en un nouveau à votre hôte ou utilisez /link",
+	"guest.resa_unavailable": "Cette réservation n'est plus disponible",
+	"guest.ask_ref":          "Merci d'envoyer votre *référence de réservation*",
+	"guest.ref_not_found":    "Impossible de trouver votre réservation, vérifiez votre référence",
+	"guest.not_linked":       "Vous n'êtes lié à aucune réservation, utilisez /link",
+	"guest.resa_over":        "Votre réservation est terminée, merci pour votre séjour !",
+	"guest.code_unavailable": "Impossible de récupérer votre code pour le moment, réessayez plus tard",
+	"guest.code":             "%s Votre code d'accès : *%d*\nValable du %s au %s",
+	"guest.code_not_yet":     "Votre code n'est pas encore disponible, réessayez plus tard",
+	"guest.ask_checkout":     "À quelle heure souhaitez-vous partir ?",
+	"guest.request_sent":     "Votre demande a été envoyée à votre hôte, vous serez notifié de sa réponse.",
+	"guest.host_request":     "%s *%s* (%s) demande un départ tardif à *%s*",
+	"guest.approve":          "Accepter",
+	"guest.deny":             "Refuser",
+	"guest.answer_forbidden": "Vous n'êtes pas autorisé à répondre à cette demande %s",
+	"guest.already_answered": "Cette demande a déjà reçu une réponse",
+	"guest.denied_host":      "Départ tardif refusé pour %s",
+	"guest.denied_guest":     "Désolé, votre hôte n'a pas pu accepter votre demande de départ tardif.",
+	"guest.approved_host":    "Départ tardif à %s accepté pour %s",
+	"guest.approved_guest":   "%s Votre départ tardif à %s a été accepté !",
+	"guest.ask_resa_link":    "Pour quelle *réservation* voulez-vous un lien voyageur ?",
+	"guest.link":             "Envoyez ce lien à usage unique au voyageur de %s (valable %s) :\nhttps://t.me/%s?start=%s",
+	"guest.welcome":          "%s Bienvenue ! Nous vous souhaitons un excellent séjour. Utilisez /latecheckout si vous souhaitez partir plus tard.",
+
+	"guest.ref_already_linked": "Cette réservation est déjà liée à un autre voyageur, demandez un lien à votre hôte",
+	"guest.link_locked":        "Trop de références erronées, réessayez après %s ou demandez un lien à votre hôte",
+	"guest.host_linked":        "%s %s (%d) s'est lié à la réservation %s",
+	"guest.host_link_locked":   "%s %s (%d) est bloqué sur /link après trop de références erronées, dernière : %s",
+
+	"calendar.name":              "Réservations Nuki",
+	"calendar.description":       "Réservation : %s\nVoyageurs : %d",
+	"calendar.pending":           "Modification en attente : arrivée %s, départ %s",
+	"calendar.access":            "Accès : %s",
+	"calendar.first_entry":       "Première entrée : %s",
+	"calendar.last_exit":         "Dernière sortie : %s",
+	"calendar.category_stay":     "Séjour",
+	"calendar.category_access":   "Accès",
+	"calendar.category_activity": "Activité",
+
+	"booking.conflict":                "%s Conflit de réservation : %s\nFlux : %s\nRéférence : %s\nDates : %s\nEn conflit avec : %s",
+	"booking.conflict_dates":          "dates différentes de Nuki",
+	"booking.conflict_ambiguous":      "plusieurs réservations correspondent",
+	"booking.conflict_overlap":        "chevauche une autre réservation",
+	"booking.conflict_double_booking": "double réservation",
+
+	"config.reloaded":         "%s Configuration rechargée depuis %s\n%s",
+	"config.reload_unchanged": "%s Configuration rechargée depuis %s, aucun changement",
+	"config.reload_rejected":  "%s Nouvelle configuration %s rejetée, la configuration en cours est conservée :\n%s",
+	"config.reload_restart":   "Changements appliqués uniquement au redémarrage : %s",
+
+	"drift.changed":        "%s La configuration de la serrure %s a changé :",
+	"drift.drifted":        "%s La configuration de la serrure %s diffère de l'état souhaité :",
+	"drift.field":          "≠ %s : %v (souhaité : %v)",
+	"drift.enforced":       "%s Configuration souhaitée envoyée à la serrure",
+	"drift.enforce_failed": "%s Impossible d'envoyer la configuration souhaitée : %s",
+
+	"device.current":             "%s %s\nFirmware : %s, matériel : %s\nAbonnement : %s",
+	"device.subscription_since":  "%s depuis le %s",
+	"device.no_subscription":     "aucun",
+	"device.history":             "Historique :",
+	"device.no_history":          "Pas encore d'historique",
+	"device.tracked":             "%s Suivi de la serrure %s, firmware %s",
+	"device.firmware":            "%s Firmware de la serrure %s mis à jour : %s → %s",
+	"device.hardware":            "%s Matériel de la serrure %s changé : %s → %s",
+	"device.subscription":        "%s Abonnement de la serrure %s changé : %s → %s",
+	"device.subscription_expiry": "%[1]s L'abonnement %[3]s de la serrure %[2]s se termine ou se renouvelle le %[4]s",
+
+	"hygiene.title":                  "%s Revue des autorisations : %d à vérifier",
+	"hygiene.none":                   "%s Revue des autorisations : rien à nettoyer",
+	"hygiene.kind.reservation_ended": "Codes de réservations terminées encore actifs :",
+	"hygiene.kind.unused":            "Inutilisées depuis %d jours :",
+	"hygiene.kind.no_expiry":         "Sans expiration :",
+	"hygiene.kind.duplicate_name":    "Noms en double :",
+	"hygiene.kind.remote_never_used": "Accès à distance autorisé mais jamais utilisé :",
+	"hygiene.auth":                   "• %s (%s), dernière utilisation : %s",
+	"hygiene.never":                  "jamais",
+	"hygiene.disabled_state":         "[désactivée]",
+	"hygiene.disable":                "Désactiver %s",
+	"hygiene.delete":                 "Supprimer %s",
+	"hygiene.buttons_truncated":      "Les boutons ne sont affichés que pour les %d premières autorisations",
+	"hygiene.disabled":               "%s Autorisation %s désactivée",
+	"hygiene.deleted":                "%s Autorisation %s supprimée",
+	"hygiene.not_found":              "Autorisation %s introuvable, elle a peut-être déjà été supprimée",
+	"hygiene.forbidden":              "%s Seuls les hôtes peuvent modifier les autorisations",
+	"hygiene.action_failed":          "%s Impossible de modifier l'autorisation %s : %v",
+
+	"sender.keypad_code": "%s%s %s par '%s' %s",
+	"smartlock.pretty":   "*Serrure %s*\nBatterie : %s (%d%%)\nClavier : %s\nCapteur de porte : %s",
+
+	"status.title":               "%s %s (%s)",
+	"status.lock_state":          "%s Serrure : %s",
+	"status.door_state":          "%s Porte : %s",
+	"status.mode":                "Mode : %s",
+	"status.last_action":         "Dernière action : %s par %s %s",
+	"status.night_mode":          "%s Mode nuit : %s",
+	"status.battery":             "%s Batterie : %d%%%s",
+	"status.accessories_battery": "Batterie du clavier : %s, batterie du capteur de porte : %s",
+	"status.versions":            "Firmware : %s, matériel : %s, mises à jour automatiques : %s",
+	"status.server_state":        "%s Serveur : %s",
+	"status.auto_lock":           "Verrouillage automatique : %s",
+	"status.auto_lock_timeout":   "%s après %s",
+	"status.settings":            "Déverrouillage automatique : %s, bouton : %s, LED : %s, appairage : %s",
+
+	"action.1":   "déverrouillage",
+	"action.2":   "verrouillage",
+	"action.3":   "ouverture",
+	"action.4":   "lock'n'go",
+	"action.5":   "lock'n'go avec ouverture",
+	"action.208": "alerte porte entrouverte",
+	"action.209": "alerte incohérence d'état de la porte",
+	"action.224": "reconnaissance de sonnette (Opener uniquement)",
+	"action.240": "porte ouverte",
+	"action.241": "porte fermée",
+	"action.242": "capteur de porte bloqué",
+	"action.243": "mise à jour du firmware",
+	"action.250": "journal de porte activé",
+	"action.251": "journal de porte désactivé",
+	"action.252": "initialisation",
+	"action.253": "calibration",
+	"action.254": "journal activé",
+	"action.255": "journal désactivé",
+
+	"state.0":   "Succès",
+	"state.1":   "Moteur bloqué",
+	"state.2":   "Annulé",
+	"state.3":   "Trop récent",
+	"state.4":   "Occupé",
+	"state.5":   "Tension moteur faible",
+	"state.6":   "Défaillance de l'embrayage",
+	"state.7":   "Défaillance d'alimentation du moteur",
+	"state.8":   "Incomplet",
+	"state.9":   "Rejeté",
+	"state.10":  "Rejeté (mode nuit)",
+	"state.224": "Mauvais code clavier",
+	"state.254": "Autre erreur",
+	"state.255": "Erreur inconnue",
+
+	"trigger.0":   "système",
+	"trigger.1":   "manuel",
+	"trigger.2":   "bouton",
+	"trigger.3":   "automatique",
+	"trigger.4":   "web",
+	"trigger.5":   "application",
+	"trigger.6":   "verrouillage auto",
+	"trigger.7":   "accessoire",
+	"trigger.255": "clavier",
+
+	"source.0": "Défaut",
+	"source.1": "Code clavier",
+	"source.2": "Empreinte digitale",
+
+	"mode.0": "non calibrée",
+	"mode.1": "calibration",
+	"mode.2": "mode porte",
+	"mode.3": "mode continu",
+	"mode.4": "mode maintenance",
+
+	"lock_state.0":   "non calibrée",
+	"lock_state.1":   "verrouillée",
+	"lock_state.2":   "déverrouillage",
+	"lock_state.3":   "déverrouillée",
+	"lock_state.4":   "verrouillage",
+	"lock_state.5":   "pêne ouvert",
+	"lock_state.6":   "déverrouillée (lock'n'go)",
+	"lock_state.7":   "ouverture du pêne",
+	"lock_state.254": "moteur bloqué",
+	"lock_state.255": "indéfini",
+
+	"door_state.0":   "indisponible",
+	"door_state.1":   "désactivé",
+	"door_state.2":   "fermée",
+	"door_state.3":   "ouverte",
+	"door_state.4":   "inconnu",
+	"door_state.5":   "calibration",
+	"door_state.16":  "non calibré",
+	"door_state.240": "retiré",
+
+	"server_state.0": "ok",
+	"server_state.1": "non enregistrée",
+	"server_state.2": "uuid d'authentification invalide",
+	"server_state.3": "authentification invalide",
+	"server_state.4": "hors ligne",
+}
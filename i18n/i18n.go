@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	English         = "en"
+	French          = "fr"
+	German          = "de"
+	Spanish         = "es"
+	DefaultLanguage = English
+)
+
+var (
+	catalogues = map[string]map[string]string{
+		English: en,
+		French:  fr,
+		German:  de,
+		Spanish: es,
+	}
+)
+
+// Languages returns all supported languages
+func Languages() []string {
+	langs := maps.Keys(catalogues)
+	slices.Sort(langs)
+	return langs
+}
+
+// IsSupported returns true if a catalogue exists for lang
+func IsSupported(lang string) bool {
+	_, ok := catalogues[lang]
+	return ok
+}
+
+// Normalize converts a language code (e.g. fr-FR as sent by Telegram) to a supported language
+func Normalize(code string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(code), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	if IsSupported(lang) {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Has returns true if key exists in the catalogue of lang
+func Has(lang, key string) bool {
+	_, ok := catalogues[Normalize(lang)][key]
+	return ok
+}
+
+// T returns the message associated to key in the given language, formatted with args.
+// It falls back to the default language and then to the key itself.
+func T(lang, key string, args ...any) string {
+	msg, ok := catalogues[Normalize(lang)][key]
+	if !ok {
+		msg, ok = catalogues[DefaultLanguage][key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Validate checks that every key exists in every catalogue
+func Validate() error {
+	allKeys := map[string]struct{}{}
+	for _, c := range catalogues {
+		for k := range c {
+			allKeys[k] = struct{}{}
+		}
+	}
+
+	var missing []string
+	for _, lang := range Languages() {
+		for k := range allKeys {
+			if _, ok := catalogues[lang][k]; !ok {
+				missing = append(missing, fmt.Sprintf("%s:%s", lang, k))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("missing translations: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
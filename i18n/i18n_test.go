@@ -0,0 +1,133 @@
+package i18n
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// formatVerbs returns the verb used for each argument of a format string, by argument index starting at 1.
+// Explicit indices (e.g. %[2]s) are handled, the following verbs using the next arguments as fmt does.
+func formatVerbs(format string) (map[int]byte, error) {
+	verbs := make(map[int]byte)
+	arg := 1
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		for i < len(format) && (format[i] == '[' || format[i] == '.' || (format[i] >= '0' && format[i] <= '9')) {
+			if format[i] != '[' {
+				i++
+				continue
+			}
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return nil, strconv.ErrSyntax
+			}
+			n, err := strconv.Atoi(format[i+1 : i+end])
+			if err != nil || n < 1 {
+				return nil, strconv.ErrSyntax
+			}
+			arg = n
+			i += end + 1
+		}
+		if i >= len(format) {
+			return nil, strconv.ErrSyntax
+		}
+		if format[i] == '%' {
+			continue
+		}
+		if v, ok := verbs[arg]; ok && v != format[i] {
+			return nil, strconv.ErrSyntax
+		}
+		verbs[arg] = format[i]
+		arg++
+	}
+	return verbs, nil
+}
+
+func TestCataloguesHaveEveryKey(t *testing.T) {
+	keys := make(map[string]bool)
+	for _, c := range catalogues {
+		for key := range c {
+			keys[key] = true
+		}
+	}
+	for _, lang := range Languages() {
+		for key := range keys {
+			if _, ok := catalogues[lang][key]; !ok {
+				t.Errorf("%s: missing key %s", lang, key)
+			}
+		}
+	}
+	if err := Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestCataloguesFormatVerbs(t *testing.T) {
+	for key, msg := range catalogues[DefaultLanguage] {
+		want, err := formatVerbs(msg)
+		if err != nil {
+			t.Errorf("%s:%s: invalid format %q", DefaultLanguage, key, msg)
+			continue
+		}
+		for _, lang := range Languages() {
+			translation, ok := catalogues[lang][key]
+			if !ok {
+				continue
+			}
+			got, err := formatVerbs(translation)
+			if err != nil {
+				t.Errorf("%s:%s: invalid format %q", lang, key, translation)
+				continue
+			}
+			if !maps.Equal(got, want) {
+				t.Errorf("%s:%s: verbs %s, want %s as in %s", lang, key, formatVerbsString(got), formatVerbsString(want), DefaultLanguage)
+			}
+		}
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   map[int]byte
+	}{
+		{format: "no verb", want: map[int]byte{}},
+		{format: "100%% done", want: map[int]byte{}},
+		{format: "%s and %d", want: map[int]byte{1: 's', 2: 'd'}},
+		{format: "%[2]s before %[1]d", want: map[int]byte{1: 'd', 2: 's'}},
+		{format: "%[2]s then %s", want: map[int]byte{2: 's', 3: 's'}},
+		{format: "%-10s %.2f %05d", want: map[int]byte{1: 's', 2: 'f', 3: 'd'}},
+	}
+	for _, tt := range tests {
+		got, err := formatVerbs(tt.format)
+		if err != nil {
+			t.Errorf("formatVerbs(%q) error = %v", tt.format, err)
+			continue
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("formatVerbs(%q) = %s, want %s", tt.format, formatVerbsString(got), formatVerbsString(tt.want))
+		}
+	}
+}
+
+func formatVerbsString(verbs map[int]byte) string {
+	args := make([]int, 0, len(verbs))
+	for arg := range verbs {
+		args = append(args, arg)
+	}
+	slices.Sort(args)
+	res := make([]string, 0, len(args))
+	for _, arg := range args {
+		res = append(res, "%["+strconv.Itoa(arg)+"]"+string(verbs[arg]))
+	}
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,37 @@
+package lifecycle
+
+import (
+	"errors"
+	"time"
+)
+
+const DefaultSubscriptionWarnBefore = time.Hour * 24 * 30
+
+// Config configures the tracking of the smartlock's firmware, hardware and subscription
+type Config struct {
+	// Enabled records a device history on each smartlock check, notifying senders of changes
+	Enabled bool `mapstructure:"enabled"`
+	// SubscriptionPeriod is the subscription's billing period, which ends or renews every period from its creation.
+	// The API not giving the end of subscriptions, no warning is sent before it when not set.
+	SubscriptionPeriod time.Duration `mapstructure:"subscription_period"`
+	// SubscriptionWarnBefore is how long before the end of a period to warn
+	SubscriptionWarnBefore time.Duration `mapstructure:"subscription_warn_before"`
+}
+
+func (c Config) GetSubscriptionWarnBefore() time.Duration {
+	if c.SubscriptionWarnBefore <= 0 {
+		return DefaultSubscriptionWarnBefore
+	}
+	return c.SubscriptionWarnBefore
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.SubscriptionPeriod < 0 {
+		errs = append(errs, errors.New("subscription_period cannot be negative"))
+	}
+	if c.SubscriptionPeriod > 0 && c.GetSubscriptionWarnBefore() >= c.SubscriptionPeriod {
+		errs = append(errs, errors.New("subscription_warn_before must be shorter than subscription_period"))
+	}
+	return errors.Join(errs...)
+}
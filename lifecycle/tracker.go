@@ -0,0 +1,176 @@
+package lifecycle
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	historyCacheKey = "device-history"
+	// historyMaxEvents is the number of events kept in the history
+	historyMaxEvents = 200
+)
+
+// state is saved to the cache so that changes made while the server is stopped are reported
+type state struct {
+	Tracked                  bool      `json:"tracked"`
+	FirmwareVersion          int       `json:"firmware_version"`
+	HardwareVersion          int       `json:"hardware_version"`
+	Subscription             string    `json:"subscription"`
+	SubscriptionCreationDate time.Time `json:"subscription_creation_date"`
+	// WarnedEnd is the end of the subscription's period already warned about
+	WarnedEnd time.Time           `json:"warned_end"`
+	Events    []model.DeviceEvent `json:"events"`
+}
+
+// Tracker records the changes of the smartlock's firmware, hardware and subscription
+type Tracker struct {
+	mutex  sync.Mutex
+	config Config
+	cache  cache.Cache
+	state  state
+}
+
+func NewTracker(config Config, historyCache cache.Cache) *Tracker {
+	t := &Tracker{config: config, cache: historyCache}
+	if err := t.load(); err != nil && !errors.Is(err, cache.ErrCacheNoClient) {
+		log.Error().Err(err).Msg("Unable to load device history from cache")
+	}
+	return t
+}
+
+func (t *Tracker) SetConfig(config Config) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.config = config
+}
+
+// Events returns the device history, oldest first
+func (t *Tracker) Events() []model.DeviceEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]model.DeviceEvent(nil), t.state.Events...)
+}
+
+// Check records the changes of s since the previous check, returning the events to notify
+func (t *Tracker) Check(s model.SmartlockResponse, now time.Time) []model.DeviceEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.config.Enabled {
+		return nil
+	}
+
+	subscription := s.FormatSubscription()
+	var events []model.DeviceEvent
+	newEvent := func(kind model.DeviceEventKind, old, new string) {
+		events = append(events, model.DeviceEvent{Date: now, Kind: kind, Name: s.Name, Old: old, New: new})
+	}
+	if t.state.Tracked {
+		if s.FirmwareVersion != t.state.FirmwareVersion {
+			newEvent(model.DeviceEventFirmware,
+				model.FormatFirmwareVersion(t.state.FirmwareVersion), model.FormatFirmwareVersion(s.FirmwareVersion))
+		}
+		if s.HardwareVersion != t.state.HardwareVersion {
+			newEvent(model.DeviceEventHardware,
+				model.FormatHardwareVersion(t.state.HardwareVersion), model.FormatHardwareVersion(s.HardwareVersion))
+		}
+		if subscription != t.state.Subscription {
+			newEvent(model.DeviceEventSubscription, t.state.Subscription, subscription)
+		}
+	}
+	if end := t.subscriptionEnd(s, now); !end.IsZero() && end.Sub(now) <= t.config.GetSubscriptionWarnBefore() && !end.Equal(t.state.WarnedEnd) {
+		newEvent(model.DeviceEventSubscriptionExpiry, subscription, end.Format(time.DateOnly))
+		t.state.WarnedEnd = end
+	}
+
+	history := events
+	if !t.state.Tracked {
+		history = append([]model.DeviceEvent{{
+			Date: now,
+			Kind: model.DeviceEventTracked,
+			Name: s.Name,
+			New:  model.FormatFirmwareVersion(s.FirmwareVersion),
+		}}, history...)
+	}
+	changed := len(history) > 0 || !s.CurrentSubscription.CreationDate.Equal(t.state.SubscriptionCreationDate)
+
+	t.state.Tracked = true
+	t.state.FirmwareVersion = s.FirmwareVersion
+	t.state.HardwareVersion = s.HardwareVersion
+	t.state.Subscription = subscription
+	t.state.SubscriptionCreationDate = s.CurrentSubscription.CreationDate
+	t.state.Events = append(t.state.Events, history...)
+	if n := len(t.state.Events); n > historyMaxEvents {
+		t.state.Events = t.state.Events[n-historyMaxEvents:]
+	}
+	if changed {
+		t.save()
+	}
+	return events
+}
+
+// subscriptionEnd returns the end of the subscription's current period, zero when unknown
+func (t *Tracker) subscriptionEnd(s model.SmartlockResponse, now time.Time) time.Time {
+	period := t.config.SubscriptionPeriod
+	created := s.CurrentSubscription.CreationDate
+	if period <= 0 || s.CurrentSubscription.Type == "" || created.IsZero() || created.After(now) {
+		return time.Time{}
+	}
+	return created.Add((now.Sub(created)/period + 1) * period)
+}
+
+func (t *Tracker) save() {
+	if t.cache == nil {
+		return
+	}
+	if err := t.cache.Save(historyCacheKey, t.state); err != nil {
+		log.Error().Err(err).Msg("Unable to save device history to cache")
+	}
+}
+
+func (t *Tracker) load() error {
+	if t.cache == nil {
+		return cache.ErrCacheNoClient
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	err := t.cache.Load(historyCacheKey, &t.state)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNoServers):
+		return nil
+	default:
+		return err
+	}
+}
+
+// Format returns the smartlock's current versions and subscription followed by its history, dates being in loc
+func Format(lang string, loc *time.Location, s model.SmartlockResponse, events []model.DeviceEvent) string {
+	subscription := s.FormatSubscription()
+	if subscription == "" {
+		subscription = i18n.T(lang, "device.no_subscription")
+	} else if !s.CurrentSubscription.CreationDate.IsZero() {
+		subscription = i18n.T(lang, "device.subscription_since", subscription, s.CurrentSubscription.CreationDate.In(loc).Format(time.DateOnly))
+	}
+	lines := []string{
+		i18n.T(lang, "device.current", s.State.State.GetEmoji(), s.Name,
+			model.FormatFirmwareVersion(s.FirmwareVersion), model.FormatHardwareVersion(s.HardwareVersion), subscription),
+		"",
+	}
+	if len(events) == 0 {
+		lines = append(lines, i18n.T(lang, "device.no_history"))
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, i18n.T(lang, "device.history"))
+	for _, e := range events {
+		lines = append(lines, e.Date.In(loc).Format(time.DateTime)+" "+e.Format(lang))
+	}
+	return strings.Join(lines, "\n")
+}
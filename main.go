@@ -15,5 +15,6 @@ func main() {
 		log.Error().
 			Err(err).
 			Send()
+		os.Exit(1)
 	}
 }
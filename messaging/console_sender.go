@@ -35,6 +35,10 @@ func (c *ConsoleSender) sendOne(e *Event) error {
 			bytes, err = json.Marshal(e.Log)
 		} else if e.IsSmartlockEvent() {
 			bytes, err = json.Marshal(e.Smartlock)
+		} else if e.IsDriftEvent() {
+			bytes, err = json.Marshal(e.Drift)
+		} else if e.IsDeviceEvent() {
+			bytes, err = json.Marshal(e.Device)
 		} else {
 			err = fmt.Errorf("unable to determine the event type to send")
 		}
@@ -47,7 +51,7 @@ func (c *ConsoleSender) sendOne(e *Event) error {
 
 	// Regular output
 	if e.IsLogEvent() {
-		values := e.GetValues(c.IncludeDate, false, c.Timezone)
+		values := e.GetValues(c.IncludeDate, false, c.Timezone, c.GetLanguage())
 		logger := log.With().Logger()
 		for k, v := range values {
 			logger = logger.With().Str(k, v).Logger()
@@ -61,6 +65,21 @@ func (c *ConsoleSender) sendOne(e *Event) error {
 			Bool("doorsensor_battery_critical", e.Smartlock.State.DoorsensorBatteryCritical).
 			Int32("battery_percent", e.Smartlock.State.BatteryCharge).
 			Send()
+	} else if e.IsDriftEvent() {
+		log.Warn().
+			Str("name", e.Drift.Name).
+			Interface("changes", e.Drift.Changes).
+			Interface("drifts", e.Drift.Drifts).
+			Bool("enforced", e.Drift.Enforced).
+			Str("enforce_error", e.Drift.EnforceError).
+			Msg("Smartlock configuration changed")
+	} else if e.IsDeviceEvent() {
+		log.Warn().
+			Str("name", e.Device.Name).
+			Str("kind", string(e.Device.Kind)).
+			Str("old", e.Device.Old).
+			Str("new", e.Device.New).
+			Msg("Smartlock device changed")
 	} else {
 		return fmt.Errorf("unable to determine the event type to send")
 	}
@@ -2,21 +2,33 @@ package messaging
 
 import (
 	"fmt"
-	"github.com/nmaupu/nuki-logger/model"
 	"strings"
 	"time"
+
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/model"
 )
 
 type Sender interface {
 	Send(events []*Event) error
 	GetName() string
 	GetTimezone() string
+	GetLanguage() string
+}
+
+// ResumableSender delivers events in several parts and can resume after the parts a previous attempt delivered
+type ResumableSender interface {
+	Sender
+	// SendFrom sends events skipping their first delivered parts, returns the number of parts delivered so far
+	SendFrom(events []*Event, delivered int) (int, error)
 }
 
 type sender struct {
 	Name        string `mapstructure:"-"`
 	IncludeDate bool   `mapstructure:"include_date"`
 	Timezone    string `mapstructure:"timezone"`
+	// Language is the language used to format events (en, fr, de or es)
+	Language string `mapstructure:"language"`
 }
 
 func (s *sender) GetName() string {
@@ -27,12 +39,22 @@ func (s *sender) GetTimezone() string {
 	return s.Timezone
 }
 
+func (s *sender) GetLanguage() string {
+	return i18n.Normalize(s.Language)
+}
+
 type Event struct {
 	Prefix          string
 	Log             model.NukiSmartlockLogResponse
 	ReservationName string
 	Smartlock       model.SmartlockResponse
-	Json            bool
+	// Modification is set when a pending modification has been applied
+	Modification *model.ReservationPendingModification
+	// Drift is set when the smartlock's configuration has changed
+	Drift *model.SmartlockDrift
+	// Device is set when the smartlock's firmware, hardware or subscription has changed
+	Device *model.DeviceEvent
+	Json   bool
 }
 
 func (e Event) IsLogEvent() bool {
@@ -43,21 +65,33 @@ func (e Event) IsSmartlockEvent() bool {
 	return e.Smartlock.SmartlockId != 0
 }
 
-func (e Event) GetValues(includeDate, emoji bool, tz string) map[string]string {
+func (e Event) IsModificationEvent() bool {
+	return e.Modification != nil
+}
+
+func (e Event) IsDriftEvent() bool {
+	return e.Drift != nil
+}
+
+func (e Event) IsDeviceEvent() bool {
+	return e.Device != nil
+}
+
+func (e Event) GetValues(includeDate, emoji bool, tz, lang string) map[string]string {
 	var values map[string]string
 	if emoji {
 		values = map[string]string{
-			"action":  e.Log.Action.String(),
+			"action":  e.Log.Action.Translate(lang),
 			"trigger": e.Log.Trigger.GetEmoji(),
 			"state":   e.Log.State.GetEmoji(),
-			"source":  e.Log.Source.String(),
+			"source":  e.Log.Source.Translate(lang),
 		}
 	} else {
 		values = map[string]string{
-			"action":  e.Log.Action.String(),
-			"trigger": e.Log.Trigger.String(),
-			"state":   e.Log.State.String(),
-			"source":  e.Log.Source.String(),
+			"action":  e.Log.Action.Translate(lang),
+			"trigger": e.Log.Trigger.Translate(lang),
+			"state":   e.Log.State.Translate(lang),
+			"source":  e.Log.Source.Translate(lang),
 		}
 	}
 
@@ -77,8 +111,8 @@ func (e Event) GetValues(includeDate, emoji bool, tz string) map[string]string {
 	return values
 }
 
-func (e Event) String(includeDate, emoji bool, tz string) string {
-	values := e.GetValues(includeDate, emoji, tz)
+func (e Event) String(includeDate, emoji bool, tz, lang string) string {
+	values := e.GetValues(includeDate, emoji, tz, lang)
 	var valuesStr []string
 	for k, v := range values {
 		valuesStr = append(valuesStr, fmt.Sprintf("%s=%s", k, v))
@@ -0,0 +1,125 @@
+package messaging
+
+import (
+	"sync"
+	"time"
+)
+
+// bucketIdleTimeout is how long a full bucket is kept unused before being evicted, and how often buckets are checked
+const bucketIdleTimeout = time.Minute * 10
+
+// tokenBucket is a simple token bucket rate limiter
+type tokenBucket struct {
+	mutex    sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, capacity int) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		rate:     rate,
+		last:     time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// setRate changes the rate and capacity, keeping the tokens available
+func (b *tokenBucket) setRate(rate float64, capacity int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rate = rate
+	b.capacity = float64(capacity)
+	b.tokens = min(b.tokens, b.capacity)
+}
+
+// isIdle returns true if the bucket has not been used for d and is full again
+func (b *tokenBucket) isIdle(d time.Duration) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	elapsed := time.Since(b.last)
+	return elapsed > d && b.tokens+elapsed.Seconds()*b.rate >= b.capacity
+}
+
+// Wait blocks until a token is available
+func (b *tokenBucket) Wait() {
+	if d := b.reserve(); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// chatRateLimiter holds one token bucket per chat
+type chatRateLimiter struct {
+	mutex     sync.Mutex
+	buckets   map[int64]*tokenBucket
+	rate      float64
+	capacity  int
+	lastPrune time.Time
+}
+
+func newChatRateLimiter(rate float64, capacity int) *chatRateLimiter {
+	return &chatRateLimiter{
+		buckets:   make(map[int64]*tokenBucket),
+		rate:      rate,
+		capacity:  capacity,
+		lastPrune: time.Now(),
+	}
+}
+
+// SetRate changes the limits of every chat
+func (l *chatRateLimiter) SetRate(rate float64, capacity int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.rate = rate
+	l.capacity = capacity
+	for _, b := range l.buckets {
+		b.setRate(rate, capacity)
+	}
+}
+
+// Wait blocks until a message can be sent to chatID
+func (l *chatRateLimiter) Wait(chatID int64) {
+	l.mutex.Lock()
+	if time.Since(l.lastPrune) > bucketIdleTimeout {
+		l.prune()
+	}
+	b, ok := l.buckets[chatID]
+	if !ok {
+		b = newTokenBucket(l.rate, l.capacity)
+		l.buckets[chatID] = b
+	}
+	l.mutex.Unlock()
+
+	b.Wait()
+}
+
+// prune evicts the buckets idle and full, a new bucket being full as well
+func (l *chatRateLimiter) prune() {
+	for chatID, b := range l.buckets {
+		if b.isIdle(bucketIdleTimeout) {
+			delete(l.buckets, chatID)
+		}
+	}
+	l.lastPrune = time.Now()
+}
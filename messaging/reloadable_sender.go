@@ -0,0 +1,53 @@
+package messaging
+
+import "sync"
+
+var _ ResumableSender = (*ReloadableSender)(nil)
+
+// ReloadableSender forwards to a sender which can be replaced while in use, e.g. when the configuration is reloaded
+type ReloadableSender struct {
+	mutex  sync.RWMutex
+	sender Sender
+}
+
+func NewReloadableSender(s Sender) *ReloadableSender {
+	return &ReloadableSender{sender: s}
+}
+
+// Swap replaces the sender, messages being sent keep using the previous one
+func (r *ReloadableSender) Swap(s Sender) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sender = s
+}
+
+func (r *ReloadableSender) get() Sender {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.sender
+}
+
+func (r *ReloadableSender) Send(events []*Event) error {
+	return r.get().Send(events)
+}
+
+// SendFrom resumes sending events when the current sender is resumable, sending them all otherwise
+func (r *ReloadableSender) SendFrom(events []*Event, delivered int) (int, error) {
+	s := r.get()
+	if rs, ok := s.(ResumableSender); ok {
+		return rs.SendFrom(events, delivered)
+	}
+	return 0, s.Send(events)
+}
+
+func (r *ReloadableSender) GetName() string {
+	return r.get().GetName()
+}
+
+func (r *ReloadableSender) GetTimezone() string {
+	return r.get().GetTimezone()
+}
+
+func (r *ReloadableSender) GetLanguage() string {
+	return r.get().GetLanguage()
+}
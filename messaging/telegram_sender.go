@@ -2,65 +2,402 @@ package messaging
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
 
 	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
 var (
-	_ Sender = (*TelegramSender)(nil)
+	_ ResumableSender = (*TelegramSender)(nil)
+)
+
+const (
+	// TelegramMaxMessageLength is the maximum number of characters of a Telegram message, in UTF-16 code units
+	TelegramMaxMessageLength = 4096
+	// DefaultTelegramRateLimit is the default number of messages per second allowed per chat
+	DefaultTelegramRateLimit = 1.0
+	// DefaultTelegramRateLimitBurst is the default number of messages which can be sent at once per chat
+	DefaultTelegramRateLimitBurst = 3
+	telegramMaxRetries            = 5
 )
 
 type TelegramSender struct {
 	sender `mapstructure:",squash"`
 	Token  string `mapstructure:"token"`
 	ChatID int64  `mapstructure:"chat_id"`
+	// APIServer overrides Telegram's API server URL, useful to test against a local fake API
+	APIServer string `mapstructure:"api_server"`
+	// RateLimit is the number of messages per second allowed per chat
+	RateLimit float64 `mapstructure:"rate_limit"`
+	// RateLimitBurst is the number of messages which can be sent at once per chat
+	RateLimitBurst int `mapstructure:"rate_limit_burst"`
+
+	initOnce sync.Once
+	bot      *telego.Bot
+	botErr   error
+	limiter  *chatRateLimiter
 }
 
-func (t *TelegramSender) Send(events []*Event) error {
-	var err error
+// WithToken returns a copy of the sender's configuration using token, without the client of the sender
+func (t *TelegramSender) WithToken(token string) *TelegramSender {
+	return &TelegramSender{
+		sender:         t.sender,
+		Token:          token,
+		ChatID:         t.ChatID,
+		APIServer:      t.APIServer,
+		RateLimit:      t.RateLimit,
+		RateLimitBurst: t.RateLimitBurst,
+	}
+}
+
+// BotOptions returns telego options to use when creating a bot from this sender
+func (t *TelegramSender) BotOptions() []telego.BotOption {
+	var opts []telego.BotOption
+	if t.APIServer != "" {
+		opts = append(opts, telego.WithAPIServer(t.APIServer))
+	}
+	return opts
+}
+
+func (t *TelegramSender) init() {
+	t.initOnce.Do(func() {
+		t.bot, t.botErr = telego.NewBot(t.Token, t.BotOptions()...)
+		t.limiter = newChatRateLimiter(t.rateLimit())
+	})
+}
+
+func (t *TelegramSender) rateLimit() (float64, int) {
+	rate := t.RateLimit
+	if rate <= 0 {
+		rate = DefaultTelegramRateLimit
+	}
+	burst := t.RateLimitBurst
+	if burst <= 0 {
+		burst = DefaultTelegramRateLimitBurst
+	}
+	return rate, burst
+}
 
-	bot, err := telego.NewBot(t.Token)
+// ShareClient makes t use the Telegram client and rate limiter of previous when they use the same bot,
+// messages sent by both counting against the same per chat limits. It must be called before t is used.
+func (t *TelegramSender) ShareClient(previous *TelegramSender) bool {
+	if t.Token != previous.Token || t.APIServer != previous.APIServer {
+		return false
+	}
+	previous.init()
+	shared := false
+	t.initOnce.Do(func() {
+		t.bot, t.botErr = previous.bot, previous.botErr
+		t.limiter = previous.limiter
+		t.limiter.SetRate(t.rateLimit())
+		shared = true
+	})
+	return shared
+}
+
+// Bot returns the long-lived Telegram client associated to this sender
+func (t *TelegramSender) Bot() (*telego.Bot, error) {
+	t.init()
+	return t.bot, t.botErr
+}
+
+// SendMessage sends a message respecting per chat rate limits and retrying when Telegram asks to.
+// Messages too long are split into several ones.
+func (t *TelegramSender) SendMessage(params *telego.SendMessageParams) (*telego.Message, error) {
+	msg, _, err := t.sendChunks(params, 0)
+	return msg, err
+}
+
+// sendChunks sends the chunks of a message after the first skip ones, already delivered.
+// It returns the last message sent and the number of chunks delivered, skipped ones included.
+func (t *TelegramSender) sendChunks(params *telego.SendMessageParams, skip int) (*telego.Message, int, error) {
+	bot, err := t.Bot()
 	if err != nil {
+		return nil, skip, err
+	}
+
+	var lastMsg *telego.Message
+	chunks := SplitMessage(params.Text, TelegramMaxMessageLength, params.ParseMode)
+	for i := skip; i < len(chunks); i++ {
+		chunk := chunks[i]
+		p := *params
+		p.Text = chunk
+		if i < len(chunks)-1 {
+			// Only keep the keyboard on the last chunk
+			p.ReplyMarkup = nil
+		}
+
+		lastMsg, err = t.sendWithRetry(bot, &p)
+		if err != nil {
+			return lastMsg, i, err
+		}
+	}
+	return lastMsg, max(skip, len(chunks)), nil
+}
+
+func (t *TelegramSender) sendWithRetry(bot *telego.Bot, params *telego.SendMessageParams) (*telego.Message, error) {
+	var msg *telego.Message
+	err := t.withRetry(params.ChatID.ID, func() error {
+		var err error
+		msg, err = bot.SendMessage(params)
+		return err
+	})
+	return msg, err
+}
+
+// EditMessageText edits a message previously sent respecting per chat rate limits.
+// Text too long is truncated as an edited message cannot be split.
+func (t *TelegramSender) EditMessageText(params *telego.EditMessageTextParams) (*telego.Message, error) {
+	bot, err := t.Bot()
+	if err != nil {
+		return nil, err
+	}
+
+	p := *params
+	if chunks := SplitMessage(p.Text, TelegramMaxMessageLength, p.ParseMode); len(chunks) > 1 {
+		p.Text = chunks[0]
+	}
+
+	var msg *telego.Message
+	err = t.withRetry(p.ChatID.ID, func() error {
+		var err error
+		msg, err = bot.EditMessageText(&p)
 		return err
+	})
+	return msg, err
+}
+
+// withRetry calls f respecting chatID's rate limit and retries as long as Telegram asks to
+func (t *TelegramSender) withRetry(chatID int64, f func() error) error {
+	for attempt := 0; ; attempt++ {
+		t.limiter.Wait(chatID)
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		var apiErr *ta.Error
+		if attempt >= telegramMaxRetries ||
+			!errors.As(err, &apiErr) ||
+			apiErr.ErrorCode != http.StatusTooManyRequests {
+			return err
+		}
+
+		retryAfter := time.Second
+		if apiErr.Parameters != nil && apiErr.Parameters.RetryAfter > 0 {
+			retryAfter = time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+		}
+		log.Warn().
+			Int64("chat_id", chatID).
+			Dur("retry_after", retryAfter).
+			Int("attempt", attempt+1).
+			Msg("Telegram rate limit reached, retrying")
+		time.Sleep(retryAfter)
 	}
+}
+
+func (t *TelegramSender) Send(events []*Event) error {
+	_, err := t.SendFrom(events, 0)
+	return err
+}
 
+// SendFrom sends events as one message, skipping the chunks delivered by a previous attempt
+func (t *TelegramSender) SendFrom(events []*Event, delivered int) (int, error) {
 	var logsLines []string
 	for _, e := range events {
 		var msg string
+		var err error
 
 		if e.IsLogEvent() {
 			msg, err = t.FormatLogEvent(e)
 			if err != nil {
-				return err
+				return delivered, err
 			}
 		} else if e.IsSmartlockEvent() {
 			msg, err = t.formatSmartlockEvent(e)
 			if err != nil {
-				return err
+				return delivered, err
+			}
+		} else if e.IsDriftEvent() {
+			msg, err = t.formatDriftEvent(e)
+			if err != nil {
+				return delivered, err
+			}
+		} else if e.IsDeviceEvent() {
+			msg, err = t.formatDeviceEvent(e)
+			if err != nil {
+				return delivered, err
 			}
 		} else {
-			return fmt.Errorf("unable to determine the type of event to send")
+			return delivered, fmt.Errorf("unable to determine the type of event to send")
 		}
 
 		logsLines = append(logsLines, msg)
 	}
 
-	tgMsg := tu.Message(
+	_, delivered, err := t.sendChunks(tu.Message(
 		tu.ID(t.ChatID),
 		strings.Join(logsLines, "\n"),
-	)
-	_, err = bot.SendMessage(tgMsg)
-	return err
+	), delivered)
+	return delivered, err
+}
+
+// SplitMessage splits text into chunks of at most maxLen UTF-16 code units, the way Telegram counts characters.
+// Chunks are cut on new lines, outside of any entity when parseMode is telego.ModeMarkdown,
+// lines and entities too long being cut anyway.
+func SplitMessage(text string, maxLen int, parseMode string) []string {
+	if utf16Len(text) <= maxLen {
+		return []string{text}
+	}
+
+	// Pieces are never longer than maxLen
+	var pieces []string
+	for _, block := range splitBlocks(text, parseMode == telego.ModeMarkdown) {
+		if utf16Len(block) <= maxLen {
+			pieces = append(pieces, block)
+			continue
+		}
+		// Block itself is too long, cutting it on new lines
+		for _, line := range strings.SplitAfter(block, "\n") {
+			for utf16Len(line) > maxLen {
+				cut := utf16Cut(line, maxLen)
+				pieces = append(pieces, line[:cut])
+				line = line[cut:]
+			}
+			pieces = append(pieces, line)
+		}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	for _, piece := range pieces {
+		pieceLen := utf16Len(piece)
+		if currentLen+pieceLen > maxLen && currentLen > 0 {
+			chunks = append(chunks, strings.TrimSuffix(current.String(), "\n"))
+			current.Reset()
+			currentLen = 0
+		}
+		current.WriteString(piece)
+		currentLen += pieceLen
+	}
+	if currentLen > 0 {
+		chunks = append(chunks, strings.TrimSuffix(current.String(), "\n"))
+	}
+	return chunks
+}
+
+// splitBlocks splits text into blocks of whole lines, a Markdown entity never spanning two blocks
+func splitBlocks(text string, markdown bool) []string {
+	var blocks []string
+	var current strings.Builder
+	open := ""
+	for _, line := range strings.SplitAfter(text, "\n") {
+		current.WriteString(line)
+		if markdown {
+			open = markdownEntity(line, open)
+		}
+		if open == "" {
+			blocks = append(blocks, current.String())
+			current.Reset()
+		}
+	}
+	if current.Len() > 0 {
+		blocks = append(blocks, current.String())
+	}
+	return blocks
+}
+
+// markdownEntity returns the marker of the Markdown entity still open at the end of line,
+// open being the one open at its start. Entities cannot be nested in Telegram's legacy Markdown.
+func markdownEntity(line, open string) string {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case open == "" && c == '\\':
+			// Escaped character
+			i++
+		case (open == "" || open == "```") && strings.HasPrefix(line[i:], "```"):
+			open = toggleEntity(open, "```")
+			i += 2
+		case open == "```":
+		case c == '`' && (open == "" || open == "`"):
+			open = toggleEntity(open, "`")
+		case open == "`":
+		case c == '*' || c == '_':
+			if open == "" || open == string(c) {
+				open = toggleEntity(open, string(c))
+			}
+		case c == '[' && open == "":
+			open = "["
+		case c == ']' && open == "[":
+			open = ""
+			if strings.HasPrefix(line[i+1:], "(") {
+				open = "("
+				i++
+			}
+		case c == ')' && open == "(":
+			open = ""
+		}
+	}
+	return open
+}
+
+func toggleEntity(open, marker string) string {
+	if open == marker {
+		return ""
+	}
+	return marker
+}
+
+// utf16Len returns the number of UTF-16 code units of s
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// utf16RuneLen returns the number of UTF-16 code units encoding r, runes outside the BMP needing a surrogate pair
+func utf16RuneLen(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
+
+// utf16Cut returns the byte index of s at which its first maxLen UTF-16 code units end, never cutting a rune
+func utf16Cut(s string, maxLen int) int {
+	n := 0
+	for i, r := range s {
+		if n+utf16RuneLen(r) > maxLen {
+			return max(i, utf8.RuneLen(r))
+		}
+		n += utf16RuneLen(r)
+	}
+	return len(s)
 }
 
 func (t *TelegramSender) FormatLogEvent(e *Event) (string, error) {
+	return t.FormatLogEventLang(e, t.GetLanguage())
+}
+
+// FormatLogEventLang formats a log event using the given language
+func (t *TelegramSender) FormatLogEventLang(e *Event, lang string) (string, error) {
 	if e.Json {
 		bytes, err := json.Marshal(e.Log)
 		if err != nil {
@@ -83,23 +420,23 @@ func (t *TelegramSender) FormatLogEvent(e *Event) (string, error) {
 	case e.Log.Trigger == model.NukiTriggerButton:
 		// Lock / unlock with button
 		return fmt.Sprintf("%s%s %s %s",
-			date, e.Log.Trigger.GetEmoji(), e.Log.Action.String(), e.Log.State.GetEmoji()), nil
+			date, e.Log.Trigger.GetEmoji(), e.Log.Action.Translate(lang), e.Log.State.GetEmoji()), nil
 	case e.Log.Trigger == model.NukiTriggerKeypad && e.Log.Source == model.NukiSourceKeypadCode:
 		// Someone enters keypad code
-		return fmt.Sprintf("%s%s %s by '%s' %s",
-			date, e.Log.Trigger.GetEmoji(), e.Log.Action.String(), e.ReservationName, e.Log.State.GetEmoji()), nil
+		return i18n.T(lang, "sender.keypad_code",
+			date, e.Log.Trigger.GetEmoji(), e.Log.Action.Translate(lang), e.ReservationName, e.Log.State.GetEmoji()), nil
 	case e.Log.Trigger == model.NukiTriggerKeypad && e.Log.Source == model.NukiSourceDefault:
 		// < keypad button is pressed
 		return fmt.Sprintf("%s%s %s %s",
-			date, e.Log.Trigger.GetEmoji(), e.Log.Action.String(), e.Log.State.GetEmoji()), nil
+			date, e.Log.Trigger.GetEmoji(), e.Log.Action.Translate(lang), e.Log.State.GetEmoji()), nil
 	case e.Log.Trigger == model.NukiTriggerSystem && (e.Log.Action == model.NukiActionDoorOpened || e.Log.Action == model.NukiActionDoorClosed):
 		return fmt.Sprintf("%s%s %s %s",
-			date, emoji.Door.String(), e.Log.Action.String(), e.Log.State.GetEmoji()), nil
+			date, emoji.Door.String(), e.Log.Action.Translate(lang), e.Log.State.GetEmoji()), nil
 	case e.Log.Trigger == model.NukiTriggerManual:
 		return fmt.Sprintf("%s%s %s %s",
-			date, e.Log.Trigger.GetEmoji(), e.Log.Action.String(), e.Log.State.GetEmoji()), nil
+			date, e.Log.Trigger.GetEmoji(), e.Log.Action.Translate(lang), e.Log.State.GetEmoji()), nil
 	default:
-		return e.String(t.IncludeDate, true, t.Timezone), nil
+		return e.String(t.IncludeDate, true, t.Timezone, lang), nil
 	}
 }
 
@@ -112,5 +449,29 @@ func (t *TelegramSender) formatSmartlockEvent(e *Event) (string, error) {
 		return string(bytes), nil
 	}
 
-	return e.Smartlock.PrettyFormat(), nil
+	return e.Smartlock.PrettyFormat(t.GetLanguage()), nil
+}
+
+func (t *TelegramSender) formatDriftEvent(e *Event) (string, error) {
+	if e.Json {
+		bytes, err := json.Marshal(e.Drift)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+
+	return e.Drift.Format(t.GetLanguage()), nil
+}
+
+func (t *TelegramSender) formatDeviceEvent(e *Event) (string, error) {
+	if e.Json {
+		bytes, err := json.Marshal(e.Device)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+
+	return e.Device.Format(t.GetLanguage()), nil
 }
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	_ zerolog.LogObjectMarshaler = (*GuestLink)(nil)
+	_ zerolog.LogObjectMarshaler = (*LateCheckoutRequest)(nil)
+)
+
+// GuestLink links a Telegram user to a reservation
+type GuestLink struct {
+	UserID         int64     `json:"user_id"`
+	ChatID         int64     `json:"chat_id"`
+	ReservationRef string    `json:"reservation_ref"`
+	LinkedAt       time.Time `json:"linked_at"`
+	// Welcomed is true once the guest has been welcomed after using the door code for the first time
+	Welcomed bool `json:"welcomed"`
+}
+
+func (g GuestLink) MarshalZerologObject(e *zerolog.Event) {
+	e.Int64("user_id", g.UserID).
+		Int64("chat_id", g.ChatID).
+		Str("reservation_ref", g.ReservationRef).
+		Time("linked_at", g.LinkedAt).
+		Bool("welcomed", g.Welcomed)
+}
+
+// GuestLinkToken is a one-time token a guest can use to link its account to a reservation
+type GuestLinkToken struct {
+	ReservationRef string    `json:"reservation_ref"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// LateCheckoutRequest is a late checkout asked by a guest, waiting for a host approval
+type LateCheckoutRequest struct {
+	ID             string    `json:"id"`
+	UserID         int64     `json:"user_id"`
+	ChatID         int64     `json:"chat_id"`
+	ReservationRef string    `json:"reservation_ref"`
+	CheckOutTime   time.Time `json:"check_out_time"`
+	RequestedAt    time.Time `json:"requested_at"`
+}
+
+func (r LateCheckoutRequest) FormatCheckOut() string {
+	return r.CheckOutTime.Format(FormatTimeHoursMinutes)
+}
+
+func (r LateCheckoutRequest) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("id", r.ID).
+		Int64("user_id", r.UserID).
+		Str("reservation_ref", r.ReservationRef).
+		Str("check_out", r.FormatCheckOut())
+}
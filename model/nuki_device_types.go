@@ -3,12 +3,14 @@ package model
 type NukiDeviceType int32
 
 var (
-	NukiDeviceTypeSmartlock = NukiDeviceType(0)
-	NukiDeviceTypeOpener    = NukiDeviceType(2)
-	NukiDeviceTypeSmartdoor = NukiDeviceType(3)
-	NukiDeviceTypes         = map[NukiDeviceType]string{
-		NukiDeviceTypeSmartlock: "smartlock",
-		NukiDeviceTypeOpener:    "opener",
-		NukiDeviceTypeSmartdoor: "smartdoor",
+	NukiDeviceTypeSmartlock  = NukiDeviceType(0)
+	NukiDeviceTypeOpener     = NukiDeviceType(2)
+	NukiDeviceTypeSmartdoor  = NukiDeviceType(3)
+	NukiDeviceTypeSmartlock3 = NukiDeviceType(4)
+	NukiDeviceTypes          = map[NukiDeviceType]string{
+		NukiDeviceTypeSmartlock:  "smartlock",
+		NukiDeviceTypeOpener:     "opener",
+		NukiDeviceTypeSmartdoor:  "smartdoor",
+		NukiDeviceTypeSmartlock3: "smartlock 3.0/4.0",
 	}
 )
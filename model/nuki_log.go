@@ -1,8 +1,11 @@
 package model
 
 import (
+	"fmt"
 	"slices"
 	"time"
+
+	"github.com/nmaupu/nuki-logger/i18n"
 )
 
 type NukiSmartlockLogResponse struct {
@@ -60,32 +63,121 @@ func (n NukiSource) String() string {
 	return str
 }
 
+// Translate returns the name of the action in the given language
+func (n NukiAction) Translate(lang string) string {
+	if _, ok := NukiActions[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("action.%d", n))
+}
+
+// Translate returns the name of the trigger in the given language
+func (n NukiTrigger) Translate(lang string) string {
+	if _, ok := NukiTriggers[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("trigger.%d", n))
+}
+
+// Translate returns the name of the state in the given language
+func (n NukiState) Translate(lang string) string {
+	if _, ok := NukiStates[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("state.%d", n))
+}
+
+// Translate returns the name of the source in the given language
+func (n NukiSource) Translate(lang string) string {
+	if _, ok := NukiSources[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("source.%d", n))
+}
+
 func (n NukiSmartlockLogResponse) Equals(n2 NukiSmartlockLogResponse) bool {
 	return n.ID == n2.ID
 }
 
-// Diff returns new NukiSmartlockLogResponse from new not present in old
-func Diff(new, old []NukiSmartlockLogResponse) []NukiSmartlockLogResponse {
-	if len(new) == 0 {
-		return []NukiSmartlockLogResponse{}
-	}
-	if len(old) == 0 {
-		return new
+// LogsWindow keeps the ids of the logs already seen over a sliding window of time,
+// so that new logs are found whatever the order and the pages the API returns them in
+type LogsWindow struct {
+	duration time.Duration
+	seen     map[string]time.Time
+	latest   time.Time
+}
+
+func NewLogsWindow(duration time.Duration, known []NukiSmartlockLogResponse) *LogsWindow {
+	w := &LogsWindow{
+		duration: duration,
+		seen:     make(map[string]time.Time),
 	}
+	w.Add(known)
+	return w
+}
+
+// IsEmpty returns true when no log has been seen yet
+func (w *LogsWindow) IsEmpty() bool {
+	return len(w.seen) == 0
+}
 
-	if new[0].Equals(old[0]) { // no new logs
-		return []NukiSmartlockLogResponse{}
+// Latest returns the date of the most recent log seen
+func (w *LogsWindow) Latest() time.Time {
+	return w.latest
+}
+
+// New returns the logs not seen yet, oldest first, ignoring the ones too old to be tracked by the window.
+// late is the number of new logs older than the most recent log seen, i.e. returned late by the API.
+func (w *LogsWindow) New(logs []NukiSmartlockLogResponse) (res []NukiSmartlockLogResponse, late int) {
+	done := make(map[string]bool)
+	for _, l := range logs {
+		if _, ok := w.seen[l.ID]; ok || done[l.ID] {
+			continue
+		}
+		if !w.IsEmpty() && l.Date.Before(w.latest.Add(-w.duration)) {
+			continue
+		}
+		done[l.ID] = true
+		if l.Date.Before(w.latest) {
+			late++
+		}
+		res = append(res, l)
 	}
+	slices.SortStableFunc(res, func(a, b NukiSmartlockLogResponse) int {
+		return a.Date.Compare(b.Date)
+	})
+	return res, late
+}
 
-	var diff []NukiSmartlockLogResponse
-	for _, r := range new {
-		// While not equals the first old, adding this new log
-		if r.Equals(old[0]) {
-			break
+// Add marks logs as seen, forgetting the ones out of the window
+func (w *LogsWindow) Add(logs []NukiSmartlockLogResponse) {
+	for _, l := range logs {
+		w.seen[l.ID] = l.Date
+		if l.Date.After(w.latest) {
+			w.latest = l.Date
 		}
-		diff = append(diff, r)
 	}
+	for id, date := range w.seen {
+		if date.Before(w.latest.Add(-w.duration)) {
+			delete(w.seen, id)
+		}
+	}
+}
 
-	slices.Reverse(diff)
-	return diff
+// HasGap returns true when a full page of logs shares no log with the ones seen and is more recent
+// than all of them, logs between the page and the most recent one seen being possibly missing
+func (w *LogsWindow) HasGap(page []NukiSmartlockLogResponse, limit int) bool {
+	if w.IsEmpty() || len(page) == 0 || len(page) < limit {
+		return false
+	}
+	oldest := page[0].Date
+	for _, l := range page {
+		if _, ok := w.seen[l.ID]; ok {
+			return false
+		}
+		if l.Date.Before(oldest) {
+			oldest = l.Date
+		}
+	}
+	return !oldest.Before(w.latest)
 }
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testLogsBase = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testLog returns a log named after its id, minute minutes after testLogsBase
+func testLog(id string, minute int) NukiSmartlockLogResponse {
+	return NukiSmartlockLogResponse{ID: id, Name: id, Date: testLogsBase.Add(time.Duration(minute) * time.Minute)}
+}
+
+func logIDs(logs []NukiSmartlockLogResponse) []string {
+	ids := make([]string, 0, len(logs))
+	for _, l := range logs {
+		ids = append(ids, l.ID)
+	}
+	return ids
+}
+
+func TestLogsWindowNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		known    []NukiSmartlockLogResponse
+		logs     []NukiSmartlockLogResponse
+		want     []string
+		wantLate int
+	}{
+		{
+			name:  "new logs",
+			known: []NukiSmartlockLogResponse{testLog("a", 0), testLog("b", 1)},
+			logs:  []NukiSmartlockLogResponse{testLog("d", 3), testLog("c", 2), testLog("b", 1), testLog("a", 0)},
+			want:  []string{"c", "d"},
+		},
+		{
+			name:  "nothing new",
+			known: []NukiSmartlockLogResponse{testLog("a", 0), testLog("b", 1)},
+			logs:  []NukiSmartlockLogResponse{testLog("b", 1), testLog("a", 0)},
+		},
+		{
+			name: "empty window",
+			logs: []NukiSmartlockLogResponse{testLog("b", 1), testLog("a", 0)},
+			want: []string{"a", "b"},
+		},
+		{
+			name:  "reordered page",
+			known: []NukiSmartlockLogResponse{testLog("a", 0)},
+			logs:  []NukiSmartlockLogResponse{testLog("c", 2), testLog("a", 0), testLog("d", 3), testLog("b", 1)},
+			want:  []string{"b", "c", "d"},
+		},
+		{
+			name:     "late entries",
+			known:    []NukiSmartlockLogResponse{testLog("a", 0), testLog("c", 2)},
+			logs:     []NukiSmartlockLogResponse{testLog("d", 3), testLog("c", 2), testLog("b", 1), testLog("a", 0)},
+			want:     []string{"b", "d"},
+			wantLate: 1,
+		},
+		{
+			name:  "duplicates in page",
+			known: []NukiSmartlockLogResponse{testLog("a", 0)},
+			logs:  []NukiSmartlockLogResponse{testLog("b", 1), testLog("b", 1), testLog("a", 0)},
+			want:  []string{"b"},
+		},
+		{
+			name:     "logs older than the window",
+			duration: time.Hour,
+			known:    []NukiSmartlockLogResponse{testLog("a", 120)},
+			logs:     []NukiSmartlockLogResponse{testLog("b", 121), testLog("a", 120), testLog("old", 30)},
+			want:     []string{"b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration := tt.duration
+			if duration == 0 {
+				duration = time.Hour * 24
+			}
+			w := NewLogsWindow(duration, tt.known)
+			got, late := w.New(tt.logs)
+			if ids := logIDs(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("New() = %v, want %v", ids, tt.want)
+			}
+			if late != tt.wantLate {
+				t.Errorf("New() late = %d, want %d", late, tt.wantLate)
+			}
+		})
+	}
+}
+
+func TestLogsWindowAdd(t *testing.T) {
+	w := NewLogsWindow(time.Hour, []NukiSmartlockLogResponse{testLog("a", 0), testLog("b", 30)})
+	w.Add([]NukiSmartlockLogResponse{testLog("c", 90)})
+
+	if got := w.Latest(); !got.Equal(testLog("c", 90).Date) {
+		t.Errorf("Latest() = %v, want %v", got, testLog("c", 90).Date)
+	}
+	// a is out of the window and forgotten, b is still seen
+	got, _ := w.New([]NukiSmartlockLogResponse{testLog("c", 90), testLog("b", 30), testLog("a", 0)})
+	if len(got) != 0 {
+		t.Errorf("New() = %v, want no log", logIDs(got))
+	}
+	w.Add(nil)
+	if w.IsEmpty() {
+		t.Error("IsEmpty() = true after adding logs")
+	}
+}
+
+func TestLogsWindowHasGap(t *testing.T) {
+	page := func(from, to int) []NukiSmartlockLogResponse {
+		var res []NukiSmartlockLogResponse
+		for i := to; i >= from; i-- {
+			res = append(res, testLog(fmt.Sprint(i), i))
+		}
+		return res
+	}
+	tests := []struct {
+		name  string
+		known []NukiSmartlockLogResponse
+		page  []NukiSmartlockLogResponse
+		limit int
+		want  bool
+	}{
+		{
+			name:  "page overlapping the logs seen",
+			known: page(0, 5),
+			page:  page(5, 9),
+			limit: 5,
+		},
+		{
+			name:  "full page newer than the logs seen",
+			known: page(0, 5),
+			page:  page(10, 14),
+			limit: 5,
+			want:  true,
+		},
+		{
+			name:  "partial page",
+			known: page(0, 5),
+			page:  page(10, 12),
+			limit: 5,
+		},
+		{
+			name:  "full page of late logs",
+			known: page(10, 15),
+			page:  page(1, 5),
+			limit: 5,
+		},
+		{
+			name:  "empty window",
+			page:  page(10, 14),
+			limit: 5,
+		},
+		{
+			name:  "empty page",
+			known: page(0, 5),
+			limit: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewLogsWindow(time.Hour*24, tt.known)
+			if got := w.HasGap(tt.page, tt.limit); got != tt.want {
+				t.Errorf("HasGap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
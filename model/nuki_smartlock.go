@@ -1,9 +1,10 @@
 package model
 
 import (
-	"fmt"
-	"github.com/enescakir/emoji"
 	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
 )
 
 type SmartLockState struct {
@@ -69,26 +70,26 @@ type SmartlockResponse struct {
 		BatteryWarningPerMailEnabled bool `json:"batteryWarningPerMailEnabled"`
 	} `json:"webConfig"`
 	State struct {
-		Mode                      int   `json:"mode"`
-		State                     int   `json:"state"`
-		Trigger                   int   `json:"trigger"`
-		LastAction                int   `json:"lastAction"`
-		BatteryCritical           bool  `json:"batteryCritical"`
-		BatteryCharging           bool  `json:"batteryCharging"`
-		BatteryCharge             int32 `json:"batteryCharge"`
-		KeypadBatteryCritical     bool  `json:"keypadBatteryCritical"`
-		DoorsensorBatteryCritical bool  `json:"doorsensorBatteryCritical"`
-		DoorState                 int   `json:"doorState"`
-		RingToOpenTimer           int   `json:"ringToOpenTimer"`
-		NightMode                 bool  `json:"nightMode"`
+		Mode                      NukiSmartlockMode `json:"mode"`
+		State                     NukiLockState     `json:"state"`
+		Trigger                   NukiTrigger       `json:"trigger"`
+		LastAction                NukiAction        `json:"lastAction"`
+		BatteryCritical           bool              `json:"batteryCritical"`
+		BatteryCharging           bool              `json:"batteryCharging"`
+		BatteryCharge             int32             `json:"batteryCharge"`
+		KeypadBatteryCritical     bool              `json:"keypadBatteryCritical"`
+		DoorsensorBatteryCritical bool              `json:"doorsensorBatteryCritical"`
+		DoorState                 NukiDoorState     `json:"doorState"`
+		RingToOpenTimer           int               `json:"ringToOpenTimer"`
+		NightMode                 bool              `json:"nightMode"`
 	} `json:"state"`
-	FirmwareVersion     int       `json:"firmwareVersion"`
-	HardwareVersion     int       `json:"hardwareVersion"`
-	ServerState         int       `json:"serverState"`
-	AdminPinState       int       `json:"adminPinState"`
-	VirtualDevice       bool      `json:"virtualDevice"`
-	CreationDate        time.Time `json:"creationDate"`
-	UpdateDate          time.Time `json:"updateDate"`
+	FirmwareVersion     int             `json:"firmwareVersion"`
+	HardwareVersion     int             `json:"hardwareVersion"`
+	ServerState         NukiServerState `json:"serverState"`
+	AdminPinState       int             `json:"adminPinState"`
+	VirtualDevice       bool            `json:"virtualDevice"`
+	CreationDate        time.Time       `json:"creationDate"`
+	UpdateDate          time.Time       `json:"updateDate"`
 	CurrentSubscription struct {
 		Type         string    `json:"type"`
 		State        string    `json:"state"`
@@ -106,7 +107,8 @@ func (s SmartlockResponse) ToSmartlockState() SmartLockState {
 	}
 }
 
-func (s SmartlockResponse) PrettyFormat() string {
+// PrettyFormat returns a human-readable summary of the smartlock's batteries in the given language
+func (s SmartlockResponse) PrettyFormat(lang string) string {
 	smartlockState := s.ToSmartlockState()
 	ok := emoji.GreenCircle
 	warn := emoji.OrangeCircle
@@ -131,7 +133,7 @@ func (s SmartlockResponse) PrettyFormat() string {
 		eBatteryDoorsensor = crit
 	}
 
-	return fmt.Sprintf("*Smartlock %s*\nBattery pack: %s (%d%%)\nKeypad: %s\nDoor sensor: %s",
+	return i18n.T(lang, "smartlock.pretty",
 		smartlockState.Name,
 		eBattery, smartlockState.BatteryCharge,
 		eBatteryKeypad,
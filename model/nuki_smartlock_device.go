@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
+)
+
+type DeviceEventKind string
+
+const (
+	// DeviceEventTracked is recorded the first time the smartlock is checked
+	DeviceEventTracked            DeviceEventKind = "tracked"
+	DeviceEventFirmware           DeviceEventKind = "firmware"
+	DeviceEventHardware           DeviceEventKind = "hardware"
+	DeviceEventSubscription       DeviceEventKind = "subscription"
+	DeviceEventSubscriptionExpiry DeviceEventKind = "subscription_expiry"
+)
+
+var deviceEventEmojis = map[DeviceEventKind]emoji.Emoji{
+	DeviceEventTracked:            emoji.Memo,
+	DeviceEventFirmware:           emoji.CounterclockwiseArrowsButton,
+	DeviceEventHardware:           emoji.Wrench,
+	DeviceEventSubscription:       emoji.CreditCard,
+	DeviceEventSubscriptionExpiry: emoji.HourglassNotDone,
+}
+
+// DeviceEvent is a change of the smartlock's firmware, hardware or subscription
+type DeviceEvent struct {
+	Date time.Time       `json:"date"`
+	Kind DeviceEventKind `json:"kind"`
+	Name string          `json:"name"`
+	// Old and New are human-readable values: decoded versions, subscriptions or the date of a subscription's end
+	Old string `json:"old,omitempty"`
+	New string `json:"new"`
+}
+
+// Format returns a human-readable description of the event in the given language
+func (e DeviceEvent) Format(lang string) string {
+	key := fmt.Sprintf("device.%s", e.Kind)
+	switch e.Kind {
+	case DeviceEventTracked:
+		return i18n.T(lang, key, deviceEventEmojis[e.Kind], e.Name, e.New)
+	case DeviceEventSubscription:
+		orNone := func(subscription string) string {
+			if subscription == "" {
+				return i18n.T(lang, "device.no_subscription")
+			}
+			return subscription
+		}
+		return i18n.T(lang, key, deviceEventEmojis[e.Kind], e.Name, orNone(e.Old), orNone(e.New))
+	default:
+		return i18n.T(lang, key, deviceEventEmojis[e.Kind], e.Name, e.Old, e.New)
+	}
+}
+
+// FormatSubscription returns the current subscription's type and state, empty if there is none
+func (s SmartlockResponse) FormatSubscription() string {
+	if s.CurrentSubscription.Type == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", s.CurrentSubscription.Type, s.CurrentSubscription.State)
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
+)
+
+// SmartlockConfigChange is a field of the smartlock's configuration which changed between two checks
+type SmartlockConfigChange struct {
+	// Field is the section and the field's JSON names, e.g. advancedConfig.autoLock
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// SmartlockConfigDrift is a field of the smartlock's configuration which differs from the desired state
+type SmartlockConfigDrift struct {
+	Field   string `json:"field"`
+	Desired any    `json:"desired"`
+	Actual  any    `json:"actual"`
+}
+
+// SmartlockDrift reports the changes of the smartlock's configuration
+type SmartlockDrift struct {
+	Name    string                  `json:"name"`
+	Changes []SmartlockConfigChange `json:"changes,omitempty"`
+	Drifts  []SmartlockConfigDrift  `json:"drifts,omitempty"`
+	// Enforced is true when the desired state has been pushed back to the smartlock
+	Enforced     bool   `json:"enforced"`
+	EnforceError string `json:"enforce_error,omitempty"`
+}
+
+// Format returns a human-readable field by field diff in the given language
+func (d SmartlockDrift) Format(lang string) string {
+	var lines []string
+	if len(d.Changes) > 0 {
+		lines = append(lines, i18n.T(lang, "drift.changed", emoji.Gear, d.Name))
+		for _, c := range d.Changes {
+			lines = append(lines, fmt.Sprintf("~ %s: %v → %v", c.Field, c.Old, c.New))
+		}
+	}
+	if len(d.Drifts) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, i18n.T(lang, "drift.drifted", emoji.Warning, d.Name))
+		for _, c := range d.Drifts {
+			lines = append(lines, i18n.T(lang, "drift.field", c.Field, c.Actual, c.Desired))
+		}
+		switch {
+		case d.EnforceError != "":
+			lines = append(lines, i18n.T(lang, "drift.enforce_failed", emoji.CrossMark, d.EnforceError))
+		case d.Enforced:
+			lines = append(lines, i18n.T(lang, "drift.enforced", emoji.CheckMarkButton))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,309 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
+)
+
+type NukiSmartlockMode int
+
+type NukiLockState int
+
+type NukiDoorState int
+
+type NukiServerState int
+
+var (
+	NukiSmartlockModeUncalibrated = NukiSmartlockMode(0)
+	NukiSmartlockModeCalibration  = NukiSmartlockMode(1)
+	NukiSmartlockModeDoor         = NukiSmartlockMode(2)
+	NukiSmartlockModeContinuous   = NukiSmartlockMode(3)
+	NukiSmartlockModeMaintenance  = NukiSmartlockMode(4)
+	NukiSmartlockModes            = map[NukiSmartlockMode]string{
+		NukiSmartlockModeUncalibrated: "uncalibrated",
+		NukiSmartlockModeCalibration:  "calibration",
+		NukiSmartlockModeDoor:         "door mode",
+		NukiSmartlockModeContinuous:   "continuous mode",
+		NukiSmartlockModeMaintenance:  "maintenance mode",
+	}
+
+	NukiLockStateUncalibrated = NukiLockState(0)
+	NukiLockStateLocked       = NukiLockState(1)
+	NukiLockStateUnlocking    = NukiLockState(2)
+	NukiLockStateUnlocked     = NukiLockState(3)
+	NukiLockStateLocking      = NukiLockState(4)
+	NukiLockStateUnlatched    = NukiLockState(5)
+	NukiLockStateUnlockedLNG  = NukiLockState(6)
+	NukiLockStateUnlatching   = NukiLockState(7)
+	NukiLockStateMotorBlocked = NukiLockState(254)
+	NukiLockStateUndefined    = NukiLockState(255)
+	NukiLockStates            = map[NukiLockState]string{
+		NukiLockStateUncalibrated: "uncalibrated",
+		NukiLockStateLocked:       "locked",
+		NukiLockStateUnlocking:    "unlocking",
+		NukiLockStateUnlocked:     "unlocked",
+		NukiLockStateLocking:      "locking",
+		NukiLockStateUnlatched:    "unlatched",
+		NukiLockStateUnlockedLNG:  "unlocked (lock'n'go)",
+		NukiLockStateUnlatching:   "unlatching",
+		NukiLockStateMotorBlocked: "motor blocked",
+		NukiLockStateUndefined:    "undefined",
+	}
+
+	NukiDoorStateUnavailable  = NukiDoorState(0)
+	NukiDoorStateDeactivated  = NukiDoorState(1)
+	NukiDoorStateClosed       = NukiDoorState(2)
+	NukiDoorStateOpened       = NukiDoorState(3)
+	NukiDoorStateUnknown      = NukiDoorState(4)
+	NukiDoorStateCalibrating  = NukiDoorState(5)
+	NukiDoorStateUncalibrated = NukiDoorState(16)
+	NukiDoorStateRemoved      = NukiDoorState(240)
+	NukiDoorStates            = map[NukiDoorState]string{
+		NukiDoorStateUnavailable:  "unavailable",
+		NukiDoorStateDeactivated:  "deactivated",
+		NukiDoorStateClosed:       "closed",
+		NukiDoorStateOpened:       "opened",
+		NukiDoorStateUnknown:      "unknown",
+		NukiDoorStateCalibrating:  "calibrating",
+		NukiDoorStateUncalibrated: "uncalibrated",
+		NukiDoorStateRemoved:      "removed",
+	}
+
+	NukiServerStateOK              = NukiServerState(0)
+	NukiServerStateUnregistered    = NukiServerState(1)
+	NukiServerStateAuthUUIDInvalid = NukiServerState(2)
+	NukiServerStateAuthInvalid     = NukiServerState(3)
+	NukiServerStateOffline         = NukiServerState(4)
+	NukiServerStates               = map[NukiServerState]string{
+		NukiServerStateOK:              "ok",
+		NukiServerStateUnregistered:    "unregistered",
+		NukiServerStateAuthUUIDInvalid: "auth uuid invalid",
+		NukiServerStateAuthInvalid:     "auth invalid",
+		NukiServerStateOffline:         "offline",
+	}
+)
+
+func (n NukiSmartlockMode) String() string {
+	str, ok := NukiSmartlockModes[n]
+	if !ok {
+		return "unknown"
+	}
+	return str
+}
+
+func (n NukiLockState) String() string {
+	str, ok := NukiLockStates[n]
+	if !ok {
+		return "unknown"
+	}
+	return str
+}
+
+func (n NukiDoorState) String() string {
+	str, ok := NukiDoorStates[n]
+	if !ok {
+		return "unknown"
+	}
+	return str
+}
+
+func (n NukiServerState) String() string {
+	str, ok := NukiServerStates[n]
+	if !ok {
+		return "unknown"
+	}
+	return str
+}
+
+// Translate returns the name of the mode in the given language
+func (n NukiSmartlockMode) Translate(lang string) string {
+	if _, ok := NukiSmartlockModes[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("mode.%d", n))
+}
+
+// Translate returns the name of the lock state in the given language
+func (n NukiLockState) Translate(lang string) string {
+	if _, ok := NukiLockStates[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("lock_state.%d", n))
+}
+
+// Translate returns the name of the door state in the given language
+func (n NukiDoorState) Translate(lang string) string {
+	if _, ok := NukiDoorStates[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("door_state.%d", n))
+}
+
+// Translate returns the name of the server state in the given language
+func (n NukiServerState) Translate(lang string) string {
+	if _, ok := NukiServerStates[n]; !ok {
+		return "unknown"
+	}
+	return i18n.T(lang, fmt.Sprintf("server_state.%d", n))
+}
+
+func (n NukiLockState) GetEmoji() string {
+	switch n {
+	case NukiLockStateLocked:
+		return emoji.Locked.String()
+	case NukiLockStateUnlocked, NukiLockStateUnlockedLNG:
+		return emoji.Unlocked.String()
+	case NukiLockStateUnlatched:
+		return emoji.Door.String()
+	case NukiLockStateLocking, NukiLockStateUnlocking, NukiLockStateUnlatching:
+		return emoji.HourglassNotDone.String()
+	case NukiLockStateMotorBlocked:
+		return emoji.Warning.String()
+	}
+	return emoji.QuestionMark.String()
+}
+
+func (n NukiDoorState) GetEmoji() string {
+	switch n {
+	case NukiDoorStateClosed:
+		return emoji.GreenCircle.String()
+	case NukiDoorStateOpened:
+		return emoji.OrangeCircle.String()
+	case NukiDoorStateUnknown, NukiDoorStateCalibrating, NukiDoorStateUncalibrated:
+		return emoji.Warning.String()
+	}
+	return emoji.WhiteCircle.String()
+}
+
+func (n NukiServerState) GetEmoji() string {
+	if n == NukiServerStateOK {
+		return emoji.GreenCircle.String()
+	}
+	return emoji.RedCircle.String()
+}
+
+// FormatFirmwareVersion decodes a firmware version, its bytes being the major, minor and patch versions
+func FormatFirmwareVersion(v int) string {
+	return fmt.Sprintf("%d.%d.%d", (v>>16)&0xff, (v>>8)&0xff, v&0xff)
+}
+
+// FormatHardwareVersion decodes a hardware version, its bytes being the major and minor versions
+func FormatHardwareVersion(v int) string {
+	return fmt.Sprintf("%d.%d", (v>>8)&0xff, v&0xff)
+}
+
+// SmartlockStatus is the full status of a smartlock with its enums decoded
+type SmartlockStatus struct {
+	Name                      string    `json:"name"`
+	Type                      string    `json:"type"`
+	Mode                      string    `json:"mode"`
+	LockState                 string    `json:"lock_state"`
+	DoorState                 string    `json:"door_state"`
+	LastAction                string    `json:"last_action"`
+	Trigger                   string    `json:"trigger"`
+	NightMode                 bool      `json:"night_mode"`
+	BatteryCharge             int32     `json:"battery_charge"`
+	BatteryCharging           bool      `json:"battery_charging"`
+	BatteryCritical           bool      `json:"battery_critical"`
+	KeypadBatteryCritical     bool      `json:"keypad_battery_critical"`
+	DoorsensorBatteryCritical bool      `json:"doorsensor_battery_critical"`
+	FirmwareVersion           string    `json:"firmware_version"`
+	HardwareVersion           string    `json:"hardware_version"`
+	ServerState               string    `json:"server_state"`
+	AutoLock                  bool      `json:"auto_lock"`
+	AutoLockTimeout           int       `json:"auto_lock_timeout"`
+	AutoUnlatch               bool      `json:"auto_unlatch"`
+	AutoUpdateEnabled         bool      `json:"auto_update_enabled"`
+	PairingEnabled            bool      `json:"pairing_enabled"`
+	ButtonEnabled             bool      `json:"button_enabled"`
+	LedEnabled                bool      `json:"led_enabled"`
+	LedBrightness             int       `json:"led_brightness"`
+	SingleLock                bool      `json:"single_lock"`
+	KeypadPaired              bool      `json:"keypad_paired"`
+	WifiEnabled               bool      `json:"wifi_enabled"`
+	UpdateDate                time.Time `json:"update_date"`
+}
+
+func (s SmartlockResponse) ToStatus() SmartlockStatus {
+	return SmartlockStatus{
+		Name:                      s.Name,
+		Type:                      NukiDeviceType(s.Type).String(),
+		Mode:                      s.State.Mode.String(),
+		LockState:                 s.State.State.String(),
+		DoorState:                 s.State.DoorState.String(),
+		LastAction:                s.State.LastAction.String(),
+		Trigger:                   s.State.Trigger.String(),
+		NightMode:                 s.State.NightMode,
+		BatteryCharge:             s.State.BatteryCharge,
+		BatteryCharging:           s.State.BatteryCharging,
+		BatteryCritical:           s.State.BatteryCritical,
+		KeypadBatteryCritical:     s.State.KeypadBatteryCritical,
+		DoorsensorBatteryCritical: s.State.DoorsensorBatteryCritical,
+		FirmwareVersion:           FormatFirmwareVersion(s.FirmwareVersion),
+		HardwareVersion:           FormatHardwareVersion(s.HardwareVersion),
+		ServerState:               s.ServerState.String(),
+		AutoLock:                  s.AdvancedConfig.AutoLock,
+		AutoLockTimeout:           s.AdvancedConfig.AutoLockTimeout,
+		AutoUnlatch:               s.Config.AutoUnlatch,
+		AutoUpdateEnabled:         s.AdvancedConfig.AutoUpdateEnabled,
+		PairingEnabled:            s.Config.PairingEnabled,
+		ButtonEnabled:             s.Config.ButtonEnabled,
+		LedEnabled:                s.Config.LedEnabled,
+		LedBrightness:             s.Config.LedBrightness,
+		SingleLock:                s.Config.SingleLock,
+		KeypadPaired:              s.Config.KeypadPaired || s.Config.Keypad2Paired,
+		WifiEnabled:               s.Config.WifiEnabled,
+		UpdateDate:                s.UpdateDate,
+	}
+}
+
+// StatusFormat returns a human-readable full status of the smartlock in the given language
+func (s SmartlockResponse) StatusFormat(lang string) string {
+	onOff := func(b bool) string {
+		if b {
+			return i18n.T(lang, "common.on")
+		}
+		return i18n.T(lang, "common.off")
+	}
+	batteryEmoji := emoji.GreenCircle
+	if s.State.BatteryCritical || s.State.BatteryCharge < 20 {
+		batteryEmoji = emoji.RedCircle
+	} else if s.State.BatteryCharge <= 30 {
+		batteryEmoji = emoji.OrangeCircle
+	}
+	okCritical := func(critical bool) string {
+		if critical {
+			return emoji.RedCircle.String()
+		}
+		return emoji.GreenCircle.String()
+	}
+	charging := ""
+	if s.State.BatteryCharging {
+		charging = " " + emoji.ElectricPlug.String()
+	}
+	autoLock := onOff(s.AdvancedConfig.AutoLock)
+	if s.AdvancedConfig.AutoLock && s.AdvancedConfig.AutoLockTimeout > 0 {
+		autoLock = i18n.T(lang, "status.auto_lock_timeout", autoLock, time.Duration(s.AdvancedConfig.AutoLockTimeout)*time.Second)
+	}
+
+	lines := []string{
+		i18n.T(lang, "status.title", s.State.State.GetEmoji(), s.Name, NukiDeviceType(s.Type).String()),
+		i18n.T(lang, "status.lock_state", s.State.State.GetEmoji(), s.State.State.Translate(lang)),
+		i18n.T(lang, "status.door_state", s.State.DoorState.GetEmoji(), s.State.DoorState.Translate(lang)),
+		i18n.T(lang, "status.mode", s.State.Mode.Translate(lang)),
+		i18n.T(lang, "status.last_action", s.State.LastAction.Translate(lang), s.State.Trigger.GetEmoji(), s.State.Trigger.Translate(lang)),
+		i18n.T(lang, "status.night_mode", emoji.CrescentMoon.String(), onOff(s.State.NightMode)),
+		i18n.T(lang, "status.battery", batteryEmoji.String(), s.State.BatteryCharge, charging),
+		i18n.T(lang, "status.accessories_battery", okCritical(s.State.KeypadBatteryCritical), okCritical(s.State.DoorsensorBatteryCritical)),
+		i18n.T(lang, "status.versions", FormatFirmwareVersion(s.FirmwareVersion), FormatHardwareVersion(s.HardwareVersion), onOff(s.AdvancedConfig.AutoUpdateEnabled)),
+		i18n.T(lang, "status.server_state", s.ServerState.GetEmoji(), s.ServerState.Translate(lang)),
+		i18n.T(lang, "status.auto_lock", autoLock),
+		i18n.T(lang, "status.settings",
+			onOff(s.Config.AutoUnlatch), onOff(s.Config.ButtonEnabled), onOff(s.Config.LedEnabled), onOff(s.Config.PairingEnabled)),
+	}
+	return strings.Join(lines, "\n")
+}
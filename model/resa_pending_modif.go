@@ -23,6 +23,12 @@ type ReservationPendingModification struct {
 	LinkedReservation *NukiReservationResponse `json:"linked_reservation"`
 	FromChatID        int64                    `json:"from_chat_id"`
 	LastUpdateTime    time.Time                `json:"last_update_time"`
+	// GuestName is the name of the guest when known before the reservation is synced to Nuki
+	GuestName string `json:"guest_name,omitempty"`
+	// Source is the name of the booking feed the modification was imported from, empty when entered manually
+	Source string `json:"source,omitempty"`
+	// NameOnly keeps the guest name of a reservation without changing its access times
+	NameOnly bool `json:"name_only,omitempty"`
 }
 
 func (r ReservationPendingModification) FormatCheckIn() string {
@@ -33,13 +39,29 @@ func (r ReservationPendingModification) FormatCheckOut() string {
 	return r.CheckOutTime.Format(FormatTimeHoursMinutes)
 }
 
+// FormatAccessTimes returns the check in and check out times, empty when the access times are not changed
+func (r ReservationPendingModification) FormatAccessTimes() string {
+	if r.NameOnly {
+		return ""
+	}
+	return r.FormatCheckIn() + " - " + r.FormatCheckOut()
+}
+
+// IsImported returns true if the modification comes from a booking feed
+func (r ReservationPendingModification) IsImported() bool {
+	return r.Source != ""
+}
+
 func (r ReservationPendingModification) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("reservation_ref", r.ReservationRef).
 		Time("check_in", r.CheckInTime).
 		Time("check_out", r.CheckOutTime).
 		Bool("modification_done", r.ModificationDone).
 		Bool("linked_reservation_is_nil", r.LinkedReservation == nil).
-		Int64("from_chat_id", r.FromChatID)
+		Int64("from_chat_id", r.FromChatID).
+		Str("guest_name", r.GuestName).
+		Str("source", r.Source).
+		Bool("name_only", r.NameOnly)
 }
 
 func MinutesFromMidnight(t time.Time) int32 {
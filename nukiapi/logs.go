@@ -20,7 +20,7 @@ func (r LogsReader) Execute() ([]model.NukiSmartlockLogResponse, error) {
 	if r.SmartlockID == 0 {
 		return nil, fmt.Errorf("smartlockid is mandatory")
 	}
-	if r.Token == "" {
+	if r.Token.Get() == "" {
 		return nil, fmt.Errorf("token is mandatory")
 	}
 	if r.Limit < 0 {
@@ -51,3 +51,33 @@ func (r LogsReader) Execute() ([]model.NukiSmartlockLogResponse, error) {
 	err = json.Unmarshal(body, &responses)
 	return responses, err
 }
+
+// ExecuteAll gets all logs between FromDate and ToDate, newest first, paginating over the API's limit.
+// At most maxRequests calls are made to the API, older logs being dropped when this limit is reached.
+func (r LogsReader) ExecuteAll(maxRequests int) ([]model.NukiSmartlockLogResponse, error) {
+	var res []model.NukiSmartlockLogResponse
+	seen := make(map[string]bool)
+	r.Limit = 50
+	for i := 0; i < maxRequests; i++ {
+		logs, err := r.Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		progress := false
+		for _, l := range logs {
+			if seen[l.ID] {
+				continue
+			}
+			seen[l.ID] = true
+			progress = true
+			res = append(res, l)
+		}
+		if len(logs) < r.Limit || !progress {
+			break
+		}
+		// Dates sent to the API have a precision of one second, already seen logs are skipped above
+		r.ToDate = logs[len(logs)-1].Date.Truncate(time.Second)
+	}
+	return res, nil
+}
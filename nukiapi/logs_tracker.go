@@ -0,0 +1,99 @@
+package nukiapi
+
+import (
+	"slices"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// LogsTrackerWindow is how long logs ids are remembered, older logs being ignored
+	LogsTrackerWindow = time.Hour * 24 * 7
+	// logsTrackerMaxLogs is the number of most recent logs kept
+	logsTrackerMaxLogs = 100
+	// backfillMaxRequests limits the requests made to get the logs missing after a gap
+	backfillMaxRequests = 10
+)
+
+// LogsTracker polls the logs API and finds the new logs by their ids, paging back when a gap is detected.
+// It copes with more logs than the page limit occurring between polls, logs arriving late
+// and the API sometimes not returning the most recent logs.
+type LogsTracker struct {
+	reader LogsReader
+	window *model.LogsWindow
+	logs   []model.NukiSmartlockLogResponse
+}
+
+// NewLogsTracker creates a tracker considering known logs as already seen
+func NewLogsTracker(reader LogsReader, known []model.NukiSmartlockLogResponse) *LogsTracker {
+	t := &LogsTracker{
+		reader: reader,
+		window: model.NewLogsWindow(LogsTrackerWindow, known),
+	}
+	t.merge(known)
+	return t
+}
+
+// Poll returns the logs not seen yet, oldest first. They are seen only once committed.
+func (t *LogsTracker) Poll() ([]model.NukiSmartlockLogResponse, error) {
+	page, err := t.reader.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.window.HasGap(page, t.reader.Limit) {
+		r := t.reader
+		r.FromDate = t.window.Latest().Truncate(time.Second)
+		// Dates sent to the API have a precision of one second, logs already in the page are deduplicated below
+		r.ToDate = page[len(page)-1].Date.Truncate(time.Second)
+		older, err := r.ExecuteAll(backfillMaxRequests)
+		if err != nil {
+			log.Error().Err(err).
+				Time("from", r.FromDate).
+				Time("to", r.ToDate).
+				Msg("Gap detected in logs, unable to get the missing ones")
+		} else {
+			log.Warn().
+				Time("from", r.FromDate).
+				Time("to", r.ToDate).
+				Int("backfilled", len(older)).
+				Msg("Gap detected in logs, getting the missing ones")
+			page = append(page, older...)
+		}
+	}
+
+	logs, late := t.window.New(page)
+	if late > 0 {
+		log.Info().
+			Int("count", late).
+			Msg("Logs returned late by the API")
+	}
+	return logs, nil
+}
+
+// Commit marks logs as seen
+func (t *LogsTracker) Commit(logs []model.NukiSmartlockLogResponse) {
+	t.window.Add(logs)
+	t.merge(logs)
+}
+
+// Logs returns the most recent logs seen, newest first
+func (t *LogsTracker) Logs() []model.NukiSmartlockLogResponse {
+	return slices.Clone(t.logs)
+}
+
+func (t *LogsTracker) merge(logs []model.NukiSmartlockLogResponse) {
+	t.logs = append(t.logs, logs...)
+	slices.SortStableFunc(t.logs, func(a, b model.NukiSmartlockLogResponse) int {
+		return b.Date.Compare(a.Date)
+	})
+	ids := make(map[string]bool)
+	t.logs = slices.DeleteFunc(t.logs, func(l model.NukiSmartlockLogResponse) bool {
+		duplicate := ids[l.ID]
+		ids[l.ID] = true
+		return duplicate
+	})
+	t.logs = t.logs[:min(len(t.logs), logsTrackerMaxLogs)]
+}
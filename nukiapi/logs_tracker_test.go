@@ -0,0 +1,213 @@
+package nukiapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/model"
+)
+
+var testLogsBase = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testLog(i int) model.NukiSmartlockLogResponse {
+	return model.NukiSmartlockLogResponse{ID: fmt.Sprint("log", i), Date: testLogsBase.Add(time.Duration(i) * time.Minute)}
+}
+
+func testLogs(from, to int) []model.NukiSmartlockLogResponse {
+	var res []model.NukiSmartlockLogResponse
+	for i := from; i <= to; i++ {
+		res = append(res, testLog(i))
+	}
+	return res
+}
+
+// fakeLogsAPI serves logs like the Nuki API, newest first and filtered by the limit, fromDate and toDate parameters
+type fakeLogsAPI struct {
+	logs []model.NukiSmartlockLogResponse
+	// hideLatest is the number of most recent logs missing from undated requests, as the API sometimes does
+	hideLatest int
+	// reverse returns pages oldest first
+	reverse  bool
+	requests int
+}
+
+func (f *fakeLogsAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.requests++
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	from, _ := time.Parse(time.RFC3339, q.Get("fromDate"))
+	to, _ := time.Parse(time.RFC3339, q.Get("toDate"))
+
+	logs := slices.Clone(f.logs)
+	slices.SortFunc(logs, func(a, b model.NukiSmartlockLogResponse) int { return b.Date.Compare(a.Date) })
+	if from.IsZero() && to.IsZero() {
+		logs = logs[min(f.hideLatest, len(logs)):]
+	}
+	// Dates have a precision of one second and are inclusive
+	logs = slices.DeleteFunc(logs, func(l model.NukiSmartlockLogResponse) bool {
+		return (!from.IsZero() && l.Date.Before(from)) || (!to.IsZero() && !l.Date.Before(to.Add(time.Second)))
+	})
+	logs = logs[:min(limit, len(logs))]
+	if f.reverse {
+		slices.Reverse(logs)
+	}
+
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+// newFakeLogsReader returns a reader getting its logs from api instead of the Nuki API
+func newFakeLogsReader(t *testing.T, api *fakeLogsAPI, limit int) LogsReader {
+	transport := http.DefaultTransport
+	http.DefaultTransport = api
+	t.Cleanup(func() { http.DefaultTransport = transport })
+	return LogsReader{
+		APICaller:   APICaller{Token: NewToken("token")},
+		SmartlockID: 1,
+		Limit:       limit,
+	}
+}
+
+func ids(logs []model.NukiSmartlockLogResponse) []string {
+	res := make([]string, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, l.ID)
+	}
+	return res
+}
+
+func TestLogsTrackerPoll(t *testing.T) {
+	tests := []struct {
+		name         string
+		known        []model.NukiSmartlockLogResponse
+		api          fakeLogsAPI
+		limit        int
+		want         []model.NukiSmartlockLogResponse
+		wantRequests int
+	}{
+		{
+			name:         "new logs",
+			known:        testLogs(1, 5),
+			api:          fakeLogsAPI{logs: testLogs(1, 8)},
+			limit:        20,
+			want:         testLogs(6, 8),
+			wantRequests: 1,
+		},
+		{
+			name:         "no new log",
+			known:        testLogs(1, 5),
+			api:          fakeLogsAPI{logs: testLogs(1, 5)},
+			limit:        20,
+			wantRequests: 1,
+		},
+		{
+			name:  "gap backfilled",
+			known: testLogs(1, 5),
+			api:   fakeLogsAPI{logs: testLogs(1, 40)},
+			limit: 10,
+			want:  testLogs(6, 40),
+			// The page then one backfill request between the latest log seen and the page
+			wantRequests: 2,
+		},
+		{
+			name:  "backfill capped",
+			known: testLogs(1, 1),
+			api:   fakeLogsAPI{logs: testLogs(1, 700)},
+			limit: 20,
+			// Each backfill request of 50 logs shares its oldest one with the next, older logs being dropped
+			want:         testLogs(700-20+1-backfillMaxRequests*49, 700),
+			wantRequests: 1 + backfillMaxRequests,
+		},
+		{
+			name:         "late entries",
+			known:        append(testLogs(1, 3), testLogs(5, 6)...),
+			api:          fakeLogsAPI{logs: testLogs(1, 7)},
+			limit:        20,
+			want:         []model.NukiSmartlockLogResponse{testLog(4), testLog(7)},
+			wantRequests: 1,
+		},
+		{
+			name:         "reordered page",
+			known:        testLogs(1, 2),
+			api:          fakeLogsAPI{logs: testLogs(1, 6), reverse: true},
+			limit:        20,
+			want:         testLogs(3, 6),
+			wantRequests: 1,
+		},
+		{
+			name:         "latest entries missing",
+			known:        testLogs(1, 2),
+			api:          fakeLogsAPI{logs: testLogs(1, 6), hideLatest: 2},
+			limit:        20,
+			want:         testLogs(3, 4),
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := tt.api
+			tracker := NewLogsTracker(newFakeLogsReader(t, &api, tt.limit), tt.known)
+			got, err := tracker.Poll()
+			if err != nil {
+				t.Fatalf("Poll() error = %v", err)
+			}
+			if !slices.Equal(ids(got), ids(tt.want)) {
+				t.Errorf("Poll() = %v, want %v", ids(got), ids(tt.want))
+			}
+			if api.requests != tt.wantRequests {
+				t.Errorf("Poll() made %d requests, want %d", api.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestLogsTrackerCommit(t *testing.T) {
+	api := &fakeLogsAPI{logs: testLogs(1, 5), hideLatest: 1}
+	tracker := NewLogsTracker(newFakeLogsReader(t, api, 20), testLogs(1, 2))
+
+	poll := func(want []model.NukiSmartlockLogResponse) []model.NukiSmartlockLogResponse {
+		t.Helper()
+		got, err := tracker.Poll()
+		if err != nil {
+			t.Fatalf("Poll() error = %v", err)
+		}
+		if !slices.Equal(ids(got), ids(want)) {
+			t.Fatalf("Poll() = %v, want %v", ids(got), ids(want))
+		}
+		return got
+	}
+
+	// Logs not committed are returned again
+	poll(testLogs(3, 4))
+	logs := poll(testLogs(3, 4))
+	tracker.Commit(logs)
+	poll(nil)
+
+	// The log the API was missing shows up later
+	api.hideLatest = 0
+	api.logs = append(api.logs, testLog(6))
+	tracker.Commit(poll(testLogs(5, 6)))
+	poll(nil)
+
+	got, want := ids(tracker.Logs()), ids(testLogs(1, 6))
+	slices.Reverse(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("Logs() = %v, want %v", got, want)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -15,10 +18,67 @@ const (
 	ReservationsEndpoint  = "address/%d/reservation"
 	SmartlockEndpoint     = "smartlock/%d"
 	SmartlockAuthEndpoint = "smartlock/%d/auth"
+	// Configuration endpoints of the smartlock, by section
+	SmartlockConfigEndpoint         = "smartlock/%d/config"
+	SmartlockAdvancedConfigEndpoint = "smartlock/%d/advanced/config"
+	SmartlockWebConfigEndpoint      = "smartlock/%d/web/config"
 )
 
+// ThrottledError is returned when the Nuki API asks to slow down
+type ThrottledError struct {
+	// RetryAfter is the delay requested by the API, zero when not given
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e ThrottledError) Error() string {
+	return fmt.Sprintf("Nuki API is throttling requests (retry after: %s): %s", e.RetryAfter, e.Body)
+}
+
+// checkThrottling returns a ThrottledError if resp has a 429 status
+func checkThrottling(resp *http.Response, body []byte) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	var retryAfter time.Duration
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(v); err == nil {
+			retryAfter = time.Until(date)
+		}
+	}
+	return ThrottledError{RetryAfter: retryAfter, Body: string(body)}
+}
+
+// Token is a Nuki API token shared by API callers, which can be rotated while they are in use
+type Token struct {
+	value atomic.Pointer[string]
+}
+
+func NewToken(token string) *Token {
+	t := &Token{}
+	t.Set(token)
+	return t
+}
+
+// Get returns the token, empty if t is nil
+func (t *Token) Get() string {
+	if t == nil {
+		return ""
+	}
+	if v := t.value.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+func (t *Token) Set(token string) {
+	t.value.Store(&token)
+}
+
 type APICaller struct {
-	Token string
+	Token *Token
 }
 
 func (c APICaller) execAPIGet(requestURL string) ([]byte, error) {
@@ -31,7 +91,7 @@ func (c APICaller) execAPIGet(requestURL string) ([]byte, error) {
 	}
 	httpReq.Header = http.Header{
 		"Content-Type":  {"application/json"},
-		"Authorization": {fmt.Sprintf("Bearer %s", c.Token)},
+		"Authorization": {fmt.Sprintf("Bearer %s", c.Token.Get())},
 	}
 	client := http.Client{}
 	resp, err := client.Do(httpReq)
@@ -45,6 +105,9 @@ func (c APICaller) execAPIGet(requestURL string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if err := checkThrottling(resp, body); err != nil {
+		return nil, err
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("error while querying Nuki API (status: %s): %s", resp.Status, string(body))
 	}
@@ -53,16 +116,24 @@ func (c APICaller) execAPIGet(requestURL string) ([]byte, error) {
 }
 
 func (c APICaller) execAPIPost(requestURL string, body []byte) ([]byte, error) {
+	return c.execAPIWithBody(http.MethodPost, requestURL, body)
+}
+
+func (c APICaller) execAPIDelete(requestURL string) ([]byte, error) {
+	return c.execAPIWithBody(http.MethodDelete, requestURL, nil)
+}
+
+func (c APICaller) execAPIWithBody(method, requestURL string, body []byte) ([]byte, error) {
 	log.Debug().
 		Str("request_url", requestURL).
-		Msg("Calling Nuki API (POST)")
-	httpReq, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewBuffer(body))
+		Msgf("Calling Nuki API (%s)", method)
+	httpReq, err := http.NewRequest(method, requestURL, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header = http.Header{
 		"Content-Type":  {"application/json"},
-		"Authorization": {fmt.Sprintf("Bearer %s", c.Token)},
+		"Authorization": {fmt.Sprintf("Bearer %s", c.Token.Get())},
 	}
 	client := http.Client{}
 	resp, err := client.Do(httpReq)
@@ -76,6 +147,9 @@ func (c APICaller) execAPIPost(requestURL string, body []byte) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if err := checkThrottling(resp, bodyRes); err != nil {
+		return nil, err
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("error while querying Nuki API (status: %s): %s", resp.Status, string(bodyRes))
 	}
@@ -3,12 +3,15 @@ package nukiapi
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/nmaupu/nuki-logger/model"
 )
 
 var (
+	// reservationsCache is shared by the bot, the API and the server, guarded by reservationsCacheMutex
+	reservationsCacheMutex      sync.RWMutex
 	reservationsCache           = map[string]string{}
 	reservationsCacheLastUpdate time.Time
 	reservationsCacheTimeout    = time.Hour * 2
@@ -23,7 +26,7 @@ func (r ReservationsReader) Execute() ([]model.NukiReservationResponse, error) {
 	if r.AddressID == 0 {
 		return nil, fmt.Errorf("addressid is mandatory")
 	}
-	if r.Token == "" {
+	if r.Token.Get() == "" {
 		return nil, fmt.Errorf("token is mandatory")
 	}
 
@@ -45,12 +48,11 @@ func (r ReservationsReader) GetReservationName(ref string) (string, error) {
 		return "", nil
 	}
 
-	var ok bool
-	var reservationName string
-	reservationName, ok = reservationsCache[ref]
-	if ok &&
-		!reservationsCacheLastUpdate.IsZero() &&
-		time.Since(reservationsCacheLastUpdate) < reservationsCacheTimeout {
+	reservationsCacheMutex.RLock()
+	reservationName, ok := reservationsCache[ref]
+	fresh := !reservationsCacheLastUpdate.IsZero() && time.Since(reservationsCacheLastUpdate) < reservationsCacheTimeout
+	reservationsCacheMutex.RUnlock()
+	if ok && fresh {
 		return reservationName, nil
 	}
 
@@ -59,12 +61,14 @@ func (r ReservationsReader) GetReservationName(ref string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	reservationsCacheMutex.Lock()
 	for _, resa := range reservations {
 		reservationsCache[resa.Reference] = resa.Name
 	}
 	reservationsCacheLastUpdate = time.Now()
-
 	reservationName, ok = reservationsCache[ref]
+	reservationsCacheMutex.Unlock()
+
 	if !ok {
 		return "", fmt.Errorf("unable to find ref '%s'", ref)
 	}
@@ -80,7 +84,7 @@ func (r ReservationTimeModifier) Execute(resaID string, checkIn int32, checkOut
 	if r.AddressID == 0 {
 		return fmt.Errorf("addressid is mandatory")
 	}
-	if r.Token == "" {
+	if r.Token.Get() == "" {
 		return fmt.Errorf("token is mandatory")
 	}
 
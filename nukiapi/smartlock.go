@@ -15,7 +15,7 @@ func (r SmartlockReader) Execute() (*model.SmartlockResponse, error) {
 	if r.SmartlockID == 0 {
 		return nil, fmt.Errorf("smartlockid is mandatory")
 	}
-	if r.Token == "" {
+	if r.Token.Get() == "" {
 		return nil, fmt.Errorf("token is mandatory")
 	}
 
@@ -15,7 +15,7 @@ func (r SmartlockAuthReader) Execute() ([]model.SmartlockAuthResponse, error) {
 	if r.SmartlockID == 0 {
 		return nil, fmt.Errorf("smartlockid is mandatory")
 	}
-	if r.Token == "" {
+	if r.Token.Get() == "" {
 		return nil, fmt.Errorf("token is mandatory")
 	}
 
@@ -29,3 +29,64 @@ func (r SmartlockAuthReader) Execute() ([]model.SmartlockAuthResponse, error) {
 	err = json.Unmarshal(body, &responses)
 	return responses, err
 }
+
+// SmartlockAuthModifier changes or deletes authorizations of the smartlock
+type SmartlockAuthModifier struct {
+	APICaller
+	SmartlockID int64
+}
+
+// SetEnabled enables or disables an authorization, the API expecting its name on update
+func (r SmartlockAuthModifier) SetEnabled(auth model.SmartlockAuthResponse, enabled bool) error {
+	if err := r.check(auth); err != nil {
+		return err
+	}
+
+	requestURL := fmt.Sprintf("%s/%s/%s", Api, fmt.Sprintf(SmartlockAuthEndpoint, r.SmartlockID), auth.Id)
+	bodyPost := struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+	}{
+		Name:    auth.Name,
+		Enabled: enabled,
+	}
+	bodyJSON, err := json.Marshal(bodyPost)
+	if err != nil {
+		return err
+	}
+
+	body, err := r.execAPIPost(requestURL, bodyJSON)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w, body=%s", err, string(body))
+	}
+
+	return nil
+}
+
+// Delete deletes an authorization
+func (r SmartlockAuthModifier) Delete(auth model.SmartlockAuthResponse) error {
+	if err := r.check(auth); err != nil {
+		return err
+	}
+
+	requestURL := fmt.Sprintf("%s/%s/%s", Api, fmt.Sprintf(SmartlockAuthEndpoint, r.SmartlockID), auth.Id)
+	body, err := r.execAPIDelete(requestURL)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w, body=%s", err, string(body))
+	}
+
+	return nil
+}
+
+func (r SmartlockAuthModifier) check(auth model.SmartlockAuthResponse) error {
+	if r.SmartlockID == 0 {
+		return fmt.Errorf("smartlockid is mandatory")
+	}
+	if r.Token.Get() == "" {
+		return fmt.Errorf("token is mandatory")
+	}
+	if auth.Id == "" {
+		return fmt.Errorf("auth id is mandatory")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package nukiapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Sections of the smartlock's configuration, named after their JSON field in the smartlock's response
+const (
+	SectionConfig         = "config"
+	SectionAdvancedConfig = "advancedConfig"
+	SectionWebConfig      = "webConfig"
+)
+
+var sectionEndpoints = map[string]string{
+	SectionConfig:         SmartlockConfigEndpoint,
+	SectionAdvancedConfig: SmartlockAdvancedConfigEndpoint,
+	SectionWebConfig:      SmartlockWebConfigEndpoint,
+}
+
+// readOnlyFields are returned by the API in each section but cannot be updated
+var readOnlyFields = map[string][]string{
+	SectionConfig: {
+		"fobPaired", "keypadPaired", "keypad2Paired", "homekitState", "matterState", "deviceType", "wifiEnabled",
+	},
+	SectionAdvancedConfig: {"totalDegrees"},
+}
+
+// IsReadOnlyConfigField returns true if field of section cannot be updated
+func IsReadOnlyConfigField(section, field string) bool {
+	return slices.Contains(readOnlyFields[section], field)
+}
+
+// SmartlockConfigUpdater updates a section of the smartlock's configuration
+type SmartlockConfigUpdater struct {
+	APICaller
+	SmartlockID int64
+}
+
+// Read returns the configuration sections as sent by the API, including the fields not modeled by
+// model.SmartlockResponse so that they are sent back unchanged
+func (r SmartlockConfigUpdater) Read() (map[string]map[string]any, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+
+	requestURL := fmt.Sprintf("%s/%s", Api, fmt.Sprintf(SmartlockEndpoint, r.SmartlockID))
+	body, err := r.execAPIGet(requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	res := make(map[string]map[string]any)
+	for section := range sectionEndpoints {
+		raw, ok := response[section]
+		if !ok {
+			continue
+		}
+		var values map[string]any
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, fmt.Errorf("%s: %w", section, err)
+		}
+		res[section] = values
+	}
+	return res, nil
+}
+
+// Execute sends config to the section's endpoint, the API expecting the whole section.
+// Read-only fields are left out.
+func (r SmartlockConfigUpdater) Execute(section string, config map[string]any) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	endpoint, ok := sectionEndpoints[section]
+	if !ok {
+		return fmt.Errorf("unknown configuration section %s", section)
+	}
+
+	values := maps.Clone(config)
+	for _, field := range readOnlyFields[section] {
+		delete(values, field)
+	}
+	requestURL := fmt.Sprintf("%s/%s", Api, fmt.Sprintf(endpoint, r.SmartlockID))
+	bodyJSON, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	body, err := r.execAPIPost(requestURL, bodyJSON)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w, body=%s", err, string(body))
+	}
+
+	return nil
+}
+
+func (r SmartlockConfigUpdater) check() error {
+	if r.SmartlockID == 0 {
+		return fmt.Errorf("smartlockid is mandatory")
+	}
+	if r.Token.Get() == "" {
+		return fmt.Errorf("token is mandatory")
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultMaxAttempts = 10
+	DefaultMinBackoff  = time.Second * 30
+	DefaultMaxBackoff  = time.Hour
+	// retryCheckInterval is how often pending entries are checked for a new attempt
+	retryCheckInterval = time.Second * 10
+)
+
+// Config configures the durable outbox in which events are written before being delivered to senders
+type Config struct {
+	// Dir is where entries are stored, the outbox is disabled when empty
+	Dir string `mapstructure:"dir"`
+	// MaxAttempts is the number of attempts after which an entry is dead-lettered
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// MinBackoff is the delay before the first retry, doubled after each failed attempt up to MaxBackoff
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+func (c Config) IsEnabled() bool {
+	return c.Dir != ""
+}
+
+func (c Config) GetMaxAttempts() int {
+	if c.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+func (c Config) backoff(attempts int) time.Duration {
+	minBackoff, maxBackoff := c.MinBackoff, c.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	d := minBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+// Outbox delivers events to senders, each one from its own worker, until they succeed or are dead-lettered
+type Outbox struct {
+	config  Config
+	store   Store
+	senders map[string]messaging.Sender
+	notify  map[string]chan struct{}
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func New(config Config, senders []messaging.Sender) (*Outbox, error) {
+	o := &Outbox{
+		config:  config,
+		store:   Store{Dir: config.Dir},
+		senders: make(map[string]messaging.Sender),
+		notify:  make(map[string]chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	if err := o.store.init(); err != nil {
+		return nil, err
+	}
+	for _, s := range senders {
+		o.senders[s.GetName()] = s
+		o.notify[s.GetName()] = make(chan struct{}, 1)
+	}
+
+	pending, err := o.store.Pending()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range pending {
+		if _, ok := o.senders[e.Sender]; !ok {
+			log.Warn().
+				Str("id", e.ID).
+				Str("sender", e.Sender).
+				Msg("Outbox entry for a sender not in use, keeping it")
+		}
+	}
+	return o, nil
+}
+
+// Enqueue durably stores events for every sender, they are delivered in the background.
+// Events must not be considered handled when an error is returned.
+func (o *Outbox) Enqueue(kind string, events []*messaging.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	entries := make([]Entry, 0, len(o.senders))
+	for name := range o.senders {
+		entries = append(entries, Entry{
+			ID:          newEntryID(now),
+			Sender:      name,
+			Kind:        kind,
+			Events:      events,
+			CreatedAt:   now,
+			NextAttempt: now,
+		})
+	}
+	if err := o.store.writeAll(pendingDir, entries); err != nil {
+		return err
+	}
+	for _, ch := range o.notify {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Start starts a delivery worker per sender
+func (o *Outbox) Start() {
+	for name, sender := range o.senders {
+		o.wg.Add(1)
+		go o.worker(sender, o.notify[name])
+	}
+}
+
+// Stop waits for the deliveries in progress, remaining entries being delivered at next start
+func (o *Outbox) Stop() {
+	close(o.stop)
+	o.wg.Wait()
+}
+
+func (o *Outbox) worker(sender messaging.Sender, notify <-chan struct{}) {
+	defer o.wg.Done()
+	ticker := time.NewTicker(retryCheckInterval)
+	defer ticker.Stop()
+
+	o.deliver(sender)
+	for {
+		select {
+		case <-notify:
+			o.deliver(sender)
+		case <-ticker.C:
+			o.deliver(sender)
+		case <-o.stop:
+			return
+		}
+	}
+}
+
+// send delivers the entry's events, resumable senders skipping the parts delivered by a previous attempt
+func send(sender messaging.Sender, e *Entry) error {
+	rs, ok := sender.(messaging.ResumableSender)
+	if !ok {
+		return sender.Send(e.Events)
+	}
+	delivered, err := rs.SendFrom(e.Events, e.Delivered)
+	e.Delivered = delivered
+	return err
+}
+
+// deliver sends the sender's due entries in order, stopping at the first failure to keep events ordered
+func (o *Outbox) deliver(sender messaging.Sender) {
+	entries, err := o.store.list(pendingDir, sender.GetName())
+	if err != nil {
+		log.Error().Err(err).Str("sender", sender.GetName()).Msg("Unable to read outbox")
+		return
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		if e.NextAttempt.After(now) {
+			return
+		}
+
+		err := send(sender, &e)
+		if err == nil {
+			if err := o.store.remove(pendingDir, e); err != nil {
+				log.Error().Err(err).Str("id", e.ID).Msg("Unable to remove delivered outbox entry")
+			}
+			continue
+		}
+
+		e.Attempts++
+		e.LastError = err.Error()
+		if e.Attempts >= o.config.GetMaxAttempts() {
+			log.Error().Err(err).
+				Str("id", e.ID).
+				Str("sender", e.Sender).
+				Int("attempts", e.Attempts).
+				Msg("Unable to send events, moving them to dead letters")
+			if err := o.store.move(pendingDir, deadDir, e); err != nil {
+				log.Error().Err(err).Str("id", e.ID).Msg("Unable to dead-letter outbox entry")
+				return
+			}
+			continue
+		}
+
+		e.NextAttempt = now.Add(o.config.backoff(e.Attempts))
+		log.Warn().Err(err).
+			Str("id", e.ID).
+			Str("sender", e.Sender).
+			Int("attempts", e.Attempts).
+			Time("next_attempt", e.NextAttempt).
+			Msg("Unable to send events, will retry")
+		if err := o.store.write(pendingDir, e); err != nil {
+			log.Error().Err(err).Str("id", e.ID).Msg("Unable to update outbox entry")
+		}
+		return
+	}
+}
@@ -0,0 +1,208 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	pendingDir = "pending"
+	deadDir    = "dead"
+)
+
+var (
+	ErrEntryNotFound = errors.New("outbox entry not found")
+	sequence         atomic.Uint32
+)
+
+// Entry holds events to deliver to a sender
+type Entry struct {
+	ID          string             `json:"id"`
+	Sender      string             `json:"sender"`
+	Kind        string             `json:"kind"`
+	Events      []*messaging.Event `json:"events"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Attempts    int                `json:"attempts"`
+	NextAttempt time.Time          `json:"next_attempt"`
+	LastError   string             `json:"last_error,omitempty"`
+	// Delivered is the number of parts already delivered by a resumable sender, skipped when retrying
+	Delivered int `json:"delivered,omitempty"`
+}
+
+func newEntryID(now time.Time) string {
+	// Sorting ids sorts entries by creation
+	return fmt.Sprintf("%019d-%05d", now.UnixNano(), sequence.Add(1)%100000)
+}
+
+// Store keeps entries as one json file each, pending ones and dead letters being in separate directories
+type Store struct {
+	Dir string
+}
+
+func (s Store) init() error {
+	for _, d := range []string{pendingDir, deadDir} {
+		if err := os.MkdirAll(filepath.Join(s.Dir, d), 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileName(e Entry) string {
+	return fmt.Sprintf("%s_%s.json", e.ID, url.PathEscape(e.Sender))
+}
+
+// write saves the entry atomically so that a crash never leaves a truncated file
+func (s Store) write(dir string, e Entry) error {
+	tmp, err := writeTemp(filepath.Join(s.Dir, dir), e)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	return os.Rename(tmp, filepath.Join(s.Dir, dir, fileName(e)))
+}
+
+// writeAll saves entries together: they are staged first then renamed, the ones already renamed being removed
+// if one fails so that retrying never duplicates entries
+func (s Store) writeAll(dir string, entries []Entry) error {
+	stage, err := os.MkdirTemp(s.Dir, ".stage-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+
+	staged := make([]string, 0, len(entries))
+	for _, e := range entries {
+		tmp, err := writeTemp(stage, e)
+		if err != nil {
+			return err
+		}
+		staged = append(staged, tmp)
+	}
+	for i, e := range entries {
+		if err := os.Rename(staged[i], filepath.Join(s.Dir, dir, fileName(e))); err != nil {
+			for _, done := range entries[:i] {
+				if err := s.remove(dir, done); err != nil {
+					log.Error().Err(err).Str("id", done.ID).Msg("Unable to roll back outbox entry")
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTemp writes the entry to a synced temporary file of dir, returning its path
+func writeTemp(dir string, e Entry) (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func (s Store) remove(dir string, e Entry) error {
+	err := os.Remove(filepath.Join(s.Dir, dir, fileName(e)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// move moves an entry from a directory to another, writing its new state first
+func (s Store) move(from, to string, e Entry) error {
+	if err := s.write(to, e); err != nil {
+		return err
+	}
+	return s.remove(from, e)
+}
+
+// list returns the entries of a directory sorted by creation, only the ones of sender if not empty
+func (s Store) list(dir, sender string) ([]Entry, error) {
+	files, err := os.ReadDir(filepath.Join(s.Dir, dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir, dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("unable to read outbox entry %s: %w", name, err)
+		}
+		// Sender names can contain the file name's separator, only the entry is reliable
+		if sender != "" && e.Sender != sender {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.ID, b.ID) })
+	return entries, nil
+}
+
+// Pending returns the entries not delivered yet
+func (s Store) Pending() ([]Entry, error) {
+	return s.list(pendingDir, "")
+}
+
+// DeadLetters returns the entries given up after too many attempts
+func (s Store) DeadLetters() ([]Entry, error) {
+	return s.list(deadDir, "")
+}
+
+// Replay moves a dead letter back to the pending entries, resetting its attempts
+func (s Store) Replay(id string) error {
+	dead, err := s.DeadLetters()
+	if err != nil {
+		return err
+	}
+	for _, e := range dead {
+		if e.ID != id {
+			continue
+		}
+		e.Attempts = 0
+		e.NextAttempt = time.Time{}
+		return s.move(deadDir, pendingDir, e)
+	}
+	return fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+}
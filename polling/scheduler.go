@@ -0,0 +1,158 @@
+package polling
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultFastInterval        = time.Second * 15
+	DefaultSlowInterval        = time.Minute * 5
+	DefaultWindowBefore        = time.Hour
+	DefaultWindowAfter         = time.Hour
+	DefaultReservationsRefresh = time.Minute * 30
+	DefaultSmartlockInterval   = time.Hour * 2
+	DefaultMaxBackoff          = time.Minute * 30
+)
+
+// Config configures the adaptive polling of the logs API
+type Config struct {
+	// Enabled adapts the polling interval to reservations, the server's interval being used otherwise
+	Enabled bool `mapstructure:"enabled"`
+	// FastInterval is used around check-ins and check-outs
+	FastInterval time.Duration `mapstructure:"fast_interval"`
+	// SlowInterval is used when no reservation is in progress
+	SlowInterval time.Duration `mapstructure:"slow_interval"`
+	// WindowBefore and WindowAfter define the windows around check-ins and check-outs
+	WindowBefore time.Duration `mapstructure:"window_before"`
+	WindowAfter  time.Duration `mapstructure:"window_after"`
+	// ReservationsRefresh is how often reservations are read again
+	ReservationsRefresh time.Duration `mapstructure:"reservations_refresh"`
+	// SmartlockInterval is how often the smartlock is checked for issues
+	SmartlockInterval time.Duration `mapstructure:"smartlock_interval"`
+	// MaxBackoff is the longest delay between two polls when the API throttles requests
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+func (c Config) GetSmartlockInterval() time.Duration {
+	return orDefault(c.SmartlockInterval, DefaultSmartlockInterval)
+}
+
+// Scheduler gives the delay before the next poll of the logs API
+type Scheduler struct {
+	config             Config
+	interval           time.Duration
+	reservationsReader nukiapi.ReservationsReader
+	reservations       []model.NukiReservationResponse
+	refreshedAt        time.Time
+	backoff            time.Duration
+}
+
+// NewScheduler creates a scheduler, interval being used while a reservation is in progress or when disabled
+func NewScheduler(config Config, interval time.Duration, reservationsReader nukiapi.ReservationsReader) *Scheduler {
+	return &Scheduler{
+		config:             config.withDefaults(),
+		interval:           interval,
+		reservationsReader: reservationsReader,
+	}
+}
+
+// SetConfig changes the scheduler's configuration, the current backoff being kept
+func (s *Scheduler) SetConfig(config Config) {
+	s.config = config.withDefaults()
+}
+
+func (c Config) withDefaults() Config {
+	c.FastInterval = orDefault(c.FastInterval, DefaultFastInterval)
+	c.SlowInterval = orDefault(c.SlowInterval, DefaultSlowInterval)
+	c.WindowBefore = orDefault(c.WindowBefore, DefaultWindowBefore)
+	c.WindowAfter = orDefault(c.WindowAfter, DefaultWindowAfter)
+	c.ReservationsRefresh = orDefault(c.ReservationsRefresh, DefaultReservationsRefresh)
+	c.MaxBackoff = orDefault(c.MaxBackoff, DefaultMaxBackoff)
+	return c
+}
+
+// Report takes the result of the last poll into account, backing off when the API throttles requests
+func (s *Scheduler) Report(err error) {
+	var throttled nukiapi.ThrottledError
+	if !errors.As(err, &throttled) {
+		if err == nil && s.backoff > 0 {
+			log.Info().Msg("Nuki API not throttling anymore, polling normally")
+			s.backoff = 0
+		}
+		return
+	}
+
+	if s.backoff == 0 {
+		s.backoff = s.interval
+	}
+	s.backoff = min(max(s.backoff*2, throttled.RetryAfter), s.config.MaxBackoff)
+	log.Warn().
+		Dur("retry_after", throttled.RetryAfter).
+		Dur("backoff", s.backoff).
+		Msg("Nuki API is throttling requests, backing off")
+}
+
+// Next returns the delay before the next poll
+func (s *Scheduler) Next(now time.Time) time.Duration {
+	if s.backoff > 0 {
+		return s.backoff
+	}
+	if !s.config.Enabled {
+		return s.interval
+	}
+
+	if now.Sub(s.refreshedAt) > s.config.ReservationsRefresh {
+		resas, err := s.reservationsReader.Execute()
+		if err != nil {
+			// Keeping the reservations we already know, trying again at next poll
+			log.Error().Err(err).Msg("Unable to get reservations to schedule polling")
+		} else {
+			s.reservations = resas
+			s.refreshedAt = now
+		}
+	}
+	return s.intervalAt(now)
+}
+
+// intervalAt returns the fast interval around check-ins and check-outs, the normal one during stays
+// and the slow one when the property is vacant
+func (s *Scheduler) intervalAt(now time.Time) time.Duration {
+	occupied := false
+	var nextWindow time.Time
+	for _, r := range s.reservations {
+		for _, t := range []time.Time{r.StartDate, r.EndDate} {
+			start := t.Add(-s.config.WindowBefore)
+			if now.After(start) && now.Before(t.Add(s.config.WindowAfter)) {
+				return s.config.FastInterval
+			}
+			if start.After(now) && (nextWindow.IsZero() || start.Before(nextWindow)) {
+				nextWindow = start
+			}
+		}
+		if now.After(r.StartDate) && now.Before(r.EndDate) {
+			occupied = true
+		}
+	}
+
+	interval := s.config.SlowInterval
+	if occupied {
+		interval = s.interval
+	}
+	// Not missing the beginning of the next window
+	if !nextWindow.IsZero() {
+		interval = min(interval, max(nextWindow.Sub(now), s.config.FastInterval))
+	}
+	return interval
+}
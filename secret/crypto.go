@@ -0,0 +1,98 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// EnvKey holds the base64 encoded key used to decrypt encrypted files
+	EnvKey = "NUKI_LOGGER_SECRET_KEY"
+	// EnvKeyFile holds the path of a file containing the base64 encoded key, used when EnvKey is not set
+	EnvKeyFile = "NUKI_LOGGER_SECRET_KEY_FILE"
+	// KeySize is the size of the AES-256 key
+	KeySize = 32
+)
+
+var ErrNoKey = fmt.Errorf("no secret key, please set %s or %s", EnvKey, EnvKeyFile)
+
+// GenerateKey returns a new random key, base64 encoded
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 encoded key
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid secret key: expected %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// LoadKey reads the key from the environment
+func LoadKey() ([]byte, error) {
+	if s, ok := os.LookupEnv(EnvKey); ok {
+		return ParseKey(s)
+	}
+	if path, ok := os.LookupEnv(EnvKeyFile); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseKey(string(data))
+	}
+	return nil, ErrNoKey
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM, returning the nonce and the ciphertext base64 encoded
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return []byte(base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Decrypt decrypts data produced by Encrypt
+func Decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,72 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// PrefixEnv reads a secret from an environment variable, e.g. env:NUKI_TOKEN
+	PrefixEnv = "env:"
+	// PrefixFile reads a secret from a file, e.g. file:/run/secrets/nuki
+	PrefixFile = "file:"
+	// PrefixEncryptedFile reads a secret from a file encrypted with the secret key, e.g. enc:/etc/nuki-logger/nuki.enc
+	PrefixEncryptedFile = "enc:"
+
+	// Redacted replaces secrets when displayed
+	Redacted = "***"
+)
+
+// IsReference returns true if value refers to a secret instead of being the secret itself
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, PrefixEnv) ||
+		strings.HasPrefix(value, PrefixFile) ||
+		strings.HasPrefix(value, PrefixEncryptedFile)
+}
+
+// Resolve returns the secret value refers to, value being returned as is when it is not a reference
+func Resolve(value string) (string, error) {
+	if name, ok := strings.CutPrefix(value, PrefixEnv); ok {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	}
+
+	if path, ok := strings.CutPrefix(value, PrefixFile); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		// Files usually end with a new line which is not part of the secret
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if path, ok := strings.CutPrefix(value, PrefixEncryptedFile); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		key, err := LoadKey()
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := Decrypt(key, data)
+		if err != nil {
+			return "", fmt.Errorf("unable to decrypt %s: %w", path, err)
+		}
+		return string(plaintext), nil
+	}
+
+	return value, nil
+}
+
+// Redact hides a secret, keeping empty values empty so that missing secrets can still be spotted
+func Redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return Redacted
+}
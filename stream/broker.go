@@ -0,0 +1,178 @@
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/messaging"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	MessageTypeLog          = "log"
+	MessageTypeBattery      = "battery"
+	MessageTypeModification = "modification"
+	MessageTypeDrift        = "drift"
+	MessageTypeDevice       = "device"
+	// subscriberBufferSize is the number of messages a slow client can lag behind before being disconnected
+	subscriberBufferSize = 64
+)
+
+// Message is an event published on the stream
+type Message struct {
+	// ID is <epoch>-<seq>, the epoch identifying the broker so that ids given after a restart are detected
+	ID              string                                `json:"id"`
+	Type            string                                `json:"type"`
+	Date            time.Time                             `json:"date"`
+	Log             *model.NukiSmartlockLogResponse       `json:"log,omitempty"`
+	ReservationName string                                `json:"reservationName,omitempty"`
+	Smartlock       *model.SmartlockResponse              `json:"smartlock,omitempty"`
+	Modification    *model.ReservationPendingModification `json:"modification,omitempty"`
+	Drift           *model.SmartlockDrift                 `json:"drift,omitempty"`
+	Device          *model.DeviceEvent                    `json:"device,omitempty"`
+	seq             uint64
+}
+
+// Broker keeps the last messages in a ring buffer and fans them out to subscribers
+type Broker struct {
+	mutex       sync.Mutex
+	size        int
+	buffer      []Message
+	epoch       string
+	nextSeq     uint64
+	subscribers map[chan Message]struct{}
+	closed      bool
+}
+
+func NewBroker(size int) *Broker {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Broker{
+		size:        size,
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 10),
+		nextSeq:     1,
+		subscribers: make(map[chan Message]struct{}),
+	}
+}
+
+// Publish converts events to messages and sends them to all subscribers
+func (b *Broker) Publish(events []*messaging.Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return
+	}
+
+	for _, e := range events {
+		m := newMessage(e)
+		if m.Type == "" {
+			continue
+		}
+		m.seq = b.nextSeq
+		m.ID = fmt.Sprintf("%s-%d", b.epoch, m.seq)
+		b.nextSeq++
+
+		b.buffer = append(b.buffer, m)
+		if len(b.buffer) > b.size {
+			b.buffer = b.buffer[1:]
+		}
+		for ch := range b.subscribers {
+			select {
+			case ch <- m:
+			default:
+				// Client is too slow, it has to reconnect and resume from its last event id
+				delete(b.subscribers, ch)
+				close(ch)
+			}
+		}
+	}
+}
+
+func newMessage(e *messaging.Event) Message {
+	switch {
+	case e.IsLogEvent():
+		l := e.Log
+		return Message{Type: MessageTypeLog, Date: l.Date, Log: &l, ReservationName: e.ReservationName}
+	case e.IsSmartlockEvent():
+		s := e.Smartlock
+		return Message{Type: MessageTypeBattery, Date: time.Now(), Smartlock: &s}
+	case e.IsModificationEvent():
+		rpm := *e.Modification
+		return Message{Type: MessageTypeModification, Date: time.Now(), Modification: &rpm}
+	case e.IsDriftEvent():
+		d := *e.Drift
+		return Message{Type: MessageTypeDrift, Date: time.Now(), Drift: &d}
+	case e.IsDeviceEvent():
+		d := *e.Device
+		return Message{Type: MessageTypeDevice, Date: d.Date, Device: &d}
+	default:
+		log.Debug().Str("prefix", e.Prefix).Msg("Event not streamed")
+		return Message{}
+	}
+}
+
+// Subscribe returns the buffered messages published after lastID and a channel receiving the next ones.
+// gap is true when messages after lastID are not buffered anymore or when lastID is not one of this broker's.
+// The channel is closed when the client is too slow or the broker is closed, cancel must be called when done.
+func (b *Broker) Subscribe(lastID string) (backlog []Message, gap bool, ch <-chan Message, cancel func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if lastID != "" {
+		seq, ok := b.parseID(lastID)
+		if !ok {
+			// The id comes from before a restart, sending everything we have
+			seq = 0
+		}
+		for _, m := range b.buffer {
+			if m.seq > seq {
+				backlog = append(backlog, m)
+			}
+		}
+		gap = !ok || (len(b.buffer) > 0 && b.buffer[0].seq > seq+1)
+	}
+
+	c := make(chan Message, subscriberBufferSize)
+	if b.closed {
+		close(c)
+		return backlog, gap, c, func() {}
+	}
+	b.subscribers[c] = struct{}{}
+	return backlog, gap, c, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		if _, ok := b.subscribers[c]; ok {
+			delete(b.subscribers, c)
+			close(c)
+		}
+	}
+}
+
+// parseID returns the sequence of an id published by this broker
+func (b *Broker) parseID(id string) (uint64, bool) {
+	epoch, s, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || seq >= b.nextSeq {
+		return 0, false
+	}
+	return seq, true
+}
+
+// Close disconnects all subscribers so that the http server can shut down
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
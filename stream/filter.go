@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Filter selects the messages sent to a client, from comma separated query parameters:
+//   - type: log, battery, modification, drift or device
+//   - action, trigger, state, source: names (e.g. unlock, keypad) or numbers, only log messages match them
+//   - ref: reservation reference of keypad logs and modifications
+type Filter struct {
+	Types    []string
+	Actions  []string
+	Triggers []string
+	States   []string
+	Sources  []string
+	Refs     []string
+}
+
+func ParseFilter(q url.Values) Filter {
+	return Filter{
+		Types:    splitValues(q["type"]),
+		Actions:  splitValues(q["action"]),
+		Triggers: splitValues(q["trigger"]),
+		States:   splitValues(q["state"]),
+		Sources:  splitValues(q["source"]),
+		Refs:     splitValues(q["ref"]),
+	}
+}
+
+func splitValues(values []string) []string {
+	var res []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+func (f Filter) Match(m Message) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, m.Type) {
+		return false
+	}
+
+	if len(f.Actions) > 0 || len(f.Triggers) > 0 || len(f.States) > 0 || len(f.Sources) > 0 {
+		if m.Log == nil {
+			return false
+		}
+		l := m.Log
+		if !matchEnum(f.Actions, int32(l.Action), l.Action.String()) ||
+			!matchEnum(f.Triggers, int32(l.Trigger), l.Trigger.String()) ||
+			!matchEnum(f.States, int32(l.State), l.State.String()) ||
+			!matchEnum(f.Sources, int32(l.Source), l.Source.String()) {
+			return false
+		}
+	}
+
+	if len(f.Refs) > 0 {
+		var ref string
+		switch {
+		case m.Log != nil:
+			ref = m.Log.Name
+		case m.Modification != nil:
+			ref = m.Modification.ReservationRef
+		}
+		if !slices.Contains(f.Refs, strings.ToLower(ref)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchEnum returns true when no value is given or when one of them is the enum's number or name
+func matchEnum(values []string, number int32, name string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	return slices.Contains(values, strconv.Itoa(int(number))) || slices.Contains(values, strings.ToLower(name))
+}
@@ -0,0 +1,144 @@
+package stream
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultPath       = "/events"
+	DefaultBufferSize = 500
+	// keepAliveInterval keeps proxies from closing idle connections
+	keepAliveInterval = time.Second * 30
+	// retryDelay is the reconnection delay advertised to clients, in milliseconds
+	retryDelay = 5000
+)
+
+// Config configures the Server-Sent Events stream served by the http server
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path of the stream on the http server
+	Path string `mapstructure:"path"`
+	// Token must be given as a token query parameter or as a bearer token to subscribe
+	Token string `mapstructure:"token"`
+	// BufferSize is the number of events kept for clients resuming from a last event id
+	BufferSize int `mapstructure:"buffer_size"`
+}
+
+func (c Config) GetPath() string {
+	if c.Path == "" {
+		return DefaultPath
+	}
+	return c.Path
+}
+
+// Stream pushes events published on its broker to http clients as Server-Sent Events
+type Stream struct {
+	*Broker
+	config Config
+}
+
+func New(config Config) (*Stream, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("stream token is mandatory")
+	}
+	return &Stream{
+		Broker: NewBroker(config.BufferSize),
+		config: config,
+	}, nil
+}
+
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.isAuthorized(r) {
+		log.Warn().
+			Str("remote_addr", r.RemoteAddr).
+			Msg("Unauthorized stream request")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	filter := ParseFilter(r.URL.Query())
+
+	backlog, gap, messages, cancel := s.Subscribe(lastID)
+	defer cancel()
+
+	log.Debug().
+		Str("remote_addr", r.RemoteAddr).
+		Str("last_event_id", lastID).
+		Msg("Stream client connected")
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryDelay)
+	if gap {
+		fmt.Fprint(w, ": some events since the last event id are not available anymore\n\n")
+	}
+	for _, m := range backlog {
+		if err := writeMessage(w, filter, m); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case m, ok := <-messages:
+			if !ok {
+				return
+			}
+			if err := writeMessage(w, filter, m); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			log.Debug().
+				Str("remote_addr", r.RemoteAddr).
+				Msg("Stream client disconnected")
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeMessage(w http.ResponseWriter, filter Filter, m Message) error {
+	if !filter.Match(m) {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		log.Error().Err(err).Str("id", m.ID).Msg("Unable to marshal stream message")
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", m.ID, m.Type, data)
+	return err
+}
+
+func (s *Stream) isAuthorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) == 1
+}
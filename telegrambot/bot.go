@@ -1,14 +1,23 @@
 package telegrambot
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/enescakir/emoji"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/booking"
 	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/eventbus"
+	"github.com/nmaupu/nuki-logger/hygiene"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/lifecycle"
 	"github.com/nmaupu/nuki-logger/messaging"
 	"github.com/nmaupu/nuki-logger/model"
 	"github.com/nmaupu/nuki-logger/nukiapi"
@@ -19,11 +28,35 @@ import (
 
 type NukiBot interface {
 	Start() error
+	Stop() error
 	AddFilter(FilterFunc)
+	SetRoles(Roles)
+	UseWebhook(WebhookConfig, *http.ServeMux)
+	SetGuestsConfig(GuestsConfig)
+	SetBookingsConfig(booking.Config)
+	SetLifecycleTracker(*lifecycle.Tracker)
+	SetHygieneConfig(hygiene.Config)
+	Reload(Settings)
+	Notify(key string, args ...any)
+	IsGuestAllowed(telego.Update) bool
+	GetAllPendingModifications() []model.ReservationPendingModification
+	PendingModificationRoutine() tgbroutine.ReservationPendingModificationRoutine
 }
 
+// Settings are the bot's settings which can be changed while running
+type Settings struct {
+	DefaultCheckIn  time.Time
+	DefaultCheckOut time.Time
+	Roles           Roles
+	Guests          GuestsConfig
+}
+
+// CallbackHandler handles callbacks not bound to a chat session
+type CallbackHandler func(update telego.Update, data string) (*telego.SendMessageParams, error)
+
 type nukiBot struct {
 	bot                                   *telego.Bot
+	bus                                   *eventbus.Bus
 	Sender                                *messaging.TelegramSender
 	LogsReader                            nukiapi.LogsReader
 	SmartlockReader                       nukiapi.SmartlockReader
@@ -31,8 +64,20 @@ type nukiBot struct {
 	SmartlockAuthReader                   nukiapi.SmartlockAuthReader
 	filters                               []FilterFunc
 	reservationPendingModificationRoutine tgbroutine.ReservationPendingModificationRoutine
-	DefaultCheckIn                        time.Time
-	DefaultCheckOut                       time.Time
+	mutexSettings                         sync.RWMutex
+	settings                              Settings
+	commands                              Commands
+	sessions                              *SessionManager
+	webhook                               *WebhookConfig
+	webhookMux                            *http.ServeMux
+	guests                                *guestStore
+	languages                             *languageStore
+	bookingsConfig                        booking.Config
+	bookingImportRoutine                  tgbroutine.BookingImportRoutine
+	callbackHandlers                      map[string]CallbackHandler
+	lifecycleTracker                      *lifecycle.Tracker
+	hygieneConfig                         hygiene.Config
+	authHygieneRoutine                    tgbroutine.AuthHygieneRoutine
 }
 
 func NewNukiBot(sender *messaging.TelegramSender,
@@ -43,19 +88,23 @@ func NewNukiBot(sender *messaging.TelegramSender,
 	defaultCheckIn time.Time,
 	defaultCheckOut time.Time,
 	cache cache.Cache,
+	bus *eventbus.Bus,
+	sessions *SessionManager,
 	filters ...FilterFunc) (NukiBot, error) {
 
-	bot, err := telego.NewBot(sender.Token)
+	// Sharing the sender's client so that rate limits apply to every message sent
+	bot, err := sender.Bot()
 	if err != nil {
 		return nil, err
 	}
 	resaTimeModifier := nukiapi.ReservationTimeModifier{
-		APICaller: nukiapi.APICaller{Token: reservationsReader.Token},
+		APICaller: reservationsReader.APICaller,
 		AddressID: reservationsReader.AddressID,
 	}
-	resaPendingModifRoutine := tgbroutine.NewReservationPendingModificationRoutine(reservationsReader, resaTimeModifier, cache)
+	resaPendingModifRoutine := tgbroutine.NewReservationPendingModificationRoutine(reservationsReader, resaTimeModifier, cache, bus)
 	return &nukiBot{
 		bot:                                   bot,
+		bus:                                   bus,
 		Sender:                                sender,
 		LogsReader:                            logsReader,
 		SmartlockReader:                       smartlockReader,
@@ -63,8 +112,12 @@ func NewNukiBot(sender *messaging.TelegramSender,
 		SmartlockAuthReader:                   smartlockAuthReader,
 		filters:                               filters,
 		reservationPendingModificationRoutine: resaPendingModifRoutine,
-		DefaultCheckIn:                        defaultCheckIn,
-		DefaultCheckOut:                       defaultCheckOut,
+		settings:                              Settings{DefaultCheckIn: defaultCheckIn, DefaultCheckOut: defaultCheckOut},
+		sessions:                              sessions,
+		guests:                                newGuestStore(false, cache),
+		languages:                             newLanguageStore(cache),
+		callbackHandlers:                      make(map[string]CallbackHandler),
+		authHygieneRoutine:                    tgbroutine.NewAuthHygieneRoutine(smartlockAuthReader, reservationsReader, cache),
 	}, nil
 }
 
@@ -72,17 +125,141 @@ func (b *nukiBot) AddFilter(f FilterFunc) {
 	b.filters = append(b.filters, f)
 }
 
-func (b *nukiBot) Start() error {
-	b.reservationPendingModificationRoutine.AddOnErrorListener(func(rpm *model.ReservationPendingModification, e error) {
-		log.Error().Err(e).Msg("An error occurred processing pending modifications")
-		if rpm != nil {
-			_, _ = b.bot.SendMessage(tu.Message(tu.ID(rpm.FromChatID), fmt.Sprintf("An error occurred processing pending modification, err=%v", e)))
+// SetBookingsConfig configures the import of bookings from OTAs' iCal feeds
+func (b *nukiBot) SetBookingsConfig(c booking.Config) {
+	b.bookingsConfig = c
+}
+
+// SetLifecycleTracker gives the device history displayed by /device
+func (b *nukiBot) SetLifecycleTracker(t *lifecycle.Tracker) {
+	b.lifecycleTracker = t
+}
+
+// GetAllPendingModifications returns all reservations' access times modifications, applied or not
+func (b *nukiBot) GetAllPendingModifications() []model.ReservationPendingModification {
+	return b.reservationPendingModificationRoutine.GetAllPendingModifications()
+}
+
+// PendingModificationRoutine returns the routine applying pending modifications
+func (b *nukiBot) PendingModificationRoutine() tgbroutine.ReservationPendingModificationRoutine {
+	return b.reservationPendingModificationRoutine
+}
+
+// SetRoles sets the roles used to authorize commands
+func (b *nukiBot) SetRoles(roles Roles) {
+	b.mutexSettings.Lock()
+	defer b.mutexSettings.Unlock()
+	b.settings.Roles = roles
+}
+
+// SetGuestsConfig configures the guest self-service mode
+func (b *nukiBot) SetGuestsConfig(c GuestsConfig) {
+	b.mutexSettings.Lock()
+	defer b.mutexSettings.Unlock()
+	b.settings.Guests = c
+	b.guests.enabled = c.Enabled
+}
+
+// getSettings returns a copy of the current settings
+func (b *nukiBot) getSettings() Settings {
+	b.mutexSettings.RLock()
+	defer b.mutexSettings.RUnlock()
+	return b.settings
+}
+
+// Reload applies new settings to the running bot.
+// Enabling or disabling guests changes the bot's commands and is only taken into account on restart.
+func (b *nukiBot) Reload(s Settings) {
+	b.mutexSettings.Lock()
+	s.Guests.Enabled = b.settings.Guests.Enabled
+	b.settings = s
+	b.mutexSettings.Unlock()
+
+	if b.bookingImportRoutine != nil {
+		b.bookingImportRoutine.SetDefaultCheckTimes(s.DefaultCheckIn, s.DefaultCheckOut)
+	}
+}
+
+// Notify sends a translated message to the bot's chat
+func (b *nukiBot) Notify(key string, args ...any) {
+	_, err := b.Sender.SendMessage(tu.Message(tu.ID(b.Sender.ChatID),
+		i18n.T(b.langForChat(b.Sender.ChatID), key, args...)))
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Unable to send notification to the bot's chat")
+	}
+}
+
+// isAllowed returns true if userID can run cmd, taking linked guests into account
+func (b *nukiBot) isAllowed(cmd Command, userID int64) bool {
+	if cmd.Public {
+		return true
+	}
+	if b.guests.IsLinked(userID) {
+		if len(cmd.Roles) == 0 || slices.Contains(cmd.Roles, RoleGuest) {
+			return true
 		}
-	})
+		if b.getSettings().Roles.IsEmpty() { // guests are never considered as admins
+			return false
+		}
+	}
+	return cmd.IsAllowed(b.getSettings().Roles, userID)
+}
+
+// accessDenied logs a denied attempt and reports it to all admins
+func (b *nukiBot) accessDenied(update telego.Update) {
+	from := update.Message.From
+	log.Warn().
+		Int64("from_id", from.ID).
+		Str("from_username", from.Username).
+		Str("from_firstname", from.FirstName).
+		Str("from_lastname", from.LastName).
+		Str("message", update.Message.Text).
+		Msg("Access denied.")
+
+	for _, adminID := range b.getSettings().Roles.Admins() {
+		_, err := b.Sender.SendMessage(tu.Message(
+			tu.ID(adminID),
+			i18n.T(b.langForChat(adminID), "bot.access_denied_admin",
+				emoji.NoEntry.String(), from.FirstName, from.LastName, from.Username, from.ID, update.Message.Text),
+		))
+		if err != nil {
+			log.Error().Err(err).Int64("admin_id", adminID).Msg("Unable to report denied access to admin")
+		}
+	}
+}
+
+// modificationChatID returns the chat to notify about a modification, the bot's chat when not created from Telegram
+func (b *nukiBot) modificationChatID(rpm *model.ReservationPendingModification) int64 {
+	if rpm.FromChatID == 0 {
+		return b.Sender.ChatID
+	}
+	return rpm.FromChatID
+}
+
+// Stop stops receiving updates from Telegram
+func (b *nukiBot) Stop() error {
+	if b.webhook != nil {
+		return b.stopWebhook()
+	}
+	b.bot.StopLongPolling()
+	return nil
+}
 
-	b.reservationPendingModificationRoutine.AddOnModificationDoneListener(func(rpm *model.ReservationPendingModification) {
+// onEvent handles the events the bot is subscribed to on the bus
+func (b *nukiBot) onEvent(e eventbus.Event) {
+	rpm := e.Modification
+	switch e.Kind {
+	case eventbus.KindLog:
+		b.OnNewLogs(e.Logs())
+	case eventbus.KindModificationFailed:
+		log.Error().Err(e.Err).Msg("An error occurred processing pending modifications")
+		if rpm != nil {
+			chatID := b.modificationChatID(rpm)
+			_, _ = b.Sender.SendMessage(tu.Message(tu.ID(chatID), i18n.T(b.langForChat(chatID), "bot.modif_error", e.Err)))
+		}
+	case eventbus.KindModificationDone:
 		if rpm == nil {
-			log.Warn().Msgf("onModificationListener callback called with a nil modification")
+			log.Warn().Msgf("Modification done event received without a modification")
 			return
 		}
 
@@ -91,72 +268,141 @@ func (b *nukiBot) Start() error {
 			Str("check_in", rpm.FormatCheckIn()).
 			Str("check_out", rpm.FormatCheckOut()).
 			Msg("Pending modification done")
-		_, _ = b.bot.SendMessage(tu.Message(
-			tu.ID(rpm.FromChatID),
-			fmt.Sprintf("Pending modification done for %s (%s -> %s)", rpm.ReservationRef, rpm.FormatCheckIn(), rpm.FormatCheckOut())),
+		chatID := b.modificationChatID(rpm)
+		_, _ = b.Sender.SendMessage(tu.Message(
+			tu.ID(chatID),
+			i18n.T(b.langForChat(chatID), "bot.modif_done", rpm.ReservationRef, rpm.FormatCheckIn(), rpm.FormatCheckOut())),
 		)
-	})
+	}
+}
+
+func (b *nukiBot) Start() error {
+	b.bus.Subscribe("telegram-bot", eventbus.DefaultQueueSize, b.onEvent,
+		eventbus.KindLog, eventbus.KindModificationDone, eventbus.KindModificationFailed)
 
 	commands := Commands{}
+	b.commands = commands
 	handlerHelp := func(update telego.Update, msg *telego.SendMessageParams) {
+		lang := b.lang(update)
 		keys := maps.Keys(commands)
 		helpItems := slices.DeleteFunc(keys, func(s string) bool { return !strings.HasPrefix(s, "/") })
+		slices.Sort(helpItems)
 		elts := []string{}
 		for _, v := range helpItems {
-			if v == "/test" {
+			if v == "/test" || !b.isAllowed(commands[v], update.Message.From.ID) {
 				continue
 			}
-			elts = append(elts, fmt.Sprintf("  - %s\t%s", v, commands[v].Description))
+			elts = append(elts, fmt.Sprintf("  - %s\t%s", v, i18n.T(lang, commands[v].Description)))
 		}
-		msg.Text = fmt.Sprintf("The following commands are available: \n%s",
-			strings.Join(elts, "\n"))
+		msg.Text = i18n.T(lang, "bot.help", strings.Join(elts, "\n"))
 	}
 
-	cmdHelp := Command{Handler: handlerHelp, Description: "Display help"}
+	cmdHelp := Command{Handler: handlerHelp, Description: "cmd.help"}
 	commands["/start"] = cmdHelp
 	commands["/help"] = cmdHelp
-	commands[menuHelp] = cmdHelp
+	commands.addMenuCommand(menuHelp, cmdHelp)
 
-	commands["/menu"] = Command{Handler: b.handlerMenu, Description: "Show the main menu"}
+	commands["/menu"] = Command{Handler: b.handlerMenu, Description: "cmd.menu"}
 
-	cmdBat := Command{Handler: b.handlerBattery, Description: "Display battery details"}
+	cmdBat := Command{Handler: b.handlerBattery, Description: "cmd.battery", Roles: rolesAll}
 	commands["/battery"] = cmdBat
 	commands["/bat"] = cmdBat
-	commands[menuBattery] = cmdBat
+	commands.addMenuCommand(menuBattery, cmdBat)
+
+	commands["/status"] = Command{Handler: b.handlerStatus, Description: "cmd.status", Roles: rolesAll}
+	commands["/device"] = Command{Handler: b.handlerDevice, Description: "cmd.device", Roles: []Role{RoleHost, RoleViewer}}
 
-	cmdResa := Command{Handler: b.handlerResa, Description: "List all reservations"}
+	cmdResa := Command{Handler: b.handlerResa, Description: "cmd.resa", Roles: rolesAll}
 	commands["/resa"] = cmdResa
-	commands[menuResas] = cmdResa
+	commands.addMenuCommand(menuResas, cmdResa)
 
-	logsFSM := b.fsmLogsCommand()
-	cmdLogs := Command{StateMachine: logsFSM, Description: "Display Nuki lock logs"}
+	cmdLogs := Command{
+		NewStateMachine: b.fsmLogsCommand,
+		Description:     "cmd.logs",
+		Roles:           []Role{RoleHost, RoleViewer},
+		PersistedMetadata: map[string]func() any{
+			fsmMetadataLogsBrowser: func() any { return &logsBrowser{} },
+		},
+	}
 	commands["/logs"] = cmdLogs
-	commands[menuLogs] = cmdLogs
+	commands.addMenuCommand(menuLogs, cmdLogs)
 
-	codeFSM := b.fsmCodeCommand()
-	cmdCode := Command{StateMachine: codeFSM, Description: "Display a reservation door code"}
+	cmdCode := Command{NewStateMachine: b.fsmCodeCommand, Description: "cmd.code", Roles: []Role{RoleHost}}
 	commands["/code"] = cmdCode
-	commands[menuCode] = cmdCode
+	commands.addMenuCommand(menuCode, cmdCode)
+
+	commands["/version"] = Command{Handler: b.handlerVersion, Description: "cmd.version"}
 
-	commands["/version"] = Command{Handler: b.handlerVersion, Description: "Display bot version"}
+	commands["/lang"] = Command{Handler: b.handlerLang, Description: "cmd.lang"}
+	b.callbackHandlers[callbackLang] = b.callbackLangChosen
+	if err := b.languages.load(); err != nil && !errors.Is(err, cache.ErrCacheNoClient) {
+		log.Error().Err(err).Msg("Unable to load languages from cache")
+	}
 
-	modifyFSM := b.fsmModifyCommand()
-	cmdModify := Command{StateMachine: modifyFSM, Description: "Modify check-in/out of a specific reservation"}
+	cmdModify := Command{
+		NewStateMachine: b.fsmModifyCommand,
+		Description:     "cmd.modify",
+		Roles:           []Role{RoleHost},
+		PersistedMetadata: map[string]func() any{
+			fsmMetadataPendingModif: func() any { return &model.ReservationPendingModification{} },
+		},
+	}
 	commands["/modify"] = cmdModify
-	commands[menuModify] = cmdModify
+	commands.addMenuCommand(menuModify, cmdModify)
 
-	cmdListModify := Command{Handler: b.handlerListModify, Description: "List all pending modifications"}
+	cmdListModify := Command{Handler: b.handlerListModify, Description: "cmd.listmodify", Roles: []Role{RoleHost, RoleViewer}}
 	commands["/listmodify"] = cmdListModify
-	commands[menuListModify] = cmdListModify
+	commands.addMenuCommand(menuListModify, cmdListModify)
 
-	commands["/deletemodify"] = Command{StateMachine: b.fsmDeleteModifyCommand(), Description: "Delete a pending modification"}
+	commands["/deletemodify"] = Command{NewStateMachine: b.fsmDeleteModifyCommand, Description: "cmd.deletemodify", Roles: []Role{RoleHost}}
 
-	commands["/savemodify"] = Command{Handler: b.handlerSavePendingReservationsToCache, Description: "Save all modifications to the cache"}
+	commands["/savemodify"] = Command{Handler: b.handlerSavePendingReservationsToCache, Description: "cmd.savemodify", Roles: []Role{RoleHost}}
 
-	commands["/applymodify"] = Command{Handler: b.handlerApplyModify, Description: "Apply all pending modifications now"}
+	commands["/applymodify"] = Command{Handler: b.handlerApplyModify, Description: "cmd.applymodify", Roles: []Role{RoleAdmin}}
 
-	commands["/test"] = Command{StateMachine: b.fsmTestCommand()}
+	if b.bookingsConfig.IsEnabled() {
+		commands["/importbookings"] = Command{Handler: b.handlerImportBookings, Description: "cmd.importbookings", Roles: []Role{RoleAdmin}}
+	}
+
+	commands["/hygiene"] = Command{Handler: b.handlerHygiene, Description: "cmd.hygiene", Roles: []Role{RoleHost}}
+	b.callbackHandlers[callbackHygiene] = b.callbackHygieneAction
+
+	commands["/test"] = Command{NewStateMachine: b.fsmTestCommand, Roles: []Role{RoleAdmin}}
+
+	if b.getSettings().Guests.Enabled {
+		commands["/link"] = Command{NewStateMachine: b.fsmLinkCommand, Description: "cmd.link", Public: true}
+		cmdMyCode := Command{Handler: b.handlerMyCode, Description: "cmd.mycode", Roles: []Role{RoleGuest}}
+		commands["/mycode"] = cmdMyCode
+		commands.addMenuCommand(menuMyCode, cmdMyCode)
+		cmdLateCheckout := Command{NewStateMachine: b.fsmLateCheckoutCommand, Description: "cmd.latecheckout", Roles: []Role{RoleGuest}}
+		commands["/latecheckout"] = cmdLateCheckout
+		commands.addMenuCommand(menuLateCheckout, cmdLateCheckout)
+		commands["/guestlink"] = Command{NewStateMachine: b.fsmGuestLinkCommand, Description: "cmd.guestlink", Roles: []Role{RoleHost}}
+		b.callbackHandlers[callbackLateCheckout] = b.callbackLateCheckoutAnswer
+
+		if err := b.guests.load(); err != nil && !errors.Is(err, cache.ErrCacheNoClient) {
+			log.Error().Err(err).Msg("Unable to load guests from cache")
+		}
+	}
+
+	b.sessions.OnExpire(func(chatID int64) {
+		_, err := b.Sender.SendMessage(tu.Message(tu.ID(chatID),
+			i18n.T(b.langForChat(chatID), "bot.session_expired", emoji.HourglassDone.String())))
+		if err != nil {
+			log.Error().Err(err).Int64("chat_id", chatID).Msg("Unable to send session expired message")
+		}
+	})
+	if err := b.sessions.Load(commands); err != nil && !errors.Is(err, cache.ErrCacheNoClient) {
+		log.Error().Err(err).Msg("Unable to load bot sessions from cache")
+	}
+	b.sessions.StartJanitor()
 
 	b.reservationPendingModificationRoutine.Start(time.Minute * 10)
+	if b.bookingsConfig.IsEnabled() {
+		b.startBookingImport()
+	}
+	if b.hygieneConfig.Enabled {
+		b.startAuthHygiene()
+	}
 	return commands.start(b)
 }
@@ -2,56 +2,59 @@ package telegrambot
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/enescakir/emoji"
 	"github.com/looplab/fsm"
 	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/i18n"
 
 	"github.com/rs/zerolog/log"
 )
 
-var chatSessions = map[int64]Command{}
-
 type CommandHandler func(update telego.Update, msgResponse *telego.SendMessageParams)
 
 type Command struct {
-	StateMachine *fsm.FSM
-	NextFSMEvent string
-	Handler      CommandHandler
-	Description  string
-}
-
-func resetChatSession(chatID int64, cmd *Command) {
-	log.Trace().
-		Int64("chatID", chatID).
-		Msg("Resetting chat session")
-	if cmd != nil && cmd.StateMachine != nil {
-		if err := cmd.StateMachine.Event(context.Background(), FSMEventReset); err != nil {
-			log.Error().Err(err).Msg("An error occurred resetting chat session")
-		}
-	}
-	delete(chatSessions, chatID)
+	// NewStateMachine creates a new FSM for each chat session running this command
+	NewStateMachine func() *fsm.FSM
+	Handler         CommandHandler
+	Description     string
+	// Roles needed to run this command, any known user can run it if empty
+	Roles []Role
+	// PersistedMetadata lists FSM metadata to save along with the session, associated with a func creating an empty value to unmarshal to
+	PersistedMetadata map[string]func() any
+	// Public commands can be run by anyone, including unknown users
+	Public bool
 }
 
 type Commands map[string]Command
 
-func (c Command) GetNextFSMEvent() string {
-	if c.NextFSMEvent == "" {
-		return FSMEventDefault
-	}
-	return c.NextFSMEvent
+// IsAllowed returns true if userID is allowed to run this command
+func (c Command) IsAllowed(roles Roles, userID int64) bool {
+	return roles.IsAllowed(userID, c.Roles)
 }
 
 func (c Commands) start(b *nukiBot) error {
-	updates, err := b.bot.UpdatesViaLongPolling(nil)
+	var updates <-chan telego.Update
+	var err error
+	if b.webhook != nil && b.webhook.Enabled {
+		updates, err = b.updatesViaWebhook()
+	} else {
+		b.webhook = nil
+		// A previously registered webhook prevents long polling from working
+		if err := b.bot.DeleteWebhook(&telego.DeleteWebhookParams{}); err != nil {
+			log.Error().Err(err).Msg("Unable to delete telegram webhook")
+		}
+		updates, err = b.bot.UpdatesViaLongPolling(nil)
+	}
 	if err != nil {
 		return err
 	}
 
 	go func() {
-		defer b.bot.StopLongPolling()
 	POLL:
 		for update := range updates {
 			// Execute all filters before proceeding
@@ -109,20 +112,24 @@ func (c Commands) start(b *nukiBot) error {
 					log.Error().Err(err).Msg("Unable to answer callback.")
 				}
 
-				msg, err = c.handleCallback(update, destinationChatID)
+				msg, err = c.handleCallback(b, update, destinationChatID)
 				if err != nil {
 					msg = &telego.SendMessageParams{Text: err.Error()}
 				}
 			} else { // Direct message
-				msg, err = c.handleMessage(b.bot, update, destinationChatID)
+				msg, err = c.handleMessage(b, update, destinationChatID)
 				if err != nil {
 					msg = &telego.SendMessageParams{Text: err.Error()}
 				}
 			}
 
+			if msg == nil { // Message already edited in place
+				continue
+			}
+
 			// Sending message to client
 			msg.ChatID = tu.ID(destinationChatID)
-			_, err := b.bot.SendMessage(msg)
+			_, err := b.Sender.SendMessage(msg)
 			if err != nil {
 				log.Error().Err(err).
 					Str("msg", msg.Text).
@@ -142,73 +149,128 @@ func isCallback(update telego.Update) bool {
 	return update.Message == nil && update.CallbackQuery != nil
 }
 
-func (c Commands) handleCallback(update telego.Update, destinationChatID int64) (*telego.SendMessageParams, error) {
-	// Should already have a FSM registered
-	command, ok := chatSessions[destinationChatID]
-	if !ok {
-		return nil, fmt.Errorf("This button is expired, use menu or initiate a new command!")
+func (c Commands) handleCallback(b *nukiBot, update telego.Update, destinationChatID int64) (*telego.SendMessageParams, error) {
+	cmd := GetCommandFromCallbackData(update.CallbackQuery)
+	data := GetDataFromCallbackData(update.CallbackQuery)
+
+	// Callbacks not bound to a session
+	if handler, ok := b.callbackHandlers[cmd]; ok {
+		return handler(update, data)
+	}
+
+	// Should already have a session registered
+	sess := b.sessions.Get(destinationChatID)
+	if sess == nil {
+		return nil, errors.New(i18n.T(b.lang(update), "bot.button_expired"))
 	}
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
 
 	log.Debug().
 		Str("msg", update.CallbackQuery.Data).
-		Str("fsm_state", command.StateMachine.Current()).
+		Str("fsm_state", sess.StateMachine.Current()).
 		Msg("Received callback")
 
-	cmd := GetCommandFromCallbackData(update.CallbackQuery)
-	data := GetDataFromCallbackData(update.CallbackQuery)
-	command.StateMachine.SetMetadata(FSMMetadataTelegoUpdate, &update)
-	err := command.StateMachine.Event(context.Background(), cmd, data)
+	sess.StateMachine.SetMetadata(FSMMetadataTelegoUpdate, &update)
+	err := fsmEventErr(sess.StateMachine.Event(context.Background(), cmd, data))
+	// A callback can wait for the user to type something
+	nextEvent, _ := getMetadataString(FSMMetadataNextEvent, sess.StateMachine)
+	b.sessions.Touch(sess, nextEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := getMetadataSendMessageParams(FSMMetadataMessage, sess.StateMachine)
 	if err != nil {
 		return nil, err
 	}
-	return getMetadataSendMessageParams(FSMMetadataMessage, command.StateMachine)
+	if isEditMessageInPlace(sess.StateMachine) {
+		if err := b.editMessage(update.CallbackQuery.Message, msg); err != nil {
+			log.Error().Err(err).Msg("Unable to edit message, sending a new one")
+			return msg, nil
+		}
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// editMessage replaces the content of message with msg
+func (b *nukiBot) editMessage(message telego.MaybeInaccessibleMessage, msg *telego.SendMessageParams) error {
+	if message == nil || !message.IsAccessible() {
+		return errors.New("message is not accessible anymore")
+	}
+	keyboard, _ := msg.ReplyMarkup.(*telego.InlineKeyboardMarkup)
+	_, err := b.Sender.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:      tu.ID(message.GetChat().ID),
+		MessageID:   message.GetMessageID(),
+		Text:        msg.Text,
+		ParseMode:   msg.ParseMode,
+		ReplyMarkup: keyboard,
+	})
+	var apiErr *ta.Error
+	if errors.As(err, &apiErr) && strings.Contains(apiErr.Description, "message is not modified") {
+		return nil
+	}
+	return err
 }
 
-func (c Commands) handleMessage(bot *telego.Bot, update telego.Update, destinationChatID int64) (*telego.SendMessageParams, error) {
+func (c Commands) handleMessage(b *nukiBot, update telego.Update, destinationChatID int64) (*telego.SendMessageParams, error) {
+	var sess *session
+	if token, ok := strings.CutPrefix(update.Message.Text, "/start "); ok && b.getSettings().Guests.Enabled {
+		b.sessions.Delete(destinationChatID)
+		return b.linkGuestFromToken(update, strings.TrimSpace(token)), nil
+	}
+
 	command, ok := c[update.Message.Text]
+	if ok && !b.isAllowed(command, update.Message.From.ID) {
+		b.accessDenied(update)
+		return tu.Message(tu.ID(destinationChatID), i18n.T(b.lang(update), "bot.not_allowed", emoji.NoEntry.String())), nil
+	}
 	if !ok {
 		// 2 possibilities here:
 		//   - unknown command
 		//   - a response to a previous command as part of a conversation with the bot
-		command, ok = chatSessions[destinationChatID]
-		if !ok || command.NextFSMEvent == "" { // Unknown command
-			return tu.Message(tu.ID(destinationChatID), fmt.Sprintf("I don't understand %s", emoji.ManShrugging.String())), nil
+		sess = b.sessions.Get(destinationChatID)
+		if sess == nil || !sess.WaitsForInput() { // Unknown command
+			return tu.Message(tu.ID(destinationChatID), i18n.T(b.lang(update), "bot.not_understood", emoji.ManShrugging.String())), nil
 		}
 	} else { // reinit for a new command to be processed
-		cmd, ok := chatSessions[destinationChatID]
-		if ok {
-			resetChatSession(destinationChatID, &cmd)
+		b.sessions.Delete(destinationChatID)
+
+		// If we have a basic handler, execute that instead
+		if command.Handler != nil {
+			msg := &telego.SendMessageParams{}
+			command.Handler(update, msg)
+			return msg, nil
 		}
-	}
 
-	// If we have a basic handler, execute that instead
-	if command.Handler != nil {
-		msg := &telego.SendMessageParams{}
-		command.Handler(update, msg)
-		return msg, nil
+		if command.NewStateMachine == nil {
+			return tu.Message(tu.ID(destinationChatID), "internal error, fsm is nil"), nil
+		}
+		sess = b.sessions.Start(destinationChatID, update.Message.Text, command)
 	}
 
-	if command.StateMachine == nil {
-		return tu.Message(tu.ID(destinationChatID), "internal error, fsm is nil"), nil
-	}
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
 
 	log.Debug().
+		Int64("chat_id", destinationChatID).
 		Str("msg", update.Message.Text).
-		Str("fsm_state", command.StateMachine.Current()).
-		Str("next_fsm_event", command.GetNextFSMEvent()).
+		Str("fsm_state", sess.StateMachine.Current()).
+		Str("next_fsm_event", sess.GetNextFSMEvent()).
 		Msgf("Telegram message received.")
 
-	command.StateMachine.SetMetadata(FSMMetadataTelegoUpdate, &update)
-	err := command.StateMachine.Event(context.Background(), command.GetNextFSMEvent(), update.Message.Text)
+	sess.StateMachine.SetMetadata(FSMMetadataTelegoUpdate, &update)
+	err := fsmEventErr(sess.StateMachine.Event(context.Background(), sess.GetNextFSMEvent(), update.Message.Text))
 	if err != nil {
-		if errRecoverEvent, _ := getMetadataString(FSMMetadataErrRecoverEvent, command.StateMachine); errRecoverEvent != "" {
+		if errRecoverEvent, _ := getMetadataString(FSMMetadataErrRecoverEvent, sess.StateMachine); errRecoverEvent != "" {
 			// Send error message to the client
-			if _, err := bot.SendMessage(tu.Message(tu.ID(destinationChatID), err.Error())); err != nil {
+			if _, err := b.Sender.SendMessage(tu.Message(tu.ID(destinationChatID), err.Error())); err != nil {
 				log.Error().Err(err).Send()
 			}
 			// Transition to the recover event
-			command.StateMachine.SetMetadata(FSMMetadataTelegoUpdate, &update)
-			if err := command.StateMachine.Event(context.Background(), errRecoverEvent); err != nil {
+			sess.StateMachine.SetMetadata(FSMMetadataTelegoUpdate, &update)
+			if err := sess.StateMachine.Event(context.Background(), errRecoverEvent); err != nil {
 				log.Error().Err(err).Msg("An error occurred calling error callback")
 			}
 		} else {
@@ -216,11 +278,11 @@ func (c Commands) handleMessage(bot *telego.Bot, update telego.Update, destinati
 		}
 	}
 	// Get next fsm event from metadata if any
-	command.NextFSMEvent, err = getMetadataString(FSMMetadataNextEvent, command.StateMachine)
+	nextEvent, err := getMetadataString(FSMMetadataNextEvent, sess.StateMachine)
 	if err != nil {
-		command.NextFSMEvent = ""
+		nextEvent = ""
 	}
-	chatSessions[destinationChatID] = command
+	b.sessions.Touch(sess, nextEvent)
 
-	return getMetadataSendMessageParams(FSMMetadataMessage, command.StateMachine)
+	return getMetadataSendMessageParams(FSMMetadataMessage, sess.StateMachine)
 }
@@ -18,6 +18,7 @@ const (
 	FSMMetadataMessage         = "msg"
 	FSMMetadataErrRecoverEvent = "err_recover_event"
 	FSMMetadataTelegoUpdate    = "telego_update"
+	FSMMetadataEditMessage     = "edit_message"
 )
 
 var (
@@ -84,9 +85,34 @@ func getMetadataTelegoUpdate(key string, fsm *fsm.FSM) (*telego.Update, error) {
 func reinitMetadataMessage(fsm *fsm.FSM) *telego.SendMessageParams {
 	msg := &telego.SendMessageParams{}
 	fsm.SetMetadata(FSMMetadataMessage, msg)
+	fsm.SetMetadata(FSMMetadataEditMessage, false)
 	return msg
 }
 
+// editMessageInPlace makes the answer to a callback replace the message holding the keyboard
+// instead of sending a new one
+func editMessageInPlace(f *fsm.FSM) {
+	f.SetMetadata(FSMMetadataEditMessage, true)
+}
+
+func isEditMessageInPlace(f *fsm.FSM) bool {
+	res, ok := f.Metadata(FSMMetadataEditMessage)
+	if !ok {
+		return false
+	}
+	edit, _ := res.(bool)
+	return edit
+}
+
+// fsmEventErr ignores errors returned for commands staying in the same state between events
+func fsmEventErr(err error) error {
+	var noTransitionErr fsm.NoTransitionError
+	if errors.As(err, &noTransitionErr) {
+		return noTransitionErr.Err
+	}
+	return err
+}
+
 func checkFSMArg(e *fsm.Event) (string, error) {
 	if len(e.Args) != 1 {
 		return "", errors.New("invalid data")
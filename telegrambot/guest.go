@@ -0,0 +1,282 @@
+package telegrambot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	guestsCacheKey              = "telegram-bot-guests"
+	DefaultGuestLinkTokenExpiry = time.Hour * 72
+	// guestLinkMaxAttempts is the number of wrong booking references a user can send before being locked out
+	guestLinkMaxAttempts = 5
+	guestLinkLockout     = time.Hour
+)
+
+// GuestsConfig configures the guest self-service mode
+type GuestsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// LinkTokenExpiry is the validity of one-time links generated by hosts
+	LinkTokenExpiry time.Duration `mapstructure:"link_token_expiry"`
+	// LateCheckoutTimes are the check-out times a guest can ask for (HH:MM)
+	LateCheckoutTimes []string `mapstructure:"late_checkout_times"`
+}
+
+func (c GuestsConfig) GetLinkTokenExpiry() time.Duration {
+	if c.LinkTokenExpiry <= 0 {
+		return DefaultGuestLinkTokenExpiry
+	}
+	return c.LinkTokenExpiry
+}
+
+func (c GuestsConfig) GetLateCheckoutTimes() []string {
+	if len(c.LateCheckoutTimes) == 0 {
+		return []string{"12:00", "13:00", "14:00"}
+	}
+	return c.LateCheckoutTimes
+}
+
+type guestStoreData struct {
+	Links         map[int64]*model.GuestLink            `json:"links"`
+	Tokens        map[string]*model.GuestLinkToken      `json:"tokens"`
+	LateCheckouts map[string]*model.LateCheckoutRequest `json:"late_checkouts"`
+}
+
+// linkAttempts counts the wrong booking references sent by a user
+type linkAttempts struct {
+	failures    int
+	lockedUntil time.Time
+}
+
+// guestStore keeps track of guests linked to a reservation
+type guestStore struct {
+	mutex    sync.Mutex
+	enabled  bool
+	cache    cache.Cache
+	data     guestStoreData
+	attempts map[int64]*linkAttempts
+}
+
+func newGuestStore(enabled bool, cache cache.Cache) *guestStore {
+	return &guestStore{
+		enabled:  enabled,
+		cache:    cache,
+		attempts: make(map[int64]*linkAttempts),
+		data: guestStoreData{
+			Links:         make(map[int64]*model.GuestLink),
+			Tokens:        make(map[string]*model.GuestLinkToken),
+			LateCheckouts: make(map[string]*model.LateCheckoutRequest),
+		},
+	}
+}
+
+func newRandomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Error().Err(err).Msg("Unable to generate random id")
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsLinked returns true if the user is a guest linked to a reservation
+func (s *guestStore) IsLinked(userID int64) bool {
+	_, ok := s.Get(userID)
+	return ok
+}
+
+func (s *guestStore) Get(userID int64) (model.GuestLink, bool) {
+	if s == nil || !s.enabled {
+		return model.GuestLink{}, false
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, ok := s.data.Links[userID]
+	if !ok {
+		return model.GuestLink{}, false
+	}
+	return *l, true
+}
+
+// GetByReservation returns all guests linked to a reservation
+func (s *guestStore) GetByReservation(ref string) []model.GuestLink {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var res []model.GuestLink
+	for _, l := range s.data.Links {
+		if l.ReservationRef == ref {
+			res = append(res, *l)
+		}
+	}
+	return res
+}
+
+func (s *guestStore) Link(userID, chatID int64, ref string) model.GuestLink {
+	s.mutex.Lock()
+	l := &model.GuestLink{
+		UserID:         userID,
+		ChatID:         chatID,
+		ReservationRef: ref,
+		LinkedAt:       time.Now(),
+	}
+	s.data.Links[userID] = l
+	delete(s.attempts, userID)
+	s.mutex.Unlock()
+
+	log.Info().Object("guest", l).Msg("Guest linked to reservation")
+	s.save()
+	return *l
+}
+
+// LinkLockedUntil returns the end of the lockout of a user who sent too many wrong booking references
+func (s *guestStore) LinkLockedUntil(userID int64) (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	a, ok := s.attempts[userID]
+	if !ok || time.Now().After(a.lockedUntil) {
+		return time.Time{}, false
+	}
+	return a.lockedUntil, true
+}
+
+// LinkFailed counts a wrong booking reference, returns true when the user gets locked out
+func (s *guestStore) LinkFailed(userID int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	a, ok := s.attempts[userID]
+	if !ok {
+		a = &linkAttempts{}
+		s.attempts[userID] = a
+	}
+	a.failures++
+	if a.failures < guestLinkMaxAttempts {
+		return false
+	}
+	a.failures = 0
+	a.lockedUntil = time.Now().Add(guestLinkLockout)
+	return true
+}
+
+func (s *guestStore) Unlink(userID int64) {
+	s.mutex.Lock()
+	delete(s.data.Links, userID)
+	s.mutex.Unlock()
+	s.save()
+}
+
+// SetWelcomed flags a guest as welcomed, returns false if it was already
+func (s *guestStore) SetWelcomed(userID int64) bool {
+	s.mutex.Lock()
+	l, ok := s.data.Links[userID]
+	if !ok || l.Welcomed {
+		s.mutex.Unlock()
+		return false
+	}
+	l.Welcomed = true
+	s.mutex.Unlock()
+	s.save()
+	return true
+}
+
+// NewToken creates a one-time token to link a guest to a reservation
+func (s *guestStore) NewToken(ref string, expiry time.Duration) string {
+	token := newRandomID()
+	s.mutex.Lock()
+	s.data.Tokens[token] = &model.GuestLinkToken{
+		ReservationRef: ref,
+		ExpiresAt:      time.Now().Add(expiry),
+	}
+	// Cleaning expired tokens
+	for k, t := range s.data.Tokens {
+		if time.Now().After(t.ExpiresAt) {
+			delete(s.data.Tokens, k)
+		}
+	}
+	s.mutex.Unlock()
+	s.save()
+	return token
+}
+
+// UseToken consumes a one-time token and returns the reservation associated to it
+func (s *guestStore) UseToken(token string) (string, bool) {
+	s.mutex.Lock()
+	t, ok := s.data.Tokens[token]
+	delete(s.data.Tokens, token)
+	s.mutex.Unlock()
+	if !ok {
+		return "", false
+	}
+	s.save()
+	if time.Now().After(t.ExpiresAt) {
+		return "", false
+	}
+	return t.ReservationRef, true
+}
+
+func (s *guestStore) AddLateCheckoutRequest(r model.LateCheckoutRequest) model.LateCheckoutRequest {
+	r.ID = newRandomID()
+	r.RequestedAt = time.Now()
+	s.mutex.Lock()
+	s.data.LateCheckouts[r.ID] = &r
+	s.mutex.Unlock()
+	s.save()
+	return r
+}
+
+// PopLateCheckoutRequest removes a late checkout request and returns it
+func (s *guestStore) PopLateCheckoutRequest(id string) (model.LateCheckoutRequest, bool) {
+	s.mutex.Lock()
+	r, ok := s.data.LateCheckouts[id]
+	delete(s.data.LateCheckouts, id)
+	s.mutex.Unlock()
+	if !ok {
+		return model.LateCheckoutRequest{}, false
+	}
+	s.save()
+	return *r, true
+}
+
+func (s *guestStore) save() {
+	if s.cache == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.cache.Save(guestsCacheKey, s.data); err != nil {
+		log.Error().Err(err).Msg("Unable to save guests to cache")
+	}
+}
+
+func (s *guestStore) load() error {
+	if s.cache == nil {
+		return cache.ErrCacheNoClient
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.cache.Load(guestsCacheKey, &s.data)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNoServers):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	if s.data.Links == nil {
+		s.data.Links = make(map[int64]*model.GuestLink)
+	}
+	if s.data.Tokens == nil {
+		s.data.Tokens = make(map[string]*model.GuestLinkToken)
+	}
+	if s.data.LateCheckouts == nil {
+		s.data.LateCheckouts = make(map[string]*model.LateCheckoutRequest)
+	}
+	return nil
+}
@@ -1,17 +1,16 @@
 package telegrambot
 
 import (
-	"fmt"
-
 	"github.com/mymmrac/telego"
+	"github.com/nmaupu/nuki-logger/i18n"
 )
 
 func (b *nukiBot) handlerBattery(update telego.Update, msg *telego.SendMessageParams) {
 	res, err := b.SmartlockReader.Execute()
 	if err != nil {
-		msg.Text = fmt.Sprintf("Unable to read smartlock status from API, err=%v", err)
+		msg.Text = i18n.T(b.lang(update), "error.api_smartlock", err)
 	} else {
 		msg.ParseMode = telego.ModeMarkdown
-		msg.Text = res.PrettyFormat()
+		msg.Text = res.PrettyFormat(b.lang(update))
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/looplab/fsm"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/rs/zerolog/log"
 )
 
@@ -26,18 +27,19 @@ func (bot *nukiBot) fsmCodeCommand() *fsm.FSM {
 	)
 }
 
-func (bot nukiBot) fsmEventCodeDefault(ctx context.Context, e *fsm.Event) {
+func (bot *nukiBot) fsmEventCodeDefault(ctx context.Context, e *fsm.Event) {
 	log.Debug().Str("callback", FSMEventDefault).Msg("Callback called")
 	msg := reinitMetadataMessage(e.FSM)
+	lang := bot.fsmLang(e.FSM)
 
 	res, err := bot.ReservationsReader.Execute()
 	if err != nil {
-		fsmRuntimeErr(e, fmt.Sprintf("Unable to get reservations from API, err=%v", err), "reset")
+		fsmRuntimeErr(e, i18n.T(lang, "error.api_reservations", err), "reset")
 		return
 	}
 
 	if len(res) == 0 {
-		fsmRuntimeErr(e, "No reservation available", "reset")
+		fsmRuntimeErr(e, i18n.T(lang, "error.no_reservation"), "reset")
 	}
 
 	var keyboardButtons []telego.InlineKeyboardButton
@@ -49,24 +51,25 @@ func (bot nukiBot) fsmEventCodeDefault(ctx context.Context, e *fsm.Event) {
 
 	msg.ReplyMarkup = tu.InlineKeyboard(keyboardButtons)
 	msg.ParseMode = telego.ModeMarkdown
-	msg.Text = "What *reservation* do you want the code for?"
+	msg.Text = i18n.T(lang, "code.ask")
 	msg.ProtectContent = true
 
 }
 
-func (bot nukiBot) fsmEventCodeResaReceived(ctx context.Context, e *fsm.Event) {
+func (bot *nukiBot) fsmEventCodeResaReceived(ctx context.Context, e *fsm.Event) {
 	log.Debug().Str("callback", "resa_received").Msg("Callback called")
 	msg := reinitMetadataMessage(e.FSM)
+	lang := bot.fsmLang(e.FSM)
 
 	data, err := checkFSMArg(e)
 	if err != nil {
-		msg.Text = fmt.Sprintf("An error occurred: %s", err)
+		msg.Text = i18n.T(lang, "error.generic", err)
 		return
 	}
 
 	res, err := bot.SmartlockAuthReader.Execute()
 	if err != nil {
-		msg.Text = fmt.Sprintf("Unable to get smartlock auth from API, err=%v", err)
+		msg.Text = i18n.T(lang, "error.api_auth", err)
 		return
 	}
 
@@ -74,9 +77,9 @@ func (bot nukiBot) fsmEventCodeResaReceived(ctx context.Context, e *fsm.Event) {
 
 	for _, v := range res {
 		if v.Name == data {
-			msg.Text = fmt.Sprintf("Code for *%s*: %d", v.Name, v.Code)
+			msg.Text = i18n.T(lang, "code.result", v.Name, v.Code)
 			return
 		}
 	}
-	msg.Text = fmt.Sprintf("Unable to find any code for *%s*", data)
+	msg.Text = i18n.T(lang, "code.not_found", data)
 }
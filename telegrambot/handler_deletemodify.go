@@ -7,10 +7,11 @@ import (
 	"github.com/looplab/fsm"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/rs/zerolog/log"
 )
 
-func (bot nukiBot) fsmDeleteModifyCommand() *fsm.FSM {
+func (bot *nukiBot) fsmDeleteModifyCommand() *fsm.FSM {
 	return fsm.NewFSM(
 		"idle",
 		fsm.Events{
@@ -26,27 +27,32 @@ func (bot nukiBot) fsmDeleteModifyCommand() *fsm.FSM {
 	)
 }
 
-func (bot nukiBot) fsmEventDeleteModifyDefault(ctx context.Context, e *fsm.Event) {
+func (bot *nukiBot) fsmEventDeleteModifyDefault(ctx context.Context, e *fsm.Event) {
 	log.Debug().Str("callback", FSMEventDefault).Msg("Callback called")
 	msg := reinitMetadataMessage(e.FSM)
+	lang := bot.fsmLang(e.FSM)
 
 	modifs := bot.reservationPendingModificationRoutine.GetAllPendingModifications()
 	if len(modifs) == 0 {
-		fsmRuntimeErr(e, "No pending modification available", "reset")
+		fsmRuntimeErr(e, i18n.T(lang, "deletemodify.none"), "reset")
 		return
 
 	}
 
 	var keyboardButtons []telego.InlineKeyboardButton
 	for _, modif := range modifs {
+		label := modif.ReservationRef
+		if times := modif.FormatAccessTimes(); times != "" {
+			label = fmt.Sprintf("%s (%s)", label, times)
+		}
 		keyboardButtons = append(keyboardButtons,
-			tu.InlineKeyboardButton(fmt.Sprintf("%s (%s - %s)", modif.ReservationRef, modif.FormatCheckIn(), modif.FormatCheckOut())).
+			tu.InlineKeyboardButton(label).
 				WithCallbackData(NewCallbackData("resa_received", modif.ReservationRef)))
 	}
 
 	msg.ReplyMarkup = tu.InlineKeyboard(keyboardButtons)
 	msg.ParseMode = telego.ModeMarkdown
-	msg.Text = "What *pending modification* do you want to remove?"
+	msg.Text = i18n.T(lang, "deletemodify.ask")
 	msg.ProtectContent = true
 
 }
@@ -54,13 +60,14 @@ func (bot nukiBot) fsmEventDeleteModifyDefault(ctx context.Context, e *fsm.Event
 func (bot *nukiBot) fsmEventDeleteModifyReceived(ctx context.Context, e *fsm.Event) {
 	log.Debug().Str("callback", "resa_received").Msg("Callback called")
 	msg := reinitMetadataMessage(e.FSM)
+	lang := bot.fsmLang(e.FSM)
 
 	data, err := checkFSMArg(e)
 	if err != nil {
-		msg.Text = fmt.Sprintf("An error occurred: %s", err)
+		msg.Text = i18n.T(lang, "error.generic", err)
 		return
 	}
 
 	bot.reservationPendingModificationRoutine.DeletePendingModification(data)
-	msg.Text = "Done\nClick on /listmodify to display the new list"
+	msg.Text = i18n.T(lang, "deletemodify.done")
 }
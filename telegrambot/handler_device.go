@@ -0,0 +1,30 @@
+package telegrambot
+
+import (
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/lifecycle"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+func (b *nukiBot) handlerDevice(update telego.Update, msg *telego.SendMessageParams) {
+	log.Debug().Msg("handlerDevice called")
+
+	res, err := b.SmartlockReader.Execute()
+	if err != nil {
+		msg.Text = i18n.T(b.lang(update), "error.api_smartlock", err)
+		return
+	}
+	loc, err := time.LoadLocation(b.Sender.GetTimezone())
+	if err != nil {
+		loc = time.UTC
+	}
+	var events []model.DeviceEvent
+	if b.lifecycleTracker != nil {
+		events = b.lifecycleTracker.Events()
+	}
+	msg.Text = lifecycle.Format(b.lang(update), loc, *res, events)
+}
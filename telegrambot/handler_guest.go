@@ -0,0 +1,468 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/looplab/fsm"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	callbackLateCheckout = "late_checkout"
+)
+
+func (b *nukiBot) location() *time.Location {
+	loc, err := time.LoadLocation(b.Sender.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// findReservation returns the reservation associated to ref if it's not finished yet
+func (b *nukiBot) findReservation(ref string) (*model.NukiReservationResponse, error) {
+	res, err := b.ReservationsReader.Execute()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range res {
+		if r.Reference == ref && r.EndDate.After(time.Now()) {
+			resa := r
+			return &resa, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to find any ongoing reservation for %s", ref)
+}
+
+// hostChatIDs returns all the chats to send guests' requests to
+func (b *nukiBot) hostChatIDs() []int64 {
+	roles := b.getSettings().Roles
+	ids := slices.Clone(roles[RoleHost])
+	for _, id := range roles.Admins() {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		ids = append(ids, b.Sender.ChatID)
+	}
+	return ids
+}
+
+// IsGuestAllowed returns true if an update comes from a linked guest or tries to link a guest
+func (b *nukiBot) IsGuestAllowed(update telego.Update) bool {
+	if !b.getSettings().Guests.Enabled || update.Message == nil {
+		return false
+	}
+	if b.guests.IsLinked(update.Message.From.ID) {
+		return true
+	}
+	text := update.Message.Text
+	if strings.HasPrefix(text, "/start") || text == "/link" {
+		return true
+	}
+	// The booking reference answering /link
+	s := b.sessions.Get(update.Message.Chat.ID)
+	return s != nil && s.CommandName == "/link" && s.WaitsForInput()
+}
+
+func (b *nukiBot) guestWelcomeText(lang string, link model.GuestLink) string {
+	return i18n.T(lang, "guest.welcome_linked", emoji.WavingHand.String(), link.ReservationRef)
+}
+
+// linkGuestFromToken links a guest using a one-time token received with /start
+func (b *nukiBot) linkGuestFromToken(update telego.Update, token string) *telego.SendMessageParams {
+	lang := b.lang(update)
+	msg := &telego.SendMessageParams{ParseMode: telego.ModeMarkdown}
+	ref, ok := b.guests.UseToken(token)
+	if !ok {
+		msg.Text = i18n.T(lang, "guest.link_invalid")
+		return msg
+	}
+	if _, err := b.findReservation(ref); err != nil {
+		msg.Text = i18n.T(lang, "guest.resa_unavailable")
+		return msg
+	}
+
+	link := b.linkGuest(update, ref)
+	msg.Text = b.guestWelcomeText(lang, link)
+	return msg
+}
+
+// linkGuest links the sender of update to a reservation and tells the hosts about it
+func (b *nukiBot) linkGuest(update telego.Update, ref string) model.GuestLink {
+	from := update.Message.From
+	link := b.guests.Link(from.ID, update.Message.Chat.ID, ref)
+	b.notifyHosts("guest.host_linked", emoji.Link.String(), guestDisplayName(from), from.ID, ref)
+	return link
+}
+
+// guestLinkFailed counts a wrong booking reference, telling the hosts when the user gets locked out
+func (b *nukiBot) guestLinkFailed(from *telego.User, ref string) {
+	if !b.guests.LinkFailed(from.ID) {
+		return
+	}
+	log.Warn().
+		Int64("from_id", from.ID).
+		Str("ref", ref).
+		Msg("Guest locked out after too many wrong booking references")
+	b.notifyHosts("guest.host_link_locked", emoji.Warning.String(), guestDisplayName(from), from.ID, ref)
+}
+
+// notifyHosts sends the message associated to key to all hosts in their language
+func (b *nukiBot) notifyHosts(key string, args ...any) {
+	for _, chatID := range b.hostChatIDs() {
+		text := i18n.T(b.langForChat(chatID), key, args...)
+		if _, err := b.Sender.SendMessage(tu.Message(tu.ID(chatID), text)); err != nil {
+			log.Error().Err(err).Int64("chat_id", chatID).Msg("Unable to notify host")
+		}
+	}
+}
+
+func guestDisplayName(u *telego.User) string {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if u.Username != "" {
+		name += " @" + u.Username
+	}
+	return name
+}
+
+func (b *nukiBot) fsmLinkCommand() *fsm.FSM {
+	return fsm.NewFSM(
+		"idle",
+		fsm.Events{
+			{Name: FSMEventDefault, Src: []string{"idle"}, Dst: "wait_ref"},
+			{Name: "ref_received", Src: []string{"wait_ref"}, Dst: "finished"},
+			{Name: "reset", Src: []string{"idle", "wait_ref", "finished"}, Dst: "idle"},
+		},
+		fsm.Callbacks{
+			FSMEventDefault: func(ctx context.Context, e *fsm.Event) {
+				log.Debug().Str("callback", FSMEventDefault).Msg("Callback called")
+				msg := reinitMetadataMessage(e.FSM)
+				msg.ParseMode = telego.ModeMarkdown
+				msg.Text = i18n.T(b.fsmLang(e.FSM), "guest.ask_ref")
+				waitForUserInput(e.FSM, "ref_received")
+			},
+			"before_ref_received": func(ctx context.Context, e *fsm.Event) {
+				log.Debug().Str("callback", "before_ref_received").Msg("Callback called")
+				userInputReceived(e.FSM)
+				msg := reinitMetadataMessage(e.FSM)
+				msg.ParseMode = telego.ModeMarkdown
+				lang := b.fsmLang(e.FSM)
+
+				data, err := checkFSMArg(e)
+				if err != nil {
+					msg.Text = i18n.T(lang, "error.generic", err)
+					return
+				}
+				update, err := getMetadataTelegoUpdate(FSMMetadataTelegoUpdate, e.FSM)
+				if err != nil || update.Message == nil {
+					msg.Text = i18n.T(lang, "error.retry")
+					return
+				}
+
+				from := update.Message.From
+				if until, locked := b.guests.LinkLockedUntil(from.ID); locked {
+					msg.Text = i18n.T(lang, "guest.link_locked", until.In(b.location()).Format(model.FormatTimeHoursMinutes))
+					return
+				}
+
+				ref := strings.TrimSpace(data)
+				if _, err := b.findReservation(ref); err != nil {
+					log.Warn().Err(err).
+						Int64("from_id", from.ID).
+						Str("ref", ref).
+						Msg("Guest tried to link to an unknown reservation")
+					b.guestLinkFailed(from, ref)
+					msg.Text = i18n.T(lang, "guest.ref_not_found")
+					return
+				}
+				// Guests must never see other guests' reservations, another guest needs a link from the hosts
+				for _, other := range b.guests.GetByReservation(ref) {
+					if other.UserID != from.ID {
+						log.Warn().
+							Int64("from_id", from.ID).
+							Int64("linked_user_id", other.UserID).
+							Str("ref", ref).
+							Msg("Guest tried to link to a reservation already linked")
+						b.guestLinkFailed(from, ref)
+						msg.Text = i18n.T(lang, "guest.ref_already_linked")
+						return
+					}
+				}
+
+				link := b.linkGuest(*update, ref)
+				msg.Text = b.guestWelcomeText(lang, link)
+			},
+			"finished": fsmEventFinished,
+		},
+	)
+}
+
+func (b *nukiBot) handlerMyCode(update telego.Update, msg *telego.SendMessageParams) {
+	lang := b.lang(update)
+	link, ok := b.guests.Get(update.Message.From.ID)
+	if !ok {
+		msg.Text = i18n.T(lang, "guest.not_linked")
+		return
+	}
+
+	if _, err := b.findReservation(link.ReservationRef); err != nil {
+		b.guests.Unlink(link.UserID)
+		msg.Text = i18n.T(lang, "guest.resa_over")
+		return
+	}
+
+	auths, err := b.SmartlockAuthReader.Execute()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to get smartlock auth from API")
+		msg.Text = i18n.T(lang, "guest.code_unavailable")
+		return
+	}
+
+	msg.ParseMode = telego.ModeMarkdown
+	msg.ProtectContent = true
+	for _, auth := range auths {
+		// Guests must only see their own code
+		if auth.Name != link.ReservationRef {
+			continue
+		}
+		loc := b.location()
+		msg.Text = i18n.T(lang, "guest.code",
+			emoji.Key.String(), auth.Code,
+			auth.AllowedFromDate.In(loc).Format("02/01 15:04"),
+			auth.AllowedUntilDate.In(loc).Format("02/01 15:04"))
+		return
+	}
+	msg.Text = i18n.T(lang, "guest.code_not_yet")
+}
+
+func (b *nukiBot) fsmLateCheckoutCommand() *fsm.FSM {
+	return fsm.NewFSM(
+		"idle",
+		fsm.Events{
+			{Name: FSMEventDefault, Src: []string{"idle"}, Dst: "wait_time"},
+			{Name: "time_received", Src: []string{"wait_time"}, Dst: "finished"},
+			{Name: "reset", Src: []string{"idle", "wait_time", "finished"}, Dst: "idle"},
+		},
+		fsm.Callbacks{
+			FSMEventDefault: func(ctx context.Context, e *fsm.Event) {
+				log.Debug().Str("callback", FSMEventDefault).Msg("Callback called")
+				msg := reinitMetadataMessage(e.FSM)
+
+				var buttons []telego.InlineKeyboardButton
+				for _, t := range b.getSettings().Guests.GetLateCheckoutTimes() {
+					buttons = append(buttons, tu.InlineKeyboardButton(t).WithCallbackData(NewCallbackData("time_received", t)))
+				}
+				msg.ReplyMarkup = tu.InlineKeyboard(tu.InlineKeyboardRow(buttons...))
+				msg.Text = i18n.T(b.fsmLang(e.FSM), "guest.ask_checkout")
+			},
+			"before_time_received": func(ctx context.Context, e *fsm.Event) {
+				log.Debug().Str("callback", "before_time_received").Msg("Callback called")
+				msg := reinitMetadataMessage(e.FSM)
+				lang := b.fsmLang(e.FSM)
+
+				data, err := checkFSMArg(e)
+				if err != nil {
+					msg.Text = i18n.T(lang, "error.generic", err)
+					return
+				}
+				checkOut, err := time.Parse(model.FormatTimeHoursMinutes, data)
+				if err != nil {
+					msg.Text = i18n.T(lang, "error.parse", data)
+					return
+				}
+				update, err := getMetadataTelegoUpdate(FSMMetadataTelegoUpdate, e.FSM)
+				if err != nil || update.CallbackQuery == nil {
+					msg.Text = i18n.T(lang, "error.retry")
+					return
+				}
+				link, ok := b.guests.Get(update.CallbackQuery.From.ID)
+				if !ok {
+					msg.Text = i18n.T(lang, "guest.not_linked")
+					return
+				}
+
+				req := b.guests.AddLateCheckoutRequest(model.LateCheckoutRequest{
+					UserID:         link.UserID,
+					ChatID:         link.ChatID,
+					ReservationRef: link.ReservationRef,
+					CheckOutTime:   checkOut,
+				})
+				log.Info().Object("request", req).Msg("Late checkout requested")
+				b.notifyHostsLateCheckout(req)
+				msg.Text = i18n.T(lang, "guest.request_sent")
+			},
+			"finished": fsmEventFinished,
+		},
+	)
+}
+
+func (b *nukiBot) notifyHostsLateCheckout(req model.LateCheckoutRequest) {
+	name, err := b.ReservationsReader.GetReservationName(req.ReservationRef)
+	if err != nil {
+		name = req.ReservationRef
+	}
+	for _, chatID := range b.hostChatIDs() {
+		lang := b.langForChat(chatID)
+		_, err := b.Sender.SendMessage(&telego.SendMessageParams{
+			ChatID:    tu.ID(chatID),
+			ParseMode: telego.ModeMarkdown,
+			Text: i18n.T(lang, "guest.host_request",
+				emoji.AlarmClock.String(), name, req.ReservationRef, req.FormatCheckOut()),
+			ReplyMarkup: tu.InlineKeyboard(tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(fmt.Sprintf("%s %s", i18n.T(lang, "guest.approve"), emoji.ThumbsUp.String())).
+					WithCallbackData(NewCallbackData(callbackLateCheckout, "approve"+CallbackCommandSeparator+req.ID)),
+				tu.InlineKeyboardButton(fmt.Sprintf("%s %s", i18n.T(lang, "guest.deny"), emoji.ThumbsDown.String())).
+					WithCallbackData(NewCallbackData(callbackLateCheckout, "deny"+CallbackCommandSeparator+req.ID)),
+			)),
+		})
+		if err != nil {
+			log.Error().Err(err).Int64("chat_id", chatID).Msg("Unable to send late checkout request to host")
+		}
+	}
+}
+
+// callbackLateCheckoutAnswer handles a host's answer to a late checkout request
+func (b *nukiBot) callbackLateCheckoutAnswer(update telego.Update, data string) (*telego.SendMessageParams, error) {
+	from := update.CallbackQuery.From
+	lang := b.lang(update)
+	if !b.getSettings().Roles.IsAllowed(from.ID, []Role{RoleHost}) {
+		return nil, errors.New(i18n.T(lang, "guest.answer_forbidden", emoji.NoEntry.String()))
+	}
+
+	answer, id, _ := strings.Cut(data, CallbackCommandSeparator)
+	req, ok := b.guests.PopLateCheckoutRequest(id)
+	if !ok {
+		return nil, errors.New(i18n.T(lang, "guest.already_answered"))
+	}
+
+	msg := &telego.SendMessageParams{}
+	if answer != "approve" {
+		log.Info().Object("request", req).Int64("by", from.ID).Msg("Late checkout denied")
+		msg.Text = i18n.T(lang, "guest.denied_host", req.ReservationRef)
+		b.sendToGuest(req.ChatID, "guest.denied_guest")
+		return msg, nil
+	}
+
+	resa, err := b.findReservation(req.ReservationRef)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Object("request", req).Int64("by", from.ID).Msg("Late checkout approved")
+	startDate := resa.StartDate.In(b.location())
+	checkIn := time.Date(0, 1, 1, startDate.Hour(), startDate.Minute(), 0, 0, time.UTC)
+	b.reservationPendingModificationRoutine.AddPendingModification(model.ReservationPendingModification{
+		ReservationRef: req.ReservationRef,
+		CheckInTime:    checkIn,
+		CheckOutTime:   req.CheckOutTime,
+		FromChatID:     from.ID,
+	})
+	b.reservationPendingModificationRoutine.ApplyModificationNow()
+
+	msg.Text = i18n.T(lang, "guest.approved_host", req.FormatCheckOut(), req.ReservationRef)
+	b.sendToGuest(req.ChatID, "guest.approved_guest", emoji.PartyPopper.String(), req.FormatCheckOut())
+	return msg, nil
+}
+
+// sendToGuest sends the message associated to key in the language of the guest's chat
+func (b *nukiBot) sendToGuest(chatID int64, key string, args ...any) {
+	text := i18n.T(b.langForChat(chatID), key, args...)
+	if _, err := b.Sender.SendMessage(tu.Message(tu.ID(chatID), text)); err != nil {
+		log.Error().Err(err).Int64("chat_id", chatID).Msg("Unable to send message to guest")
+	}
+}
+
+// fsmGuestLinkCommand lets a host generate a one-time link for a guest
+func (b *nukiBot) fsmGuestLinkCommand() *fsm.FSM {
+	return fsm.NewFSM(
+		"idle",
+		fsm.Events{
+			{Name: FSMEventDefault, Src: []string{"idle"}, Dst: "waiting_for_resa"},
+			{Name: "resa_received", Src: []string{"idle", "waiting_for_resa"}, Dst: "finished"},
+			{Name: "reset", Src: []string{"idle", "waiting_for_resa", "finished"}, Dst: "idle"},
+		},
+		fsm.Callbacks{
+			FSMEventDefault: func(ctx context.Context, e *fsm.Event) {
+				log.Debug().Str("callback", FSMEventDefault).Msg("Callback called")
+				msg := reinitMetadataMessage(e.FSM)
+				lang := b.fsmLang(e.FSM)
+
+				res, err := b.ReservationsReader.Execute()
+				if err != nil {
+					fsmRuntimeErr(e, i18n.T(lang, "error.api_reservations", err), "reset")
+					return
+				}
+				var keyboardButtons []telego.InlineKeyboardButton
+				for _, resa := range res {
+					keyboardButtons = append(keyboardButtons,
+						tu.InlineKeyboardButton(fmt.Sprintf("%s (%s)", resa.Name, resa.Reference)).
+							WithCallbackData(NewCallbackData("resa_received", resa.Reference)))
+				}
+				if len(keyboardButtons) == 0 {
+					fsmRuntimeErr(e, i18n.T(lang, "error.no_reservation"), "reset")
+					return
+				}
+
+				msg.ReplyMarkup = tu.InlineKeyboard(keyboardButtons)
+				msg.ParseMode = telego.ModeMarkdown
+				msg.Text = i18n.T(lang, "guest.ask_resa_link")
+			},
+			"before_resa_received": func(ctx context.Context, e *fsm.Event) {
+				log.Debug().Str("callback", "before_resa_received").Msg("Callback called")
+				msg := reinitMetadataMessage(e.FSM)
+				lang := b.fsmLang(e.FSM)
+
+				data, err := checkFSMArg(e)
+				if err != nil {
+					msg.Text = i18n.T(lang, "error.generic", err)
+					return
+				}
+
+				me, err := b.bot.GetMe()
+				if err != nil {
+					msg.Text = i18n.T(lang, "error.bot_info", err)
+					return
+				}
+
+				expiry := b.getSettings().Guests.GetLinkTokenExpiry()
+				token := b.guests.NewToken(data, expiry)
+				msg.ProtectContent = true
+				msg.Text = i18n.T(lang, "guest.link", data, expiry, me.Username, token)
+			},
+			"finished": fsmEventFinished,
+		},
+	)
+}
+
+// OnNewLogs welcomes guests the first time their door code works
+func (b *nukiBot) OnNewLogs(logs []model.NukiSmartlockLogResponse) {
+	if !b.getSettings().Guests.Enabled {
+		return
+	}
+
+	for _, l := range logs {
+		if l.Trigger != model.NukiTriggerKeypad || l.Source != model.NukiSourceKeypadCode || l.State != model.NukiStateSuccess {
+			continue
+		}
+
+		for _, guest := range b.guests.GetByReservation(l.Name) {
+			if !b.guests.SetWelcomed(guest.UserID) {
+				continue
+			}
+			log.Info().Object("guest", guest).Msg("Welcoming guest")
+			b.sendToGuest(guest.ChatID, "guest.welcome", emoji.House.String())
+		}
+	}
+}
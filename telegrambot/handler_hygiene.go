@@ -0,0 +1,121 @@
+package telegrambot
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/hygiene"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	callbackHygiene = "hygiene"
+	// hygieneMaxButtons limits the size of the report's keyboard
+	hygieneMaxButtons = 20
+)
+
+// SetHygieneConfig configures the review of the smartlock's authorizations
+func (b *nukiBot) SetHygieneConfig(c hygiene.Config) {
+	b.hygieneConfig = c
+	b.authHygieneRoutine.SetConfig(c)
+}
+
+func (b *nukiBot) startAuthHygiene() {
+	b.authHygieneRoutine.AddOnReportListener(func(r hygiene.Report) {
+		for _, chatID := range b.hostChatIDs() {
+			msg := &telego.SendMessageParams{ChatID: tu.ID(chatID)}
+			b.fillHygieneReport(b.langForChat(chatID), r, msg)
+			if _, err := b.Sender.SendMessage(msg); err != nil {
+				log.Error().Err(err).Int64("chat_id", chatID).Msg("Unable to send auth hygiene report")
+			}
+		}
+	})
+	b.authHygieneRoutine.Start()
+}
+
+func (b *nukiBot) handlerHygiene(update telego.Update, msg *telego.SendMessageParams) {
+	log.Debug().Msg("handlerHygiene called")
+
+	report, err := b.authHygieneRoutine.Review()
+	if err != nil {
+		msg.Text = i18n.T(b.lang(update), "error.api_auth", err)
+		return
+	}
+	b.fillHygieneReport(b.lang(update), report, msg)
+}
+
+// fillHygieneReport sets the report and a keyboard to disable or delete each reported auth
+func (b *nukiBot) fillHygieneReport(lang string, r hygiene.Report, msg *telego.SendMessageParams) {
+	msg.Text = r.Format(lang, b.location())
+
+	auths := r.Select(hygiene.Kinds)
+	if len(auths) == 0 {
+		return
+	}
+	if len(auths) > hygieneMaxButtons {
+		auths = auths[:hygieneMaxButtons]
+		msg.Text += "\n\n" + i18n.T(lang, "hygiene.buttons_truncated", hygieneMaxButtons)
+	}
+	var rows [][]telego.InlineKeyboardButton
+	for _, f := range auths {
+		var row []telego.InlineKeyboardButton
+		if f.Enabled {
+			row = append(row, tu.InlineKeyboardButton(i18n.T(lang, "hygiene.disable", f.Name)).
+				WithCallbackData(NewCallbackData(callbackHygiene, string(hygiene.ActionDisable)+CallbackCommandSeparator+f.AuthID)))
+		}
+		row = append(row, tu.InlineKeyboardButton(i18n.T(lang, "hygiene.delete", f.Name)).
+			WithCallbackData(NewCallbackData(callbackHygiene, string(hygiene.ActionDelete)+CallbackCommandSeparator+f.AuthID)))
+		rows = append(rows, tu.InlineKeyboardRow(row...))
+	}
+	msg.ReplyMarkup = tu.InlineKeyboard(rows...)
+}
+
+func (b *nukiBot) callbackHygieneAction(update telego.Update, data string) (*telego.SendMessageParams, error) {
+	from := update.CallbackQuery.From
+	lang := b.lang(update)
+	if !b.getSettings().Roles.IsAllowed(from.ID, []Role{RoleHost}) {
+		return nil, errors.New(i18n.T(lang, "hygiene.forbidden", emoji.NoEntry.String()))
+	}
+
+	a, authID, _ := strings.Cut(data, CallbackCommandSeparator)
+	action, err := hygiene.ParseAction(a)
+	if err != nil {
+		return nil, err
+	}
+	// Reading auths again as the report may be old
+	auths, err := b.SmartlockAuthReader.Execute()
+	if err != nil {
+		return nil, errors.New(i18n.T(lang, "error.api_auth", err))
+	}
+	var finding *hygiene.Finding
+	for _, auth := range auths {
+		if auth.Id == authID {
+			finding = &hygiene.Finding{AuthID: auth.Id, Name: auth.Name}
+			break
+		}
+	}
+	if finding == nil {
+		return nil, errors.New(i18n.T(lang, "hygiene.not_found", authID))
+	}
+
+	modifier := nukiapi.SmartlockAuthModifier{
+		APICaller:   b.SmartlockAuthReader.APICaller,
+		SmartlockID: b.SmartlockAuthReader.SmartlockID,
+	}
+	if err := finding.Apply(modifier, action); err != nil {
+		log.Error().Err(err).Str("auth", finding.Name).Str("action", string(action)).Msg("Unable to change authorization")
+		return nil, errors.New(i18n.T(lang, "hygiene.action_failed", emoji.Warning.String(), finding.Name, err))
+	}
+
+	log.Info().Str("auth", finding.Name).Str("action", string(action)).Int64("by", from.ID).Msg("Authorization changed from hygiene report")
+	key := "hygiene.disabled"
+	if action == hygiene.ActionDelete {
+		key = "hygiene.deleted"
+	}
+	return &telego.SendMessageParams{Text: i18n.T(lang, key, emoji.CheckMarkButton.String(), finding.Name)}, nil
+}
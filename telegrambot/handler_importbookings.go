@@ -0,0 +1,46 @@
+package telegrambot
+
+import (
+	"github.com/enescakir/emoji"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/booking"
+	"github.com/nmaupu/nuki-logger/i18n"
+	tgbroutine "github.com/nmaupu/nuki-logger/telegrambot/routine"
+	"github.com/rs/zerolog/log"
+)
+
+// startBookingImport imports bookings from the feeds on a regular interval and reports conflicts to the bot's chat
+func (b *nukiBot) startBookingImport() {
+	settings := b.getSettings()
+	b.bookingImportRoutine = tgbroutine.NewBookingImportRoutine(
+		b.bookingsConfig,
+		b.location(),
+		b.ReservationsReader,
+		b.reservationPendingModificationRoutine,
+		settings.DefaultCheckIn,
+		settings.DefaultCheckOut,
+		b.Sender.ChatID,
+	)
+	b.bookingImportRoutine.AddOnConflictListener(func(c booking.Conflict) {
+		lang := b.langForChat(b.Sender.ChatID)
+		_, err := b.Sender.SendMessage(tu.Message(tu.ID(b.Sender.ChatID),
+			i18n.T(lang, "booking.conflict",
+				emoji.Warning.String(),
+				i18n.T(lang, "booking.conflict_"+string(c.Kind)),
+				c.Booking.Feed.Name,
+				c.Booking.Reference,
+				c.Booking.FormatDates(),
+				c.Other)))
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to report booking conflict")
+		}
+	})
+	b.bookingImportRoutine.Start()
+}
+
+func (b *nukiBot) handlerImportBookings(update telego.Update, msg *telego.SendMessageParams) {
+	log.Debug().Msg("handlerImportBookings called")
+	b.bookingImportRoutine.ImportNow()
+	msg.Text = i18n.T(b.lang(update), "common.done")
+}
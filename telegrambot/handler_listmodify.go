@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mymmrac/telego"
@@ -15,7 +16,7 @@ func (b *nukiBot) handlerListModify(update telego.Update, msg *telego.SendMessag
 
 	modifs := b.reservationPendingModificationRoutine.GetAllPendingModifications()
 	if len(modifs) == 0 {
-		msg.Text = "No pending modification"
+		msg.Text = i18n.T(b.lang(update), "listmodify.none")
 		return
 	}
 
@@ -25,7 +26,17 @@ func (b *nukiBot) handlerListModify(update telego.Update, msg *telego.SendMessag
 		if v.ModificationDone {
 			em = emoji.CheckMark.String()
 		}
-		strs = append(strs, fmt.Sprintf("*%s*: %s - %s %s", v.ReservationRef, v.FormatCheckIn(), v.FormatCheckOut(), em))
+		str := fmt.Sprintf("*%s*: %s", v.ReservationRef, em)
+		if times := v.FormatAccessTimes(); times != "" {
+			str = fmt.Sprintf("*%s*: %s %s", v.ReservationRef, times, em)
+		}
+		if v.GuestName != "" {
+			str = fmt.Sprintf("%s %s", str, v.GuestName)
+		}
+		if v.IsImported() {
+			str = fmt.Sprintf("%s (%s)", str, v.Source)
+		}
+		strs = append(strs, str)
 	}
 
 	msg.ParseMode = telego.ModeMarkdown
@@ -6,108 +6,579 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/enescakir/emoji"
 	"github.com/looplab/fsm"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
-
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/nmaupu/nuki-logger/messaging"
 	"github.com/nmaupu/nuki-logger/model"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/maps"
 )
 
-func (bot *nukiBot) fsmLogsCommand() *fsm.FSM {
+const (
+	fsmMetadataLogsBrowser = "logsBrowser"
+
+	logsBrowserPageSize    = 10
+	logsBrowserBatchSize   = 50
+	logsBrowserMaxRequests = 5
+	logsBrowserAll         = "all"
+	logsBrowserDateFormat  = "2006-01-02"
+	logsBrowserMonthFormat = "2006-01"
+)
+
+// logsCursor is the position of a page in the logs
+type logsCursor struct {
+	ToDate time.Time `json:"to_date"`
+	// Skip holds logs already displayed having the same date as ToDate, dates sent to the API having a precision of one second
+	Skip []string `json:"skip"`
+}
+
+func (c *logsCursor) advance(l model.NukiSmartlockLogResponse) {
+	date := l.Date.Truncate(time.Second)
+	if !date.Equal(c.ToDate) {
+		c.ToDate = date
+		c.Skip = nil
+	}
+	c.Skip = append(c.Skip, l.ID)
+}
+
+// logsBrowser holds the pages visited and the filters of a /logs session
+type logsBrowser struct {
+	// Cursors of all pages visited, the last one being the current page
+	Cursors []logsCursor `json:"cursors"`
+	// Next is the cursor of the next (older) page, nil if there is no more logs
+	Next        *logsCursor        `json:"next,omitempty"`
+	Action      *model.NukiAction  `json:"action,omitempty"`
+	Trigger     *model.NukiTrigger `json:"trigger,omitempty"`
+	Reservation string             `json:"reservation,omitempty"`
+	User        string             `json:"user,omitempty"`
+	FailedOnly  bool               `json:"failed_only,omitempty"`
+	// Day set with the date picker, zero for all days
+	Day time.Time `json:"day,omitempty"`
+}
+
+func newLogsBrowser(loc *time.Location) *logsBrowser {
+	lb := &logsBrowser{}
+	lb.firstPage(loc)
+	return lb
+}
+
+// firstPage goes back to the most recent logs matching the filters
+func (lb *logsBrowser) firstPage(loc *time.Location) {
+	var toDate time.Time
+	if !lb.Day.IsZero() {
+		toDate = lb.dayStart(loc).AddDate(0, 0, 1).Add(-time.Second)
+	}
+	lb.Cursors = []logsCursor{{ToDate: toDate}}
+	lb.Next = nil
+}
+
+func (lb *logsBrowser) dayStart(loc *time.Location) time.Time {
+	if lb.Day.IsZero() {
+		return time.Time{}
+	}
+	y, m, d := lb.Day.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func (lb *logsBrowser) page() int {
+	return len(lb.Cursors)
+}
+
+func (lb *logsBrowser) matches(e *messaging.Event) bool {
+	l := e.Log
+	switch {
+	case lb.Action != nil && l.Action != *lb.Action,
+		lb.Trigger != nil && l.Trigger != *lb.Trigger,
+		lb.Reservation != "" && l.Name != lb.Reservation,
+		lb.FailedOnly && l.State == model.NukiStateSuccess:
+		return false
+	}
+	if lb.User != "" {
+		user := strings.ToLower(lb.User)
+		return strings.Contains(strings.ToLower(l.Name), user) ||
+			strings.Contains(strings.ToLower(e.ReservationName), user)
+	}
+	return true
+}
+
+func (lb *logsBrowser) filtersSummary(lang string, loc *time.Location) string {
+	var filters []string
+	if lb.Action != nil {
+		filters = append(filters, fmt.Sprintf("%s=%s", i18n.T(lang, "logs.action"), lb.Action.Translate(lang)))
+	}
+	if lb.Trigger != nil {
+		filters = append(filters, fmt.Sprintf("%s=%s", i18n.T(lang, "logs.trigger"), lb.Trigger.Translate(lang)))
+	}
+	if lb.Reservation != "" {
+		filters = append(filters, fmt.Sprintf("%s=%s", i18n.T(lang, "logs.resa"), lb.Reservation))
+	}
+	if lb.User != "" {
+		filters = append(filters, fmt.Sprintf("%s=%s", i18n.T(lang, "logs.user"), lb.User))
+	}
+	if lb.FailedOnly {
+		filters = append(filters, i18n.T(lang, "logs.failed"))
+	}
+	if !lb.Day.IsZero() {
+		filters = append(filters, fmt.Sprintf("%s=%s", i18n.T(lang, "logs.date"), lb.dayStart(loc).Format(logsBrowserDateFormat)))
+	}
+	return strings.Join(filters, ", ")
+}
+
+func getMetadataLogsBrowser(f *fsm.FSM) (*logsBrowser, error) {
+	res, ok := f.Metadata(fsmMetadataLogsBrowser)
+	if !ok {
+		return nil, metadataNotFoundErr(fsmMetadataLogsBrowser)
+	}
+	lb, ok := res.(*logsBrowser)
+	if !ok {
+		return nil, metadataNotFoundErr(fsmMetadataLogsBrowser)
+	}
+	return lb, nil
+}
+
+func (b *nukiBot) fsmLogsCommand() *fsm.FSM {
+	browsing := []string{"browsing"}
 	return fsm.NewFSM(
 		"idle",
 		fsm.Events{
-			{Name: FSMEventDefault, Src: []string{"idle"}, Dst: "wait_for_number"},
-			{Name: "number_received", Src: []string{"idle", "wait_for_number"}, Dst: "finished"},
-			{Name: "reset", Src: []string{"idle", "wait_for_number", "finished"}, Dst: "idle"},
+			{Name: FSMEventDefault, Src: []string{"idle"}, Dst: "browsing"},
+			{Name: "logs_page", Src: browsing, Dst: "browsing"},
+			{Name: "logs_filter", Src: browsing, Dst: "browsing"},
+			{Name: "logs_action", Src: browsing, Dst: "browsing"},
+			{Name: "logs_trigger", Src: browsing, Dst: "browsing"},
+			{Name: "logs_resa", Src: browsing, Dst: "browsing"},
+			{Name: "logs_month", Src: browsing, Dst: "browsing"},
+			{Name: "logs_date", Src: browsing, Dst: "browsing"},
+			{Name: "user_received", Src: browsing, Dst: "browsing"},
+			{Name: "logs_close", Src: browsing, Dst: "finished"},
+			{Name: "reset", Src: []string{"idle", "browsing", "finished"}, Dst: "idle"},
 		},
 		fsm.Callbacks{
-			FSMEventDefault:          bot.fsmEventLogsDefault,
-			"before_number_received": bot.fsmEventLogsNumberReceived,
-			"finished":               fsmEventFinished,
+			FSMEventDefault: func(ctx context.Context, e *fsm.Event) {
+				log.Trace().Str("callback", FSMEventDefault).Msg("Callback called")
+				lb := newLogsBrowser(b.location())
+				e.FSM.SetMetadata(fsmMetadataLogsBrowser, lb)
+				b.logsBrowserShowPage(e, lb)
+			},
+			"before_logs_page": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, b.logsBrowserPageReceived)
+			},
+			"before_logs_filter": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, b.logsBrowserFilterReceived)
+			},
+			"before_logs_action": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, func(e *fsm.Event, lb *logsBrowser, data string) {
+					lb.Action = nil
+					if n, err := strconv.Atoi(data); err == nil {
+						action := model.NukiAction(n)
+						lb.Action = &action
+					}
+					lb.firstPage(b.location())
+					b.logsBrowserShowPage(e, lb)
+				})
+			},
+			"before_logs_trigger": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, func(e *fsm.Event, lb *logsBrowser, data string) {
+					lb.Trigger = nil
+					if n, err := strconv.Atoi(data); err == nil {
+						trigger := model.NukiTrigger(n)
+						lb.Trigger = &trigger
+					}
+					lb.firstPage(b.location())
+					b.logsBrowserShowPage(e, lb)
+				})
+			},
+			"before_logs_resa": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, func(e *fsm.Event, lb *logsBrowser, data string) {
+					lb.Reservation = ""
+					if data != logsBrowserAll {
+						lb.Reservation = data
+					}
+					lb.firstPage(b.location())
+					b.logsBrowserShowPage(e, lb)
+				})
+			},
+			"before_logs_month": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, func(e *fsm.Event, lb *logsBrowser, data string) {
+					month, err := time.ParseInLocation(logsBrowserMonthFormat, data, b.location())
+					if err != nil {
+						month = time.Now().In(b.location())
+					}
+					b.logsBrowserShowDatePicker(e, month)
+				})
+			},
+			"before_logs_date": func(ctx context.Context, e *fsm.Event) {
+				b.logsBrowserCallback(e, func(e *fsm.Event, lb *logsBrowser, data string) {
+					lb.Day = time.Time{}
+					if day, err := time.ParseInLocation(logsBrowserDateFormat, data, b.location()); err == nil {
+						lb.Day = day
+					}
+					lb.firstPage(b.location())
+					b.logsBrowserShowPage(e, lb)
+				})
+			},
+			"before_user_received": func(ctx context.Context, e *fsm.Event) {
+				log.Trace().Str("callback", "before_user_received").Msg("Callback called")
+				userInputReceived(e.FSM)
+				lb, err := getMetadataLogsBrowser(e.FSM)
+				if err != nil {
+					fsmRuntimeErr(e, err.Error(), "reset")
+					return
+				}
+				data, _ := checkFSMArg(e)
+				lb.User = strings.TrimSpace(data)
+				lb.firstPage(b.location())
+				// Answering a text message, the browser is sent again as a new message
+				b.logsBrowserShowPage(e, lb)
+			},
+			"before_logs_close": func(ctx context.Context, e *fsm.Event) {
+				log.Trace().Str("callback", "before_logs_close").Msg("Callback called")
+				msg := reinitMetadataMessage(e.FSM)
+				editMessageInPlace(e.FSM)
+				msg.Text = i18n.T(b.fsmLang(e.FSM), "logs.closed")
+			},
+			"finished": fsmEventFinished,
 		},
 	)
 }
 
-func (b nukiBot) fsmEventLogsDefault(ctx context.Context, e *fsm.Event) {
-	log.Trace().Str("callback", "run").Msg("Callback called")
-	msg := &telego.SendMessageParams{}
-	e.FSM.SetMetadata(FSMMetadataMessage, msg)
+// logsBrowserCallback gets the browser's state and data associated to a callback before calling f
+func (b *nukiBot) logsBrowserCallback(e *fsm.Event, f func(e *fsm.Event, lb *logsBrowser, data string)) {
+	log.Trace().Str("callback", e.Event).Msg("Callback called")
+	userInputReset(e.FSM)
+	lb, err := getMetadataLogsBrowser(e.FSM)
+	if err != nil {
+		fsmRuntimeErr(e, err.Error(), "reset")
+		return
+	}
+	data, err := checkFSMArg(e)
+	if err != nil {
+		msg := reinitMetadataMessage(e.FSM)
+		msg.Text = i18n.T(b.fsmLang(e.FSM), "error.generic", err)
+		return
+	}
+	f(e, lb, data)
+}
 
-	keyboard := tu.InlineKeyboard(
-		tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton("5").WithCallbackData(NewCallbackData("number_received", "5")),
-			tu.InlineKeyboardButton("10").WithCallbackData(NewCallbackData("number_received", "10")),
-			tu.InlineKeyboardButton("20").WithCallbackData(NewCallbackData("number_received", "20")),
-			tu.InlineKeyboardButton("30").WithCallbackData(NewCallbackData("number_received", "30")),
-			tu.InlineKeyboardButton("40").WithCallbackData(NewCallbackData("number_received", "40")),
-			tu.InlineKeyboardButton("50").WithCallbackData(NewCallbackData("number_received", "50")),
-		),
-	)
+func (b *nukiBot) logsBrowserPageReceived(e *fsm.Event, lb *logsBrowser, data string) {
+	switch data {
+	case "older":
+		if lb.Next != nil {
+			lb.Cursors = append(lb.Cursors, *lb.Next)
+		}
+	case "newer":
+		if len(lb.Cursors) > 1 {
+			lb.Cursors = lb.Cursors[:len(lb.Cursors)-1]
+		}
+	case "latest":
+		lb.firstPage(b.location())
+	}
+	b.logsBrowserShowPage(e, lb)
+}
 
-	msg.Text = "How many logs do you want to get?"
-	msg.ReplyMarkup = keyboard
-	msg.ProtectContent = true
+func (b *nukiBot) logsBrowserFilterReceived(e *fsm.Event, lb *logsBrowser, data string) {
+	lang := b.fsmLang(e.FSM)
+	switch data {
+	case "action":
+		actions := maps.Keys(model.NukiActions)
+		slices.Sort(actions)
+		var buttons []telego.InlineKeyboardButton
+		for _, a := range actions {
+			buttons = append(buttons, tu.InlineKeyboardButton(a.Translate(lang)).
+				WithCallbackData(NewCallbackData("logs_action", strconv.Itoa(int(a)))))
+		}
+		b.logsBrowserShowChoices(e, "logs.ask_action", "logs_action", buttons)
+	case "trigger":
+		triggers := maps.Keys(model.NukiTriggers)
+		slices.Sort(triggers)
+		var buttons []telego.InlineKeyboardButton
+		for _, t := range triggers {
+			buttons = append(buttons, tu.InlineKeyboardButton(fmt.Sprintf("%s %s", t.GetEmoji(), t.Translate(lang))).
+				WithCallbackData(NewCallbackData("logs_trigger", strconv.Itoa(int(t)))))
+		}
+		b.logsBrowserShowChoices(e, "logs.ask_trigger", "logs_trigger", buttons)
+	case "resa":
+		res, err := b.ReservationsReader.Execute()
+		if err != nil {
+			msg := reinitMetadataMessage(e.FSM)
+			editMessageInPlace(e.FSM)
+			msg.Text = i18n.T(lang, "error.api_reservations", err)
+			msg.ReplyMarkup = tu.InlineKeyboard(tu.InlineKeyboardRow(b.logsBrowserBackButton(lang)))
+			return
+		}
+		var buttons []telego.InlineKeyboardButton
+		for _, r := range res {
+			buttons = append(buttons, tu.InlineKeyboardButton(fmt.Sprintf("%s (%s)", r.Name, r.Reference)).
+				WithCallbackData(NewCallbackData("logs_resa", r.Reference)))
+		}
+		b.logsBrowserShowChoices(e, "logs.ask_resa", "logs_resa", buttons)
+	case "user":
+		msg := reinitMetadataMessage(e.FSM)
+		editMessageInPlace(e.FSM)
+		msg.Text = i18n.T(lang, "logs.ask_user")
+		msg.ReplyMarkup = tu.InlineKeyboard(tu.InlineKeyboardRow(b.logsBrowserBackButton(lang)))
+		waitForUserInput(e.FSM, "user_received")
+	case "date":
+		month := lb.Day
+		if month.IsZero() {
+			month = time.Now()
+		}
+		b.logsBrowserShowDatePicker(e, month.In(b.location()))
+	case "failed":
+		lb.FailedOnly = !lb.FailedOnly
+		lb.firstPage(b.location())
+		b.logsBrowserShowPage(e, lb)
+	case "reset":
+		*lb = logsBrowser{}
+		lb.firstPage(b.location())
+		b.logsBrowserShowPage(e, lb)
+	default: // back
+		b.logsBrowserShowPage(e, lb)
+	}
 }
 
-func (bot nukiBot) fsmEventLogsNumberReceived(ctx context.Context, e *fsm.Event) {
-	log.Trace().Str("callback", "number_received").Msg("Callback called")
-	msg := &telego.SendMessageParams{}
-	e.FSM.SetMetadata(FSMMetadataMessage, msg)
+func (b *nukiBot) logsBrowserBackButton(lang string) telego.InlineKeyboardButton {
+	return tu.InlineKeyboardButton(fmt.Sprintf("%s %s", emoji.LeftArrow.String(), i18n.T(lang, "logs.back"))).
+		WithCallbackData(NewCallbackData("logs_filter", "back"))
+}
 
-	data, err := checkFSMArg(e)
-	if err != nil {
-		msg.Text = fmt.Sprintf("An error occurred: %v", err)
-		return
+// logsBrowserShowChoices displays the values a filter can take, 2 per row
+func (b *nukiBot) logsBrowserShowChoices(e *fsm.Event, questionKey, event string, buttons []telego.InlineKeyboardButton) {
+	lang := b.fsmLang(e.FSM)
+	msg := reinitMetadataMessage(e.FSM)
+	editMessageInPlace(e.FSM)
+
+	var rows [][]telego.InlineKeyboardButton
+	for i := 0; i < len(buttons); i += 2 {
+		rows = append(rows, tu.InlineKeyboardRow(buttons[i:min(i+2, len(buttons))]...))
 	}
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(i18n.T(lang, "logs.all")).WithCallbackData(NewCallbackData(event, logsBrowserAll)),
+		b.logsBrowserBackButton(lang),
+	))
 
-	limit, err := strconv.Atoi(data)
-	if err != nil {
-		msg.Text = "Number of logs must be an integer!"
-		return
+	msg.Text = i18n.T(lang, questionKey)
+	msg.ReplyMarkup = tu.InlineKeyboard(rows...)
+}
+
+// logsBrowserShowDatePicker displays a calendar of the given month
+func (b *nukiBot) logsBrowserShowDatePicker(e *fsm.Event, month time.Time) {
+	lang := b.fsmLang(e.FSM)
+	msg := reinitMetadataMessage(e.FSM)
+	editMessageInPlace(e.FSM)
+
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	current := NewCallbackData("logs_month", first.Format(logsBrowserMonthFormat))
+	months := strings.Split(i18n.T(lang, "date.months"), ",")
+	title := fmt.Sprintf("%s %d", months[first.Month()-1], first.Year())
+
+	rows := [][]telego.InlineKeyboardButton{
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("<").WithCallbackData(NewCallbackData("logs_month", first.AddDate(0, -1, 0).Format(logsBrowserMonthFormat))),
+			tu.InlineKeyboardButton(title).WithCallbackData(current),
+			tu.InlineKeyboardButton(">").WithCallbackData(NewCallbackData("logs_month", first.AddDate(0, 1, 0).Format(logsBrowserMonthFormat))),
+		),
 	}
 
-	lr := bot.LogsReader
-	lr.Limit = limit
-	res, err := lr.Execute()
-	if err != nil {
-		msg.Text = fmt.Sprintf("Unable to get logs from API, err=%v", err)
-		return
+	var weekdays []telego.InlineKeyboardButton
+	for _, wd := range strings.Split(i18n.T(lang, "date.weekdays"), ",") {
+		weekdays = append(weekdays, tu.InlineKeyboardButton(wd).WithCallbackData(current))
 	}
-	slices.Reverse(res)
+	rows = append(rows, tu.InlineKeyboardRow(weekdays...))
 
-	var logsLines []string
-	for _, l := range res {
-		logger := log.With().
-			Str("ref", l.Name).
-			Str("command", "logs").
-			Logger()
-		reservationName := l.Name
-		if l.Trigger == model.NukiTriggerKeypad && l.Source == model.NukiSourceKeypadCode && l.State != model.NukiStateWrongKeypadCode {
-			reservationName, err = bot.ReservationsReader.GetReservationName(l.Name)
-			if err != nil {
-				logger.Error().
-					Err(err).
-					Msg("Unable to get reservation's name, keeping original ref as name")
-				reservationName = l.Name
-			}
+	// Weeks start on monday
+	offset := (int(first.Weekday()) + 6) % 7
+	var week []telego.InlineKeyboardButton
+	for i := 0; i < offset; i++ {
+		week = append(week, tu.InlineKeyboardButton(" ").WithCallbackData(current))
+	}
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		week = append(week, tu.InlineKeyboardButton(strconv.Itoa(day.Day())).
+			WithCallbackData(NewCallbackData("logs_date", day.Format(logsBrowserDateFormat))))
+		if len(week) == 7 {
+			rows = append(rows, tu.InlineKeyboardRow(week...))
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, tu.InlineKeyboardButton(" ").WithCallbackData(current))
 		}
+		rows = append(rows, tu.InlineKeyboardRow(week...))
+	}
 
-		str, err := bot.Sender.FormatLogEvent(&messaging.Event{
-			Log:             l,
-			ReservationName: reservationName,
-		})
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(i18n.T(lang, "logs.all")).WithCallbackData(NewCallbackData("logs_date", logsBrowserAll)),
+		b.logsBrowserBackButton(lang),
+	))
+
+	msg.Text = i18n.T(lang, "logs.ask_date")
+	msg.ReplyMarkup = tu.InlineKeyboard(rows...)
+}
+
+// logsBrowserShowPage displays the current page of logs along with navigation and filter buttons
+func (b *nukiBot) logsBrowserShowPage(e *fsm.Event, lb *logsBrowser) {
+	lang := b.fsmLang(e.FSM)
+	loc := b.location()
+	msg := reinitMetadataMessage(e.FSM)
+	editMessageInPlace(e.FSM)
+	msg.ProtectContent = true
+
+	lines := []string{i18n.T(lang, "logs.title", emoji.FileFolder.String(), lb.page())}
+	if filters := lb.filtersSummary(lang, loc); filters != "" {
+		lines = append(lines, i18n.T(lang, "logs.filters", filters))
+	}
+	lines = append(lines, "")
+
+	events, err := b.fetchLogsPage(lb, loc)
+	switch {
+	case err != nil:
+		lines = append(lines, i18n.T(lang, "error.api_logs", err))
+	case len(events) == 0:
+		lines = append(lines, i18n.T(lang, "logs.none"))
+	}
+	for _, ev := range events {
+		str, err := b.Sender.FormatLogEventLang(ev, lang)
 		if err != nil {
 			log.Error().Err(err).
-				Str("log_id", l.ID).
+				Str("log_id", ev.Log.ID).
 				Msg("Unable to format log event")
 			continue
 		}
-		logsLines = append(logsLines, str)
+		if !b.Sender.IncludeDate {
+			str = fmt.Sprintf("%s - %s", ev.Log.Date.In(loc).Format("02/01 15:04:05"), str)
+		}
+		lines = append(lines, str)
+	}
+	msg.Text = strings.Join(lines, "\n")
+
+	var nav []telego.InlineKeyboardButton
+	if lb.page() > 1 {
+		nav = append(nav, tu.InlineKeyboardButton(fmt.Sprintf("<< %s", i18n.T(lang, "logs.newer"))).
+			WithCallbackData(NewCallbackData("logs_page", "newer")))
+	}
+	nav = append(nav, tu.InlineKeyboardButton(fmt.Sprintf("%s %d", emoji.CounterclockwiseArrowsButton.String(), lb.page())).
+		WithCallbackData(NewCallbackData("logs_page", "refresh")))
+	if lb.Next != nil {
+		nav = append(nav, tu.InlineKeyboardButton(fmt.Sprintf("%s >>", i18n.T(lang, "logs.older"))).
+			WithCallbackData(NewCallbackData("logs_page", "older")))
+	}
+
+	filterButton := func(key, data string, active bool) telego.InlineKeyboardButton {
+		label := i18n.T(lang, key)
+		if active {
+			label = fmt.Sprintf("%s %s", emoji.CheckMarkButton.String(), label)
+		}
+		return tu.InlineKeyboardButton(label).WithCallbackData(NewCallbackData("logs_filter", data))
+	}
+
+	msg.ReplyMarkup = tu.InlineKeyboard(
+		nav,
+		tu.InlineKeyboardRow(
+			filterButton("logs.action", "action", lb.Action != nil),
+			filterButton("logs.trigger", "trigger", lb.Trigger != nil),
+			filterButton("logs.resa", "resa", lb.Reservation != ""),
+		),
+		tu.InlineKeyboardRow(
+			filterButton("logs.user", "user", lb.User != ""),
+			filterButton("logs.failed", "failed", lb.FailedOnly),
+			filterButton("logs.date", "date", !lb.Day.IsZero()),
+		),
+		tu.InlineKeyboardRow(
+			filterButton("logs.reset", "reset", false),
+			tu.InlineKeyboardButton(i18n.T(lang, "logs.close")).WithCallbackData(NewCallbackData("logs_close", "close")),
+		),
+	)
+}
+
+// fetchLogsPage gets the logs of the current page matching the filters and sets the cursor of the next page.
+// As filters are applied locally, several requests can be needed to fill a page.
+func (b *nukiBot) fetchLogsPage(lb *logsBrowser, loc *time.Location) ([]*messaging.Event, error) {
+	var events []*messaging.Event
+	cursor := lb.Cursors[len(lb.Cursors)-1]
+	cursor.Skip = slices.Clone(cursor.Skip)
+	lb.Next = nil
+	var names map[string]string
+
+	for i := 0; i < logsBrowserMaxRequests; i++ {
+		lr := b.LogsReader
+		lr.Limit = logsBrowserBatchSize
+		lr.FromDate = lb.dayStart(loc)
+		lr.ToDate = cursor.ToDate
+		res, err := lr.Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		progress := false
+		for _, l := range res {
+			if slices.Contains(cursor.Skip, l.ID) {
+				continue
+			}
+			if len(events) == logsBrowserPageSize {
+				lb.Next = &cursor
+				return events, nil
+			}
+			progress = true
+			cursor.advance(l)
+
+			if names == nil && isReservationLog(l) {
+				names = b.reservationNames()
+			}
+			e := &messaging.Event{Log: l, ReservationName: logReservationName(l, names)}
+			if lb.matches(e) {
+				events = append(events, e)
+			}
+		}
+
+		if len(res) < logsBrowserBatchSize { // No more logs
+			return events, nil
+		}
+		if !progress { // More logs than a batch during the same second
+			cursor.ToDate = cursor.ToDate.Add(-time.Second)
+			cursor.Skip = nil
+		}
+	}
+
+	// Too many logs filtered, letting the user ask for more
+	lb.Next = &cursor
+	return events, nil
+}
+
+// isReservationLog returns true if l is a keypad code log, named after its reservation's ref
+func isReservationLog(l model.NukiSmartlockLogResponse) bool {
+	return l.Trigger == model.NukiTriggerKeypad && l.Source == model.NukiSourceKeypadCode && l.State != model.NukiStateWrongKeypadCode
+}
+
+// reservationNames returns the reservations' names by ref, reservations being read once per page
+func (b *nukiBot) reservationNames() map[string]string {
+	names := make(map[string]string)
+	resas, err := b.ReservationsReader.Execute()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("command", "logs").
+			Msg("Unable to get reservations to resolve names, keeping refs")
+		return names
 	}
+	for _, resa := range resas {
+		names[resa.Reference] = resa.Name
+	}
+	return names
+}
 
-	msg.Text = strings.Join(logsLines, "\n")
+// logReservationName returns the name of the reservation associated to a keypad code log
+func logReservationName(l model.NukiSmartlockLogResponse, names map[string]string) string {
+	if !isReservationLog(l) {
+		return l.Name
+	}
+	if name, ok := names[l.Name]; ok {
+		return name
+	}
+	return l.Name
 }
@@ -4,31 +4,78 @@ import (
 	"fmt"
 
 	"github.com/enescakir/emoji"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
+// Menu items are identified by their message key, labels are registered for every language
 var (
-	menuBattery    = fmt.Sprintf("%s %s", emoji.Battery.String(), "Battery")
-	menuCode       = fmt.Sprintf("%s %s", emoji.InputNumbers.String(), "Code")
-	menuHelp       = fmt.Sprintf("%s %s", emoji.QuestionMark.String(), "Help")
-	menuLogs       = fmt.Sprintf("%s %s", emoji.FileFolder.String(), "Logs")
-	menuResas      = fmt.Sprintf("%s %s", emoji.OpenBook.String(), "Resas")
-	menuListModify = fmt.Sprintf("%s %s", emoji.Pencil.String(), "List modifs")
-	menuModify     = fmt.Sprintf("%s %s", emoji.Gear.String(), "Modify")
+	menuBattery    = "menu.battery"
+	menuCode       = "menu.code"
+	menuHelp       = "menu.help"
+	menuLogs       = "menu.logs"
+	menuResas      = "menu.resas"
+	menuListModify = "menu.listmodify"
+	menuModify     = "menu.modify"
+	// Guests menu
+	menuMyCode       = "menu.mycode"
+	menuLateCheckout = "menu.latecheckout"
+
+	menuEmojis = map[string]emoji.Emoji{
+		menuBattery:      emoji.Battery,
+		menuCode:         emoji.InputNumbers,
+		menuHelp:         emoji.QuestionMark,
+		menuLogs:         emoji.FileFolder,
+		menuResas:        emoji.OpenBook,
+		menuListModify:   emoji.Pencil,
+		menuModify:       emoji.Gear,
+		menuMyCode:       emoji.Key,
+		menuLateCheckout: emoji.AlarmClock,
+	}
 )
 
+// menuLabel returns the label of a menu item in the given language
+func menuLabel(lang, item string) string {
+	return fmt.Sprintf("%s %s", menuEmojis[item].String(), i18n.T(lang, item))
+}
+
+// addMenuCommand registers cmd for the label of a menu item in every language
+func (c Commands) addMenuCommand(item string, cmd Command) {
+	for _, lang := range i18n.Languages() {
+		c[menuLabel(lang, item)] = cmd
+	}
+}
+
 func (b *nukiBot) handlerMenu(update telego.Update, msg *telego.SendMessageParams) {
 	log.Debug().Msg("menuHandler called")
-	keyboard := tu.Keyboard(
-		tu.KeyboardRow(tu.KeyboardButton(menuBattery), tu.KeyboardButton(menuLogs), tu.KeyboardButton(menuCode)),
-		tu.KeyboardRow(tu.KeyboardButton(menuResas), tu.KeyboardButton(menuModify), tu.KeyboardButton(menuListModify)),
-		tu.KeyboardRow(tu.KeyboardButton(menuHelp)),
-	).WithResizeKeyboard().WithInputFieldPlaceholder("Menu")
+	lang := b.lang(update)
+	menuRows := [][]string{
+		{menuBattery, menuLogs, menuCode},
+		{menuResas, menuModify, menuListModify},
+		{menuMyCode, menuLateCheckout},
+		{menuHelp},
+	}
+
+	// Only display items the user is allowed to use
+	var rows [][]telego.KeyboardButton
+	for _, menuRow := range menuRows {
+		var row []telego.KeyboardButton
+		for _, item := range menuRow {
+			label := menuLabel(lang, item)
+			if cmd, ok := b.commands[label]; ok && b.isAllowed(cmd, update.Message.From.ID) {
+				row = append(row, tu.KeyboardButton(label))
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, tu.KeyboardRow(row...))
+		}
+	}
+	keyboard := tu.Keyboard(rows...).WithResizeKeyboard().WithInputFieldPlaceholder(i18n.T(lang, "bot.menu"))
 
-	msg.Text = "Menu"
+	msg.Text = i18n.T(lang, "bot.menu")
 	msg.ReplyMarkup = keyboard
 	msg.ProtectContent = true
 }
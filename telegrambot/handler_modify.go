@@ -9,15 +9,16 @@ import (
 	"github.com/looplab/fsm"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/nmaupu/nuki-logger/model"
 	"github.com/rs/zerolog/log"
 )
 
-func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
-	const (
-		metadataPendingModif = "resaPendingModif"
-	)
+const (
+	fsmMetadataPendingModif = "resaPendingModif"
+)
 
+func (bot *nukiBot) fsmModifyCommand() *fsm.FSM {
 	return fsm.NewFSM(
 		"idle",
 		fsm.Events{
@@ -46,7 +47,7 @@ func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
 				log.Debug().Str("callback", "wait_resa_id").Msg("Callback called")
 				msg := reinitMetadataMessage(e.FSM)
 				msg.ParseMode = telego.ModeMarkdown
-				msg.Text = "Enter *reservation* ID"
+				msg.Text = i18n.T(bot.fsmLang(e.FSM), "modify.ask_resa")
 				waitForUserInput(e.FSM, "resa_id_received")
 			},
 			"before_resa_id_received": func(ctx context.Context, e *fsm.Event) {
@@ -62,7 +63,7 @@ func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
 					return
 				}
 
-				e.FSM.SetMetadata(metadataPendingModif, &model.ReservationPendingModification{
+				e.FSM.SetMetadata(fsmMetadataPendingModif, &model.ReservationPendingModification{
 					ReservationRef: data,
 					FromChatID:     update.Message.From.ID,
 				})
@@ -70,7 +71,7 @@ func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
 			"wait_check_in": func(ctx context.Context, e *fsm.Event) {
 				log.Debug().Str("callback", "wait_check_in").Msg("Callback called")
 				msg := reinitMetadataMessage(e.FSM)
-				msg.Text = fmt.Sprintf("Enter check-in time (default: %s)", bot.DefaultCheckIn.Format(model.FormatTimeHoursMinutes))
+				msg.Text = i18n.T(bot.fsmLang(e.FSM), "modify.ask_check_in", bot.getSettings().DefaultCheckIn.Format(model.FormatTimeHoursMinutes))
 				waitForUserInput(e.FSM, "check_in_received")
 			},
 			"before_check_in_received": func(ctx context.Context, e *fsm.Event) {
@@ -80,7 +81,7 @@ func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
 
 				data, _ := checkFSMArg(e)
 
-				modif, err := getMetadataReservationPendingModification(metadataPendingModif, e.FSM)
+				modif, err := getMetadataReservationPendingModification(fsmMetadataPendingModif, e.FSM)
 				if err != nil {
 					fsmRuntimeErr(e, err.Error(), "reset")
 					return
@@ -88,14 +89,14 @@ func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
 
 				modif.CheckInTime, err = time.Parse(model.FormatTimeHoursMinutes, data)
 				if err != nil {
-					fsmRuntimeErr(e, fmt.Sprintf("unable to parse %s", data), "recover_check_in")
+					fsmRuntimeErr(e, i18n.T(bot.fsmLang(e.FSM), "error.parse", data), "recover_check_in")
 					return
 				}
 			},
 			"wait_check_out": func(ctx context.Context, e *fsm.Event) {
 				log.Debug().Str("callback", "wait_check_out").Msg("Callback called")
 				msg := reinitMetadataMessage(e.FSM)
-				msg.Text = fmt.Sprintf("Enter check-out time (default: %s)", bot.DefaultCheckOut.Format(model.FormatTimeHoursMinutes))
+				msg.Text = i18n.T(bot.fsmLang(e.FSM), "modify.ask_check_out", bot.getSettings().DefaultCheckOut.Format(model.FormatTimeHoursMinutes))
 				waitForUserInput(e.FSM, "check_out_received")
 			},
 			"before_check_out_received": func(ctx context.Context, e *fsm.Event) {
@@ -105,59 +106,58 @@ func (bot nukiBot) fsmModifyCommand() *fsm.FSM {
 
 				data, _ := checkFSMArg(e)
 
-				modif, err := getMetadataReservationPendingModification(metadataPendingModif, e.FSM)
+				modif, err := getMetadataReservationPendingModification(fsmMetadataPendingModif, e.FSM)
 				if err != nil {
-					fsmRuntimeErr(e, fmt.Sprintf("An error occurred, %s", err.Error()), "reset")
+					fsmRuntimeErr(e, i18n.T(bot.fsmLang(e.FSM), "error.generic", err), "reset")
 					return
 				}
 
 				modif.CheckOutTime, err = time.Parse(model.FormatTimeHoursMinutes, data)
 				if err != nil {
-					fsmRuntimeErr(e, fmt.Sprintf("unable to parse %s", data), "recover_check_out")
+					fsmRuntimeErr(e, i18n.T(bot.fsmLang(e.FSM), "error.parse", data), "recover_check_out")
 					return
 				}
 			},
 			"wait_confirmation": func(ctx context.Context, e *fsm.Event) {
 				log.Debug().Str("callback", "wait_confirmation").Msg("Callback called")
 				msg := reinitMetadataMessage(e.FSM)
+				lang := bot.fsmLang(e.FSM)
 
-				modif, err := getMetadataReservationPendingModification(metadataPendingModif, e.FSM)
+				modif, err := getMetadataReservationPendingModification(fsmMetadataPendingModif, e.FSM)
 				if err != nil {
-					fsmRuntimeErr(e, fmt.Sprintf("An error occurred, %s", err.Error()), "reset")
+					fsmRuntimeErr(e, i18n.T(lang, "error.generic", err), "reset")
 					return
 				}
 
 				keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
-					tu.InlineKeyboardButton(fmt.Sprintf("Yes %s", emoji.ThumbsUp.String())).
+					tu.InlineKeyboardButton(fmt.Sprintf("%s %s", i18n.T(lang, "common.yes"), emoji.ThumbsUp.String())).
 						WithCallbackData(NewCallbackData("confirmation_received", "yes")),
-					tu.InlineKeyboardButton(fmt.Sprintf("No %s", emoji.ThumbsDown.String())).
+					tu.InlineKeyboardButton(fmt.Sprintf("%s %s", i18n.T(lang, "common.no"), emoji.ThumbsDown.String())).
 						WithCallbackData(NewCallbackData("confirmation_received", "no")),
 				))
 
 				msg.ReplyMarkup = keyboard
 				msg.ParseMode = telego.ModeMarkdown
-				m := fmt.Sprintf("%s Do you confirm?\n", emoji.OpenBook.String())
-				m += fmt.Sprintf("*Reservation*: %s\n", modif.ReservationRef)
-				m += fmt.Sprintf("*Check-in*: %s\n", modif.FormatCheckIn())
-				m += fmt.Sprintf("*Check-out*: %s", modif.FormatCheckOut())
-				msg.Text = m
+				msg.Text = i18n.T(lang, "modify.confirm",
+					emoji.OpenBook.String(), modif.ReservationRef, modif.FormatCheckIn(), modif.FormatCheckOut())
 			},
 			"before_confirmation_received": func(ctx context.Context, e *fsm.Event) {
 				log.Debug().Str("callback", "before_confirmation_received").Msg("Callback called")
 				msg := reinitMetadataMessage(e.FSM)
+				lang := bot.fsmLang(e.FSM)
 				data, _ := checkFSMArg(e)
 
-				modif, err := getMetadataReservationPendingModification(metadataPendingModif, e.FSM)
+				modif, err := getMetadataReservationPendingModification(fsmMetadataPendingModif, e.FSM)
 				if err != nil {
-					fsmRuntimeErr(e, fmt.Sprintf("An error occurred, %s", err.Error()), "reset")
+					fsmRuntimeErr(e, i18n.T(lang, "error.generic", err), "reset")
 					return
 				}
 
 				if data == "yes" {
 					bot.reservationPendingModificationRoutine.AddPendingModification(*modif)
-					msg.Text = "Confirmed!"
+					msg.Text = i18n.T(lang, "common.confirmed")
 				} else {
-					msg.Text = "Canceled..."
+					msg.Text = i18n.T(lang, "common.canceled")
 				}
 			},
 			"finished": fsmEventFinished,
@@ -7,12 +7,14 @@ import (
 
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/i18n"
 )
 
 func (b *nukiBot) handlerResa(update telego.Update, msg *telego.SendMessageParams) {
+	lang := b.lang(update)
 	res, err := b.ReservationsReader.Execute()
 	if err != nil {
-		msg.Text = fmt.Sprintf("Unable to get reservations from API, err=%v", err)
+		msg.Text = i18n.T(lang, "error.api_reservations", err)
 		return
 	}
 
@@ -33,14 +35,14 @@ func (b *nukiBot) handlerResa(update telego.Update, msg *telego.SendMessageParam
 		lines = append(lines, line)
 	}
 	msg.ParseMode = telego.ModeMarkdown
-	msg.Text = fmt.Sprintf("Reservations:\n%s", strings.Join(lines, "\n"))
+	msg.Text = i18n.T(lang, "resa.title", strings.Join(lines, "\n"))
 
-	b.bot.SendMessage(&telego.SendMessageParams{
+	b.Sender.SendMessage(&telego.SendMessageParams{
 		ChatID: tu.ID(update.Message.From.ID),
-		Text:   "Reservation IDs for easy copy/paste:",
+		Text:   i18n.T(lang, "resa.ids"),
 	})
 	for _, r := range res {
-		b.bot.SendMessage(&telego.SendMessageParams{
+		b.Sender.SendMessage(&telego.SendMessageParams{
 			ChatID: tu.ID(update.Message.From.ID),
 			Text:   r.Reference,
 		})
@@ -1,15 +1,15 @@
 package telegrambot
 
 import (
-	"fmt"
-
 	"github.com/mymmrac/telego"
+	"github.com/nmaupu/nuki-logger/i18n"
 )
 
 func (b *nukiBot) handlerSavePendingReservationsToCache(update telego.Update, msg *telego.SendMessageParams) {
+	lang := b.lang(update)
 	if err := b.reservationPendingModificationRoutine.SaveToCache(); err != nil {
-		msg.Text = fmt.Sprintf("Unable to save to cache, err=%v", err)
+		msg.Text = i18n.T(lang, "error.cache_save", err)
 	} else {
-		msg.Text = "Done."
+		msg.Text = i18n.T(lang, "common.done")
 	}
 }
@@ -0,0 +1,18 @@
+package telegrambot
+
+import (
+	"github.com/mymmrac/telego"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+func (b *nukiBot) handlerStatus(update telego.Update, msg *telego.SendMessageParams) {
+	log.Debug().Msg("handlerStatus called")
+
+	res, err := b.SmartlockReader.Execute()
+	if err != nil {
+		msg.Text = i18n.T(b.lang(update), "error.api_smartlock", err)
+		return
+	}
+	msg.Text = res.StatusFormat(b.lang(update))
+}
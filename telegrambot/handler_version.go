@@ -1,9 +1,8 @@
 package telegrambot
 
 import (
-	"fmt"
-
 	"github.com/mymmrac/telego"
+	"github.com/nmaupu/nuki-logger/i18n"
 	"github.com/nmaupu/nuki-logger/model"
 	"github.com/rs/zerolog/log"
 )
@@ -12,5 +11,5 @@ func (b *nukiBot) handlerVersion(update telego.Update, msg *telego.SendMessagePa
 	log.Debug().Msg("handlerVersion called")
 
 	msg.ParseMode = telego.ModeMarkdown
-	msg.Text = fmt.Sprintf("%s, version %s, build %s", model.AppName, model.ApplicationVersion, model.BuildDate)
+	msg.Text = i18n.T(b.lang(update), "bot.version", model.AppName, model.ApplicationVersion, model.BuildDate)
 }
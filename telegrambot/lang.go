@@ -0,0 +1,136 @@
+package telegrambot
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/looplab/fsm"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	languagesCacheKey = "telegram-bot-languages"
+	callbackLang      = "lang"
+)
+
+// languageStore keeps track of the language chosen by each chat
+type languageStore struct {
+	mutex sync.Mutex
+	cache cache.Cache
+	data  map[int64]string
+}
+
+func newLanguageStore(cache cache.Cache) *languageStore {
+	return &languageStore{
+		cache: cache,
+		data:  make(map[int64]string),
+	}
+}
+
+func (s *languageStore) Get(chatID int64) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	lang, ok := s.data[chatID]
+	return lang, ok
+}
+
+func (s *languageStore) Set(chatID int64, lang string) {
+	s.mutex.Lock()
+	s.data[chatID] = lang
+	s.mutex.Unlock()
+	s.save()
+}
+
+func (s *languageStore) save() {
+	if s.cache == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.cache.Save(languagesCacheKey, s.data); err != nil {
+		log.Error().Err(err).Msg("Unable to save languages to cache")
+	}
+}
+
+func (s *languageStore) load() error {
+	if s.cache == nil {
+		return cache.ErrCacheNoClient
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.cache.Load(languagesCacheKey, &s.data)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNoServers):
+		return nil
+	case err != nil:
+		return err
+	}
+	if s.data == nil {
+		s.data = make(map[int64]string)
+	}
+	return nil
+}
+
+// langForChat returns the language to use for messages sent to chatID outside a conversation
+func (b *nukiBot) langForChat(chatID int64) string {
+	if lang, ok := b.languages.Get(chatID); ok {
+		return lang
+	}
+	return b.Sender.GetLanguage()
+}
+
+// lang returns the language to use to answer an update.
+// A language chosen with /lang takes precedence over the one of the user's Telegram client.
+func (b *nukiBot) lang(update telego.Update) string {
+	var chatID int64
+	var from *telego.User
+	switch {
+	case update.Message != nil:
+		chatID = update.Message.Chat.ID
+		from = update.Message.From
+	case update.CallbackQuery != nil:
+		chatID = update.CallbackQuery.Message.GetChat().ID
+		from = &update.CallbackQuery.From
+	}
+
+	if lang, ok := b.languages.Get(chatID); ok {
+		return lang
+	}
+	if from != nil && from.LanguageCode != "" {
+		return i18n.Normalize(from.LanguageCode)
+	}
+	return b.Sender.GetLanguage()
+}
+
+// fsmLang returns the language to use from the update stored in the FSM's metadata
+func (b *nukiBot) fsmLang(f *fsm.FSM) string {
+	update, err := getMetadataTelegoUpdate(FSMMetadataTelegoUpdate, f)
+	if err != nil {
+		return b.Sender.GetLanguage()
+	}
+	return b.lang(*update)
+}
+
+func (b *nukiBot) handlerLang(update telego.Update, msg *telego.SendMessageParams) {
+	var buttons []telego.InlineKeyboardButton
+	for _, lang := range i18n.Languages() {
+		buttons = append(buttons,
+			tu.InlineKeyboardButton(i18n.T(lang, "lang.name")).
+				WithCallbackData(NewCallbackData(callbackLang, lang)))
+	}
+	msg.ReplyMarkup = tu.InlineKeyboard(tu.InlineKeyboardRow(buttons...))
+	msg.Text = i18n.T(b.lang(update), "lang.ask")
+}
+
+// callbackLangChosen saves the language chosen by a chat
+func (b *nukiBot) callbackLangChosen(update telego.Update, data string) (*telego.SendMessageParams, error) {
+	lang := i18n.Normalize(data)
+	b.languages.Set(update.CallbackQuery.Message.GetChat().ID, lang)
+	return &telego.SendMessageParams{Text: i18n.T(lang, "lang.set", i18n.T(lang, "lang.name"))}, nil
+}
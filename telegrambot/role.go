@@ -0,0 +1,88 @@
+package telegrambot
+
+import (
+	"slices"
+
+	"golang.org/x/exp/maps"
+)
+
+type Role string
+
+const (
+	RoleAdmin   = Role("admin")
+	RoleHost    = Role("host")
+	RoleCleaner = Role("cleaner")
+	RoleViewer  = Role("viewer")
+	// RoleGuest is given to users linked to a reservation, it cannot be configured
+	RoleGuest = Role("guest")
+)
+
+var (
+	// rolesAll is a shortcut for commands every known user can run
+	rolesAll = []Role{RoleAdmin, RoleHost, RoleCleaner, RoleViewer}
+)
+
+// Roles maps a role to the Telegram user IDs having it
+type Roles map[Role][]int64
+
+// IsEmpty returns true if no role is configured, in that case every user is considered as admin
+func (r Roles) IsEmpty() bool {
+	for _, ids := range r {
+		if len(ids) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// UserRoles returns all the roles of a given user
+func (r Roles) UserRoles(userID int64) []Role {
+	if r.IsEmpty() {
+		return []Role{RoleAdmin}
+	}
+
+	var res []Role
+	for role, ids := range r {
+		if slices.Contains(ids, userID) {
+			res = append(res, role)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// IsAllowed returns true if the user has at least one of the given roles.
+// Admins are always allowed and an empty roles list means that any known user is allowed.
+func (r Roles) IsAllowed(userID int64, roles []Role) bool {
+	userRoles := r.UserRoles(userID)
+	if len(userRoles) == 0 {
+		return false
+	}
+	if slices.Contains(userRoles, RoleAdmin) || len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(userRoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Admins returns all the admins' user IDs
+func (r Roles) Admins() []int64 {
+	return slices.Clone(r[RoleAdmin])
+}
+
+// AllUserIDs returns all the user IDs having at least one role
+func (r Roles) AllUserIDs() []int64 {
+	var res []int64
+	for _, role := range maps.Keys(r) {
+		for _, id := range r[role] {
+			if !slices.Contains(res, id) {
+				res = append(res, id)
+			}
+		}
+	}
+	return res
+}
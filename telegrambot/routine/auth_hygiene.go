@@ -0,0 +1,144 @@
+package routine
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/hygiene"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+)
+
+const authHygieneCacheKey = "auth-hygiene-last-report"
+
+var _ AuthHygieneRoutine = (*authHygieneRoutine)(nil)
+
+// AuthHygieneRoutine periodically reviews the smartlock's authorizations
+type AuthHygieneRoutine interface {
+	Start()
+	SetConfig(config hygiene.Config)
+	Review() (hygiene.Report, error)
+	AddOnReportListener(func(r hygiene.Report))
+}
+
+type authHygieneRoutine struct {
+	mutexConfig        sync.RWMutex
+	config             hygiene.Config
+	authReader         nukiapi.SmartlockAuthReader
+	reservationsReader nukiapi.ReservationsReader
+	cache              cache.Cache
+	mutexListeners     sync.Mutex
+	onReportListeners  []func(r hygiene.Report)
+}
+
+func NewAuthHygieneRoutine(authReader nukiapi.SmartlockAuthReader, reservationsReader nukiapi.ReservationsReader, cache cache.Cache) *authHygieneRoutine {
+	return &authHygieneRoutine{
+		authReader:         authReader,
+		reservationsReader: reservationsReader,
+		cache:              cache,
+	}
+}
+
+func (r *authHygieneRoutine) AddOnReportListener(f func(r hygiene.Report)) {
+	r.mutexListeners.Lock()
+	defer r.mutexListeners.Unlock()
+	r.onReportListeners = append(r.onReportListeners, f)
+}
+
+func (r *authHygieneRoutine) SetConfig(config hygiene.Config) {
+	r.mutexConfig.Lock()
+	defer r.mutexConfig.Unlock()
+	r.config = config
+}
+
+func (r *authHygieneRoutine) getInterval() time.Duration {
+	r.mutexConfig.RLock()
+	defer r.mutexConfig.RUnlock()
+	return r.config.GetInterval()
+}
+
+// Review analyzes the current authorizations
+func (r *authHygieneRoutine) Review() (hygiene.Report, error) {
+	auths, err := r.authReader.Execute()
+	if err != nil {
+		return hygiene.Report{}, err
+	}
+	// Codes are named after their reservation's reference
+	reservations, err := r.reservationsReader.Execute()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to get reservations, relying on auths' expiry only")
+	}
+	r.mutexConfig.RLock()
+	config := r.config
+	r.mutexConfig.RUnlock()
+	return hygiene.Analyze(config, auths, reservations, time.Now()), nil
+}
+
+// Start sends a report every interval, the date of the last one being kept in cache to survive restarts
+func (r *authHygieneRoutine) Start() {
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+		timer := time.NewTimer(r.nextReport(r.getInterval()))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				r.report()
+				timer.Reset(r.getInterval())
+			case <-interrupt:
+				log.Info().Msg("Stopping auth hygiene routine")
+				return
+			}
+		}
+	}()
+}
+
+func (r *authHygieneRoutine) report() {
+	log.Info().Msg("Reviewing smartlock authorizations")
+	report, err := r.Review()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to review smartlock authorizations")
+		return
+	}
+	if r.cache != nil {
+		if err := r.cache.Save(authHygieneCacheKey, report.Date); err != nil {
+			log.Error().Err(err).Msg("Unable to save auth hygiene report date to cache")
+		}
+	}
+	if len(report.Findings) == 0 {
+		return
+	}
+
+	r.mutexListeners.Lock()
+	defer r.mutexListeners.Unlock()
+	for _, fn := range r.onReportListeners {
+		if fn != nil {
+			fn(report)
+		}
+	}
+}
+
+// nextReport returns the delay before the next report, an interval after the last one or now if none was sent
+func (r *authHygieneRoutine) nextReport(interval time.Duration) time.Duration {
+	if r.cache == nil {
+		return interval
+	}
+	var last time.Time
+	err := r.cache.Load(authHygieneCacheKey, &last)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss):
+		return 0
+	case err != nil:
+		log.Error().Err(err).Msg("Unable to load auth hygiene report date from cache")
+		return interval
+	}
+	return max(time.Until(last.Add(interval)), 0)
+}
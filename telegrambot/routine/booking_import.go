@@ -0,0 +1,217 @@
+package routine
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nmaupu/nuki-logger/booking"
+	"github.com/nmaupu/nuki-logger/model"
+	"github.com/nmaupu/nuki-logger/nukiapi"
+	"github.com/rs/zerolog/log"
+)
+
+var _ BookingImportRoutine = (*bookingImportRoutine)(nil)
+
+// BookingImportRoutine imports bookings from OTAs' iCal feeds as pending modifications
+type BookingImportRoutine interface {
+	Start()
+	ImportNow()
+	AddOnConflictListener(func(c booking.Conflict))
+	SetDefaultCheckTimes(checkIn, checkOut time.Time)
+}
+
+type bookingImportRoutine struct {
+	config               booking.Config
+	loc                  *time.Location
+	reservationReader    nukiapi.ReservationsReader
+	pendingModifications ReservationPendingModificationRoutine
+	mutexDefaults        sync.RWMutex
+	defaultCheckIn       time.Time
+	defaultCheckOut      time.Time
+	fromChatID           int64
+	mutexListeners       sync.Mutex
+	onConflictListeners  []func(c booking.Conflict)
+	reportedConflicts    map[string]bool
+	importNowChan        chan bool
+}
+
+func NewBookingImportRoutine(
+	config booking.Config,
+	loc *time.Location,
+	reader nukiapi.ReservationsReader,
+	pendingModifications ReservationPendingModificationRoutine,
+	defaultCheckIn, defaultCheckOut time.Time,
+	fromChatID int64) *bookingImportRoutine {
+	return &bookingImportRoutine{
+		config:               config,
+		loc:                  loc,
+		reservationReader:    reader,
+		pendingModifications: pendingModifications,
+		defaultCheckIn:       defaultCheckIn,
+		defaultCheckOut:      defaultCheckOut,
+		fromChatID:           fromChatID,
+		reportedConflicts:    make(map[string]bool),
+		importNowChan:        make(chan bool, 1),
+	}
+}
+
+func (r *bookingImportRoutine) AddOnConflictListener(f func(c booking.Conflict)) {
+	r.mutexListeners.Lock()
+	defer r.mutexListeners.Unlock()
+	r.onConflictListeners = append(r.onConflictListeners, f)
+}
+
+// SetDefaultCheckTimes changes the access times used when a feed does not override them
+func (r *bookingImportRoutine) SetDefaultCheckTimes(checkIn, checkOut time.Time) {
+	r.mutexDefaults.Lock()
+	defer r.mutexDefaults.Unlock()
+	r.defaultCheckIn = checkIn
+	r.defaultCheckOut = checkOut
+}
+
+// ImportNow asks for an import without waiting for it, an import already requested being enough
+func (r *bookingImportRoutine) ImportNow() {
+	select {
+	case r.importNowChan <- true:
+	default:
+	}
+}
+
+func (r *bookingImportRoutine) Start() {
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+		ticker := time.NewTicker(r.config.GetInterval())
+		defer ticker.Stop()
+
+		r.importBookings()
+		for {
+			select {
+			case <-ticker.C:
+				r.importBookings()
+			case <-r.importNowChan:
+				r.importBookings()
+			case <-interrupt:
+				log.Info().Msg("Stopping booking import routine")
+				return
+			}
+		}
+	}()
+}
+
+func (r *bookingImportRoutine) importBookings() {
+	log.Info().Msg("Importing bookings from feeds")
+
+	var bookings []booking.Booking
+	for _, feed := range r.config.Feeds {
+		res, err := booking.Fetch(feed, r.loc)
+		if err != nil {
+			log.Error().Err(err).Str("feed", feed.Name).Msg("Unable to import bookings")
+			continue
+		}
+		bookings = append(bookings, res...)
+	}
+
+	resas, err := r.reservationReader.Execute()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to get reservations from API to match bookings")
+		return
+	}
+
+	matches, conflicts := booking.MatchReservations(bookings, resas, r.loc, time.Now())
+	for _, c := range conflicts {
+		if r.reportedConflicts[c.Key()] {
+			continue
+		}
+		r.reportedConflicts[c.Key()] = true
+		log.Warn().
+			Str("kind", string(c.Kind)).
+			Str("feed", c.Booking.Feed.Name).
+			Str("ref", c.Booking.Reference).
+			Str("dates", c.Booking.FormatDates()).
+			Str("other", c.Other).
+			Msg("Booking conflict")
+		r.dispatchConflictToListeners(c)
+	}
+
+	existing := make(map[string]model.ReservationPendingModification)
+	for _, p := range r.pendingModifications.GetAllPendingModifications() {
+		existing[p.ReservationRef] = p
+	}
+	for _, m := range matches {
+		r.registerPendingModification(m, existing)
+	}
+}
+
+// registerPendingModification attaches the feed's access times and the guest name to the matched reservation.
+// Access times are only changed when they differ from the reservation's, a booking matched without overrides
+// only keeping its guest name. Modifications entered manually are never overridden.
+func (r *bookingImportRoutine) registerPendingModification(m booking.Match, existing map[string]model.ReservationPendingModification) {
+	if m.Reference == "" {
+		log.Debug().
+			Str("feed", m.Booking.Feed.Name).
+			Str("dates", m.Booking.FormatDates()).
+			Msg("Booking not synced to Nuki yet and without reference, ignoring")
+		return
+	}
+
+	r.mutexDefaults.RLock()
+	defaultCheckIn, defaultCheckOut := r.defaultCheckIn, r.defaultCheckOut
+	r.mutexDefaults.RUnlock()
+	checkIn, checkOut, hasOverrides, err := m.Booking.Feed.AccessTimes(defaultCheckIn, defaultCheckOut)
+	if err != nil {
+		log.Error().Err(err).Str("feed", m.Booking.Feed.Name).Msg("Invalid check in/out times")
+		return
+	}
+	if hasOverrides && m.Reservation != nil && r.hasAccessTimes(*m.Reservation, checkIn, checkOut) {
+		hasOverrides = false
+	}
+	// Without access times to change, only the guest name of a reservation not synced yet is worth keeping
+	if !hasOverrides && (m.Reservation != nil || m.Booking.GuestName == "") {
+		return
+	}
+
+	rpm := model.ReservationPendingModification{
+		ReservationRef: m.Reference,
+		FromChatID:     r.fromChatID,
+		GuestName:      m.Booking.GuestName,
+		Source:         m.Booking.Feed.Name,
+		NameOnly:       !hasOverrides,
+	}
+	if hasOverrides {
+		rpm.CheckInTime = checkIn
+		rpm.CheckOutTime = checkOut
+	}
+	if prev, ok := existing[m.Reference]; ok {
+		if !prev.IsImported() ||
+			(prev.FormatAccessTimes() == rpm.FormatAccessTimes() && prev.GuestName == rpm.GuestName) {
+			return
+		}
+	}
+
+	log.Info().
+		Object("pending_resa", rpm).
+		Msg("Registering pending modification from booking feed")
+	r.pendingModifications.AddPendingModification(rpm)
+}
+
+// hasAccessTimes returns true if the reservation already starts at checkIn and ends at checkOut
+func (r *bookingImportRoutine) hasAccessTimes(resa model.NukiReservationResponse, checkIn, checkOut time.Time) bool {
+	sameTime := func(date, t time.Time) bool {
+		return date.In(r.loc).Format(model.FormatTimeHoursMinutes) == t.Format(model.FormatTimeHoursMinutes)
+	}
+	return sameTime(resa.StartDate, checkIn) && sameTime(resa.EndDate, checkOut)
+}
+
+func (r *bookingImportRoutine) dispatchConflictToListeners(c booking.Conflict) {
+	r.mutexListeners.Lock()
+	defer r.mutexListeners.Unlock()
+	for _, fn := range r.onConflictListeners {
+		if fn != nil {
+			fn(c)
+		}
+	}
+}
@@ -10,9 +10,11 @@ import (
 
 	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/nmaupu/nuki-logger/eventbus"
 	"github.com/nmaupu/nuki-logger/model"
 	"github.com/nmaupu/nuki-logger/nukiapi"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/maps"
 )
 
 var _ ReservationPendingModificationRoutine = (*reservationPendingModificationRoutine)(nil)
@@ -30,31 +32,33 @@ type ReservationPendingModificationRoutine interface {
 	AddPendingModification(r model.ReservationPendingModification)
 	GetAllPendingModifications() []model.ReservationPendingModification
 	DeletePendingModification(resaRef string)
-	AddOnErrorListener(func(rpm *model.ReservationPendingModification, err error))
-	AddOnModificationDoneListener(func(rpm *model.ReservationPendingModification))
 	SaveToCache() error
 	ApplyModificationNow()
 }
 
 type reservationPendingModificationRoutine struct {
-	cache                       cache.Cache
-	pendings                    map[string]*model.ReservationPendingModification
-	messages                    chan model.ReservationPendingModification
-	mutexRPM                    sync.Mutex
-	reservationReader           nukiapi.ReservationsReader
-	reservationTimeModifier     nukiapi.ReservationTimeModifier
-	mutexListeners              sync.Mutex
-	onErrorListeners            []func(rpm *model.ReservationPendingModification, err error)
-	onModificationDoneListeners []func(rpm *model.ReservationPendingModification)
-	applyNowChan                chan bool
-}
-
+	cache                   cache.Cache
+	bus                     *eventbus.Bus
+	pendings                map[string]*model.ReservationPendingModification
+	messages                chan model.ReservationPendingModification
+	mutexRPM                sync.Mutex
+	reservationReader       nukiapi.ReservationsReader
+	reservationTimeModifier nukiapi.ReservationTimeModifier
+	applyNowChan            chan bool
+	// reservationsVersions are the update dates of the reservations seen during the last check, nil before the first one
+	reservationsVersions map[string]time.Time
+}
+
+// NewReservationPendingModificationRoutine creates the routine, publishing modifications' outcome
+// and reservations' changes on bus
 func NewReservationPendingModificationRoutine(
 	reader nukiapi.ReservationsReader,
 	writer nukiapi.ReservationTimeModifier,
-	cache cache.Cache) *reservationPendingModificationRoutine {
+	cache cache.Cache,
+	bus *eventbus.Bus) *reservationPendingModificationRoutine {
 	return &reservationPendingModificationRoutine{
 		cache:                   cache,
+		bus:                     bus,
 		pendings:                make(map[string]*model.ReservationPendingModification),
 		messages:                make(chan model.ReservationPendingModification),
 		mutexRPM:                sync.Mutex{},
@@ -88,18 +92,6 @@ func (r *reservationPendingModificationRoutine) GetAllPendingModifications() []m
 	return res
 }
 
-func (r *reservationPendingModificationRoutine) AddOnErrorListener(f func(rpm *model.ReservationPendingModification, err error)) {
-	r.mutexListeners.Lock()
-	defer r.mutexListeners.Unlock()
-	r.onErrorListeners = append(r.onErrorListeners, f)
-}
-
-func (r *reservationPendingModificationRoutine) AddOnModificationDoneListener(f func(rpm *model.ReservationPendingModification)) {
-	r.mutexListeners.Lock()
-	defer r.mutexListeners.Unlock()
-	r.onModificationDoneListeners = append(r.onModificationDoneListeners, f)
-}
-
 func (r *reservationPendingModificationRoutine) ApplyModificationNow() {
 	r.applyNowChan <- true
 }
@@ -144,7 +136,7 @@ func (r *reservationPendingModificationRoutine) Start(checkInterval time.Duratio
 				r.pendings[msg.ReservationRef] = &msg
 				if err := r.SaveToCache(); err != nil {
 					log.Error().Err(err).Msg("Unable to save pending modification cache to disk")
-					r.dispatchErrorsToListeners(&msg, err)
+					r.publishError(&msg, err)
 				}
 				r.mutexRPM.Unlock()
 			case <-interrupt:
@@ -161,13 +153,23 @@ func (r *reservationPendingModificationRoutine) apply() {
 	// Get all reservations from API
 	allResas, err := r.reservationReader.Execute()
 	if err != nil {
-		r.dispatchErrorsToListeners(nil, ErrCannotGetReservationsFromAPI{err})
+		r.publishError(nil, ErrCannotGetReservationsFromAPI{err})
+	} else {
+		r.checkReservationsChanges(allResas)
 	}
 
 	// Getting all pending modifications and do the change
 	r.mutexRPM.Lock()
 	for _, resa := range allResas {
 		pendingResa, ok := r.pendings[resa.Reference]
+		if ok && !pendingResa.ModificationDone && pendingResa.NameOnly {
+			// Only the guest name was kept, the reservation's access times are left untouched
+			pendingResa.ModificationDone = true
+			pendingResa.LastUpdateTime = time.Now()
+			linkedResa := resa
+			pendingResa.LinkedReservation = &linkedResa
+			continue
+		}
 		if ok && !pendingResa.ModificationDone { // found one to process
 			log.Info().
 				Object("pending_resa", pendingResa).
@@ -178,37 +180,41 @@ func (r *reservationPendingModificationRoutine) apply() {
 				model.MinutesFromMidnight(pendingResa.CheckOutTime),
 			)
 			if err != nil {
-				r.dispatchErrorsToListeners(pendingResa, err)
+				r.publishError(pendingResa, err)
 				continue
 			}
 			pendingResa.ModificationDone = true
 			pendingResa.LastUpdateTime = time.Now()
 			linkedResa := resa
 			pendingResa.LinkedReservation = &linkedResa
-			r.dispatchModificationDoneToListeners(pendingResa)
+			rpm := *pendingResa
+			r.bus.Publish(eventbus.Event{Kind: eventbus.KindModificationDone, Modification: &rpm})
 		}
 	}
 	r.mutexRPM.Unlock()
 }
 
-func (r *reservationPendingModificationRoutine) dispatchErrorsToListeners(rpm *model.ReservationPendingModification, e error) {
-	r.mutexListeners.Lock()
-	defer r.mutexListeners.Unlock()
-	for _, fn := range r.onErrorListeners {
-		if fn != nil {
-			fn(rpm, e)
-		}
+func (r *reservationPendingModificationRoutine) publishError(rpm *model.ReservationPendingModification, e error) {
+	var modification *model.ReservationPendingModification
+	if rpm != nil {
+		m := *rpm
+		modification = &m
 	}
+	r.bus.Publish(eventbus.Event{Kind: eventbus.KindModificationFailed, Modification: modification, Err: e})
 }
 
-func (r *reservationPendingModificationRoutine) dispatchModificationDoneToListeners(rpm *model.ReservationPendingModification) {
-	r.mutexListeners.Lock()
-	defer r.mutexListeners.Unlock()
-	for _, fn := range r.onModificationDoneListeners {
-		if fn != nil {
-			fn(rpm)
-		}
+// checkReservationsChanges publishes reservations when one has been added, removed or updated since the last check
+func (r *reservationPendingModificationRoutine) checkReservationsChanges(resas []model.NukiReservationResponse) {
+	versions := make(map[string]time.Time, len(resas))
+	for _, resa := range resas {
+		versions[resa.ID] = resa.UpdateDate
+	}
+	previous := r.reservationsVersions
+	r.reservationsVersions = versions
+	if previous == nil || maps.EqualFunc(previous, versions, time.Time.Equal) {
+		return
 	}
+	r.bus.Publish(eventbus.Event{Kind: eventbus.KindReservation, Reservations: resas})
 }
 
 func (r *reservationPendingModificationRoutine) SaveToCache() error {
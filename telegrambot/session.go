@@ -0,0 +1,290 @@
+package telegrambot
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/looplab/fsm"
+	"github.com/nmaupu/nuki-logger/cache"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	sessionsCacheKey          = "telegram-bot-sessions"
+	DefaultSessionIdleTimeout = time.Minute * 15
+	sessionJanitorInterval    = time.Minute
+)
+
+// session is a conversation in progress between a chat and a command
+type session struct {
+	mutex        sync.Mutex
+	ChatID       int64
+	CommandName  string
+	StateMachine *fsm.FSM
+	// NextFSMEvent is written holding both the session's and the manager's locks, either one being enough to read it
+	NextFSMEvent string
+	LastActivity time.Time
+	command      Command
+}
+
+// sessionSnapshot is the serializable form of a session
+type sessionSnapshot struct {
+	ChatID       int64                      `json:"chat_id"`
+	CommandName  string                     `json:"command_name"`
+	State        string                     `json:"state"`
+	NextFSMEvent string                     `json:"next_fsm_event"`
+	LastActivity time.Time                  `json:"last_activity"`
+	Metadata     map[string]json.RawMessage `json:"metadata"`
+}
+
+func (s *session) GetNextFSMEvent() string {
+	if s.NextFSMEvent == "" {
+		return FSMEventDefault
+	}
+	return s.NextFSMEvent
+}
+
+// WaitsForInput returns true if the conversation expects the next message of its chat
+func (s *session) WaitsForInput() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.NextFSMEvent != ""
+}
+
+// IsInProgress returns true if the conversation is not finished yet
+func (s *session) IsInProgress() bool {
+	return s.StateMachine != nil && s.StateMachine.Current() != "idle"
+}
+
+func (s *session) snapshot() sessionSnapshot {
+	snap := sessionSnapshot{
+		ChatID:       s.ChatID,
+		CommandName:  s.CommandName,
+		State:        s.StateMachine.Current(),
+		NextFSMEvent: s.NextFSMEvent,
+		LastActivity: s.LastActivity,
+		Metadata:     map[string]json.RawMessage{},
+	}
+	for key := range s.command.PersistedMetadata {
+		v, ok := s.StateMachine.Metadata(key)
+		if !ok {
+			continue
+		}
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Unable to marshal session metadata")
+			continue
+		}
+		snap.Metadata[key] = bytes
+	}
+	return snap
+}
+
+// SessionManager keeps one FSM instance per chat and command
+type SessionManager struct {
+	mutex       sync.Mutex
+	sessions    map[int64]*session
+	idleTimeout time.Duration
+	cache       cache.Cache
+	onExpire    func(chatID int64)
+}
+
+func NewSessionManager(idleTimeout time.Duration, cache cache.Cache) *SessionManager {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultSessionIdleTimeout
+	}
+	return &SessionManager{
+		sessions:    make(map[int64]*session),
+		idleTimeout: idleTimeout,
+		cache:       cache,
+	}
+}
+
+// OnExpire sets a function to be called when an in progress session expires
+func (m *SessionManager) OnExpire(f func(chatID int64)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onExpire = f
+}
+
+// Start creates a new session for chatID, replacing any existing one
+func (m *SessionManager) Start(chatID int64, commandName string, command Command) *session {
+	s := &session{
+		ChatID:       chatID,
+		CommandName:  commandName,
+		StateMachine: command.NewStateMachine(),
+		LastActivity: time.Now(),
+		command:      command,
+	}
+
+	m.mutex.Lock()
+	m.sessions[chatID] = s
+	m.mutex.Unlock()
+	return s
+}
+
+// Get returns the current session of a chat or nil if there is none, expiring it when idle for too long
+func (m *SessionManager) Get(chatID int64) *session {
+	m.mutex.Lock()
+	s, ok := m.sessions[chatID]
+	if !ok {
+		m.mutex.Unlock()
+		return nil
+	}
+	if time.Since(s.LastActivity) > m.idleTimeout {
+		delete(m.sessions, chatID)
+		onExpire := m.onExpire
+		m.mutex.Unlock()
+		m.expired([]*session{s}, onExpire)
+		return nil
+	}
+	s.LastActivity = time.Now()
+	m.mutex.Unlock()
+	return s
+}
+
+// Delete removes the session of a chat
+func (m *SessionManager) Delete(chatID int64) {
+	log.Trace().
+		Int64("chatID", chatID).
+		Msg("Resetting chat session")
+	m.mutex.Lock()
+	_, ok := m.sessions[chatID]
+	delete(m.sessions, chatID)
+	m.mutex.Unlock()
+
+	if ok {
+		m.save()
+	}
+}
+
+// Touch sets the next event expected by a session, updates its last activity and persists sessions to the cache.
+// The session's lock must be held.
+func (m *SessionManager) Touch(s *session, nextFSMEvent string) {
+	m.mutex.Lock()
+	s.NextFSMEvent = nextFSMEvent
+	s.LastActivity = time.Now()
+	m.mutex.Unlock()
+	m.save()
+}
+
+// StartJanitor regularly removes idle sessions
+func (m *SessionManager) StartJanitor() {
+	go func() {
+		ticker := time.NewTicker(sessionJanitorInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.expire()
+		}
+	}()
+}
+
+func (m *SessionManager) expire() {
+	var expired []*session
+	m.mutex.Lock()
+	for chatID, s := range m.sessions {
+		if time.Since(s.LastActivity) > m.idleTimeout {
+			expired = append(expired, s)
+			delete(m.sessions, chatID)
+		}
+	}
+	onExpire := m.onExpire
+	m.mutex.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+	m.expired(expired, onExpire)
+}
+
+// expired notifies sessions removed for being idle and persists the remaining ones, the lock not being held
+func (m *SessionManager) expired(expired []*session, onExpire func(chatID int64)) {
+	for _, s := range expired {
+		log.Debug().
+			Int64("chat_id", s.ChatID).
+			Str("command", s.CommandName).
+			Msg("Session expired")
+		if onExpire != nil && s.IsInProgress() {
+			onExpire(s.ChatID)
+		}
+	}
+	m.save()
+}
+
+func (m *SessionManager) save() {
+	if m.cache == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	snapshots := []sessionSnapshot{}
+	for _, s := range m.sessions {
+		if s.IsInProgress() {
+			snapshots = append(snapshots, s.snapshot())
+		}
+	}
+	m.mutex.Unlock()
+
+	if err := m.cache.Save(sessionsCacheKey, snapshots); err != nil {
+		log.Error().Err(err).Msg("Unable to save bot sessions to cache")
+	}
+}
+
+// Load restores in progress sessions from the cache
+func (m *SessionManager) Load(commands Commands) error {
+	if m.cache == nil {
+		return cache.ErrCacheNoClient
+	}
+
+	snapshots := []sessionSnapshot{}
+	err := m.cache.Load(sessionsCacheKey, &snapshots)
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNoServers):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, snap := range snapshots {
+		command, ok := commands[snap.CommandName]
+		if !ok || command.NewStateMachine == nil || time.Since(snap.LastActivity) > m.idleTimeout {
+			continue
+		}
+
+		s := &session{
+			ChatID:       snap.ChatID,
+			CommandName:  snap.CommandName,
+			StateMachine: command.NewStateMachine(),
+			NextFSMEvent: snap.NextFSMEvent,
+			LastActivity: snap.LastActivity,
+			command:      command,
+		}
+		s.StateMachine.SetState(snap.State)
+		s.StateMachine.SetMetadata(FSMMetadataNextEvent, snap.NextFSMEvent)
+		for key, raw := range snap.Metadata {
+			newValue, ok := command.PersistedMetadata[key]
+			if !ok {
+				continue
+			}
+			v := newValue()
+			if err := json.Unmarshal(raw, v); err != nil {
+				log.Error().Err(err).Str("key", key).Msg("Unable to unmarshal session metadata")
+				continue
+			}
+			s.StateMachine.SetMetadata(key, v)
+		}
+
+		log.Debug().
+			Int64("chat_id", s.ChatID).
+			Str("command", s.CommandName).
+			Str("state", snap.State).
+			Msg("Session restored from cache")
+		m.sessions[s.ChatID] = s
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultWebhookPath = "/telegram/webhook"
+)
+
+// secretTokenRegexp are the secret tokens accepted by Telegram
+var secretTokenRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// WebhookConfig configures updates delivery using a webhook instead of long polling
+type WebhookConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// URL is the public HTTPS URL Telegram sends updates to (e.g. https://bot.example.com/telegram/webhook)
+	URL string `mapstructure:"url"`
+	// Path is the path of the handler registered on the local HTTP server
+	Path string `mapstructure:"path"`
+	// SecretToken is sent by Telegram in every request and verified by the handler.
+	// It is mandatory, anyone reaching the URL being able to forge updates otherwise.
+	SecretToken string `mapstructure:"secret_token"`
+	// Certificate is a self-signed public certificate to upload to Telegram, not needed behind a reverse proxy
+	Certificate string `mapstructure:"certificate"`
+}
+
+func (w WebhookConfig) GetPath() string {
+	if w.Path == "" {
+		return DefaultWebhookPath
+	}
+	return w.Path
+}
+
+// ValidateSecretToken checks that updates are authenticated with a secret token accepted by Telegram
+func (w WebhookConfig) ValidateSecretToken() error {
+	if w.SecretToken == "" {
+		return errors.New("secret_token is mandatory")
+	}
+	if !secretTokenRegexp.MatchString(w.SecretToken) {
+		return errors.New("secret_token must be 1-256 characters among A-Z, a-z, 0-9, _ and -")
+	}
+	return nil
+}
+
+// UseWebhook configures the bot to receive updates from the given mux instead of using long polling
+func (b *nukiBot) UseWebhook(cfg WebhookConfig, mux *http.ServeMux) {
+	b.webhook = &cfg
+	b.webhookMux = mux
+}
+
+func (b *nukiBot) updatesViaWebhook() (<-chan telego.Update, error) {
+	if b.webhook.URL == "" {
+		return nil, fmt.Errorf("webhook url is mandatory")
+	}
+	if b.webhookMux == nil {
+		return nil, fmt.Errorf("no http server available for webhook")
+	}
+	if err := b.webhook.ValidateSecretToken(); err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+
+	setWebhookParams := &telego.SetWebhookParams{
+		URL:         b.webhook.URL,
+		SecretToken: b.webhook.SecretToken,
+	}
+	if b.webhook.Certificate != "" {
+		f, err := os.Open(b.webhook.Certificate)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		cert := tu.File(f)
+		setWebhookParams.Certificate = &cert
+	}
+
+	log.Info().
+		Str("url", b.webhook.URL).
+		Str("path", b.webhook.GetPath()).
+		Msg("Registering telegram webhook")
+
+	// Handlers are registered on the already existing http server which is started separately
+	server := telego.HTTPWebhookServer{
+		Logger:      b.bot.Logger(),
+		Server:      &http.Server{Handler: b.webhookMux},
+		ServeMux:    b.webhookMux,
+		SecretToken: b.webhook.SecretToken,
+	}
+	updates, err := b.bot.UpdatesViaWebhook(b.webhook.GetPath(),
+		telego.WithWebhookServer(telego.FuncWebhookServer{
+			Server:    server,
+			StartFunc: func(_ string) error { return nil },
+			StopFunc:  func(_ context.Context) error { return nil },
+		}),
+		telego.WithWebhookSet(setWebhookParams),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only flag the webhook as running, the http server is started elsewhere
+	return updates, b.bot.StartWebhook("")
+}
+
+func (b *nukiBot) stopWebhook() error {
+	log.Info().Msg("Unregistering telegram webhook")
+	if err := b.bot.StopWebhook(); err != nil {
+		log.Error().Err(err).Msg("Unable to stop telegram webhook")
+	}
+	return b.bot.DeleteWebhook(&telego.DeleteWebhookParams{})
+}
@@ -0,0 +1,182 @@
+package telegrambot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+const (
+	testBotToken    = "123456789:abcdefghijklmnopqrstuvwxyz012345678"
+	testSecretToken = "webhook-secret_42"
+)
+
+// fakeTelegramAPI records the methods called on the Telegram API with their parameters
+type fakeTelegramAPI struct {
+	mutex sync.Mutex
+	calls []fakeTelegramCall
+}
+
+type fakeTelegramCall struct {
+	method string
+	params map[string]any
+}
+
+func (f *fakeTelegramAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	params := make(map[string]any)
+	if r.ContentLength > 0 {
+		_ = json.NewDecoder(r.Body).Decode(&params)
+	}
+	f.mutex.Lock()
+	f.calls = append(f.calls, fakeTelegramCall{method: method, params: params})
+	f.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+}
+
+func (f *fakeTelegramAPI) methods() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var res []string
+	for _, c := range f.calls {
+		res = append(res, c.method)
+	}
+	return res
+}
+
+func (f *fakeTelegramAPI) call(method string) (fakeTelegramCall, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, c := range f.calls {
+		if c.method == method {
+			return c, true
+		}
+	}
+	return fakeTelegramCall{}, false
+}
+
+// newWebhookBot returns a bot using a fake Telegram API and receiving its updates on the returned server
+func newWebhookBot(t *testing.T, api *fakeTelegramAPI) (*nukiBot, *httptest.Server) {
+	apiServer := httptest.NewServer(api)
+	t.Cleanup(apiServer.Close)
+	bot, err := telego.NewBot(testBotToken, telego.WithAPIServer(apiServer.URL), telego.WithDiscardLogger())
+	if err != nil {
+		t.Fatalf("NewBot() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	b := &nukiBot{bot: bot}
+	b.UseWebhook(WebhookConfig{
+		Enabled:     true,
+		URL:         "https://bot.example.com" + DefaultWebhookPath,
+		SecretToken: testSecretToken,
+	}, mux)
+	return b, server
+}
+
+func TestWebhookRegistration(t *testing.T) {
+	api := &fakeTelegramAPI{}
+	b, _ := newWebhookBot(t, api)
+
+	if _, err := b.updatesViaWebhook(); err != nil {
+		t.Fatalf("updatesViaWebhook() error = %v", err)
+	}
+	set, ok := api.call("setWebhook")
+	if !ok {
+		t.Fatalf("setWebhook not called, calls: %v", api.methods())
+	}
+	if got := set.params["url"]; got != b.webhook.URL {
+		t.Errorf("setWebhook url = %v, want %s", got, b.webhook.URL)
+	}
+	if got := set.params["secret_token"]; got != testSecretToken {
+		t.Errorf("setWebhook secret_token = %v, want %s", got, testSecretToken)
+	}
+
+	if err := b.stopWebhook(); err != nil {
+		t.Fatalf("stopWebhook() error = %v", err)
+	}
+	if _, ok := api.call("deleteWebhook"); !ok {
+		t.Errorf("deleteWebhook not called, calls: %v", api.methods())
+	}
+}
+
+func TestWebhookSecretToken(t *testing.T) {
+	api := &fakeTelegramAPI{}
+	b, server := newWebhookBot(t, api)
+	updates, err := b.updatesViaWebhook()
+	if err != nil {
+		t.Fatalf("updatesViaWebhook() error = %v", err)
+	}
+	t.Cleanup(func() { _ = b.stopWebhook() })
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "missing secret token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret token", token: "forged", wantStatus: http.StatusUnauthorized},
+		{name: "valid secret token", token: testSecretToken, wantStatus: http.StatusOK},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"update_id":%d,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"/status"}}`, i+1)
+			req, err := http.NewRequest(http.MethodPost, server.URL+DefaultWebhookPath, strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set(telego.WebhookSecretTokenHeader, tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			select {
+			case u := <-updates:
+				if tt.wantStatus != http.StatusOK {
+					t.Errorf("forged update %d received", u.UpdateID)
+				}
+			case <-time.After(time.Millisecond * 100):
+				if tt.wantStatus == http.StatusOK {
+					t.Error("update not received")
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookConfigValidateSecretToken(t *testing.T) {
+	tests := []struct {
+		token   string
+		wantErr bool
+	}{
+		{token: "", wantErr: true},
+		{token: "has spaces", wantErr: true},
+		{token: strings.Repeat("a", 257), wantErr: true},
+		{token: testSecretToken},
+	}
+	for _, tt := range tests {
+		err := WebhookConfig{SecretToken: tt.token}.ValidateSecretToken()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSecretToken(%q) error = %v, wantErr %v", tt.token, err, tt.wantErr)
+		}
+	}
+}